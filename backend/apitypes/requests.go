@@ -0,0 +1,204 @@
+package apitypes
+
+import "time"
+
+// Request bodies. Unknown fields are rejected by the server, so these are exactly what it accepts. Pointer fields
+// are optional; on PATCH bodies a nil field is left unchanged.
+
+type CreateNamespaceRequest struct {
+	Name string `json:"name"`
+}
+
+type RenameNamespaceRequest struct {
+	Name            string `json:"name"`
+	AliasTTLSeconds int    `json:"alias_ttl_seconds,omitempty"`
+}
+
+// UpdateNamespacePolicyRequest changes the given fields of a namespace policy; TicketPattern "" clears the pattern.
+type UpdateNamespacePolicyRequest struct {
+	RequiredApprovals *int    `json:"required_approvals,omitempty"`
+	RequireComment    *bool   `json:"require_comment,omitempty"`
+	MinCommentLength  *int    `json:"min_comment_length,omitempty"`
+	TicketPattern     *string `json:"ticket_pattern,omitempty"`
+}
+
+type CreateConfigRequest struct {
+	Format    ConfigFormat `json:"format"`
+	BodyRaw   string       `json:"body_raw"`
+	Comment   *string      `json:"comment,omitempty"`
+	CreatedBy *string      `json:"created_by,omitempty"`
+}
+
+// UpdateConfigRequest creates a new version. With Merge, an update based on an older BaseVersion is three-way
+// merged onto the latest version instead of failing.
+type UpdateConfigRequest struct {
+	BodyRaw     string  `json:"body_raw"`
+	Comment     *string `json:"comment,omitempty"`
+	CreatedBy   *string `json:"created_by,omitempty"`
+	BaseVersion *int    `json:"base_version,omitempty"`
+	Merge       bool    `json:"merge,omitempty"`
+}
+
+type SetTagRequest struct {
+	Version int     `json:"version"`
+	MovedBy *string `json:"moved_by,omitempty"`
+	Comment *string `json:"comment,omitempty"`
+}
+
+// DeleteTagRequest is the optional body of DELETE .../tags/{tag}; moved_by and comment may also be query parameters.
+type DeleteTagRequest struct {
+	MovedBy *string `json:"moved_by,omitempty"`
+	Comment *string `json:"comment,omitempty"`
+}
+
+type CreateDraftRequest struct {
+	BodyRaw     string  `json:"body_raw"`
+	Comment     *string `json:"comment,omitempty"`
+	CreatedBy   *string `json:"created_by,omitempty"`
+	BaseVersion *int    `json:"base_version,omitempty"`
+}
+
+type RebaseDraftRequest struct {
+	BodyRaw     string  `json:"body_raw,omitempty"`
+	Comment     *string `json:"comment,omitempty"`
+	BaseVersion *int    `json:"base_version"`
+	RevisedBy   *string `json:"revised_by,omitempty"`
+}
+
+type DraftReviewRequest struct {
+	Reviewer string  `json:"reviewer"`
+	Decision string  `json:"decision"` // approve | reject | comment
+	Comment  *string `json:"comment,omitempty"`
+}
+
+type PublishDraftRequest struct {
+	PublishedBy *string `json:"published_by,omitempty"`
+}
+
+type CreateScheduleRequest struct {
+	BodyRaw         string  `json:"body_raw"`
+	PublishAt       string  `json:"publish_at"` // RFC 3339
+	Comment         *string `json:"comment,omitempty"`
+	CreatedBy       *string `json:"created_by,omitempty"`
+	BaseVersion     *int    `json:"base_version,omitempty"`
+	MaxDelaySeconds *int    `json:"max_delay_seconds,omitempty"`
+}
+
+type RescheduleRequest struct {
+	PublishAt string `json:"publish_at"` // RFC 3339
+}
+
+// CreateLockRequest locks a single config (Path), a folder (Prefix) or, with neither, the whole namespace.
+type CreateLockRequest struct {
+	Path      string     `json:"path,omitempty"`
+	Prefix    string     `json:"prefix,omitempty"`
+	Reason    string     `json:"reason"`
+	Owner     string     `json:"owner"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type CreateReleaseRequest struct {
+	Name      string  `json:"name"`
+	Prefix    string  `json:"prefix,omitempty"`
+	CreatedBy *string `json:"created_by,omitempty"`
+	Comment   *string `json:"comment,omitempty"`
+}
+
+type RollbackRequest struct {
+	CreatedBy *string `json:"created_by,omitempty"`
+	Comment   *string `json:"comment,omitempty"`
+	DryRun    bool    `json:"dry_run,omitempty"`
+}
+
+// MoveRequest moves a single config (Path to ToPath) or a folder (Prefix to ToPrefix).
+type MoveRequest struct {
+	Namespace       string  `json:"namespace"`
+	Path            string  `json:"path,omitempty"`
+	Prefix          string  `json:"prefix,omitempty"`
+	ToNamespace     string  `json:"to_namespace,omitempty"`
+	ToPath          string  `json:"to_path,omitempty"`
+	ToPrefix        *string `json:"to_prefix,omitempty"`
+	AliasTTLSeconds int     `json:"alias_ttl_seconds,omitempty"`
+	MovedBy         *string `json:"moved_by,omitempty"`
+	Comment         *string `json:"comment,omitempty"`
+}
+
+// CloneRequest copies a single config (Path to ToPath) or a folder (Prefix to ToPrefix).
+type CloneRequest struct {
+	Namespace   string  `json:"namespace"`
+	Path        string  `json:"path,omitempty"`
+	Prefix      string  `json:"prefix,omitempty"`
+	ToNamespace string  `json:"to_namespace,omitempty"`
+	ToPath      string  `json:"to_path,omitempty"`
+	ToPrefix    *string `json:"to_prefix,omitempty"`
+	History     string  `json:"history,omitempty"`     // latest | full
+	OnConflict  string  `json:"on_conflict,omitempty"` // fail | skip | overwrite
+	DryRun      bool    `json:"dry_run,omitempty"`
+	CreatedBy   *string `json:"created_by,omitempty"`
+	Comment     *string `json:"comment,omitempty"`
+}
+
+// ChangesetItemRequest is one create, update or delete of a changeset. Format is only used by creates, BodyRaw
+// by creates and updates.
+type ChangesetItemRequest struct {
+	Action      string       `json:"action"` // create | update | delete
+	Namespace   string       `json:"namespace"`
+	Path        string       `json:"path"`
+	Format      ConfigFormat `json:"format,omitempty"`
+	BodyRaw     string       `json:"body_raw,omitempty"`
+	BaseVersion *int         `json:"base_version,omitempty"`
+}
+
+type CreateChangesetRequest struct {
+	CreatedBy *string                `json:"created_by,omitempty"`
+	Comment   *string                `json:"comment,omitempty"`
+	Items     []ChangesetItemRequest `json:"items"`
+}
+
+type RevertChangesetRequest struct {
+	CreatedBy *string `json:"created_by,omitempty"`
+	Comment   *string `json:"comment,omitempty"`
+}
+
+// CreateWebhookRequest subscribes URL to events; Namespace "" means every namespace, EventTypes empty every type.
+// Secret is generated when empty.
+type CreateWebhookRequest struct {
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	Namespace  string   `json:"namespace,omitempty"`
+	Prefix     string   `json:"prefix,omitempty"`
+	EventTypes []string `json:"event_types,omitempty"`
+	Active     *bool    `json:"active,omitempty"`
+}
+
+// UpdateWebhookRequest changes the given fields of a webhook. RotateSecret generates a new secret.
+type UpdateWebhookRequest struct {
+	Name         *string   `json:"name,omitempty"`
+	URL          *string   `json:"url,omitempty"`
+	Secret       *string   `json:"secret,omitempty"`
+	RotateSecret bool      `json:"rotate_secret,omitempty"`
+	Namespace    *string   `json:"namespace,omitempty"`
+	Prefix       *string   `json:"prefix,omitempty"`
+	EventTypes   *[]string `json:"event_types,omitempty"`
+	Active       *bool     `json:"active,omitempty"`
+}
+
+type CreateAdmissionWebhookRequest struct {
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	Prefix        string `json:"prefix,omitempty"`
+	TimeoutMS     *int   `json:"timeout_ms,omitempty"`
+	FailurePolicy string `json:"failure_policy,omitempty"` // fail_closed (default) | fail_open
+	Active        *bool  `json:"active,omitempty"`
+}
+
+// UpdateAdmissionWebhookRequest changes the given fields of an admission webhook.
+type UpdateAdmissionWebhookRequest struct {
+	Name          *string `json:"name,omitempty"`
+	URL           *string `json:"url,omitempty"`
+	Prefix        *string `json:"prefix,omitempty"`
+	TimeoutMS     *int    `json:"timeout_ms,omitempty"`
+	FailurePolicy *string `json:"failure_policy,omitempty"`
+	Active        *bool   `json:"active,omitempty"`
+}
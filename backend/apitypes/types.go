@@ -0,0 +1,622 @@
+// Package apitypes holds the request and response bodies of the config-manager HTTP API. The server
+// (internal/httpapi) and the Go client (package client) share them, so both always agree on the wire format.
+package apitypes
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ConfigFormat string
+
+const (
+	FormatJSON ConfigFormat = "json"
+	FormatYAML ConfigFormat = "yaml"
+)
+
+// Error is the body of every non-2xx response.
+type Error struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+type Config struct {
+	ID              string       `json:"id"`
+	Namespace       string       `json:"namespace"`
+	Path            string       `json:"path"`
+	Format          ConfigFormat `json:"format"`
+	LatestVersionID *string      `json:"latest_version_id,omitempty"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+type ConfigVersion struct {
+	ID            string    `json:"id"`
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	CreatedBy     *string   `json:"created_by,omitempty"`
+	Comment       *string   `json:"comment,omitempty"`
+	ContentSHA256 *string   `json:"content_sha256,omitempty"`
+	ChangesetID   *string   `json:"changeset_id,omitempty"`
+	TicketRefs    []string  `json:"ticket_refs,omitempty"`
+	BodyRaw       string    `json:"body_raw"`
+	BodyJSON      any       `json:"body_json,omitempty"`
+}
+
+type ConfigVersionMeta struct {
+	ID            string    `json:"id"`
+	Version       int       `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	CreatedBy     *string   `json:"created_by,omitempty"`
+	Comment       *string   `json:"comment,omitempty"`
+	ContentSHA256 *string   `json:"content_sha256,omitempty"`
+	ChangesetID   *string   `json:"changeset_id,omitempty"`
+	TicketRefs    []string  `json:"ticket_refs,omitempty"`
+}
+
+type GetConfigResponse struct {
+	Config Config        `json:"config"`
+	Latest ConfigVersion `json:"latest"`
+	Merge  *MergeResult  `json:"merge,omitempty"`
+	AsOf   *time.Time    `json:"as_of,omitempty"`
+	// Release is set when the version was resolved through ?release=.
+	Release *string `json:"release,omitempty"`
+	// Locks lists live locks covering the config (current reads only).
+	Locks []Lock `json:"locks,omitempty"`
+	// Warnings are returned by admission webhooks that allowed the write.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// MergeResult is reported when an update was three-way merged onto a newer latest.
+type MergeResult struct {
+	BaseVersion    int `json:"base_version"`
+	CurrentVersion int `json:"current_version"`
+}
+
+type GetVersionResponse struct {
+	Config  Config        `json:"config"`
+	Version ConfigVersion `json:"version"`
+}
+
+type VersionListResponse struct {
+	Items      []ConfigVersionMeta `json:"items"`
+	NextCursor *string             `json:"next_cursor,omitempty"`
+}
+
+// TicketVersion is a config version whose comment referenced a ticket.
+type TicketVersion struct {
+	Namespace string `json:"namespace"`
+	Path      string `json:"path"`
+	ConfigID  string `json:"config_id"`
+	ConfigVersionMeta
+}
+
+type TicketVersionListResponse struct {
+	Ticket     string          `json:"ticket"`
+	Items      []TicketVersion `json:"items"`
+	NextCursor *string         `json:"next_cursor,omitempty"`
+}
+
+type ConfigListItem struct {
+	Config     Config            `json:"config"`
+	LatestMeta ConfigVersionMeta `json:"latest_meta"`
+}
+
+type ConfigListResponse struct {
+	Items      []ConfigListItem `json:"items"`
+	NextCursor *string          `json:"next_cursor,omitempty"`
+	AsOf       *time.Time       `json:"as_of,omitempty"`
+}
+
+type Namespace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NamespaceWithCount struct {
+	Namespace
+	ConfigCount int `json:"config_count"`
+}
+
+type NamespaceListResponse struct {
+	Items      []NamespaceWithCount `json:"items"`
+	NextCursor *string              `json:"next_cursor,omitempty"`
+}
+
+type BrowseEntryFolder struct {
+	Type     string `json:"type"` // folder
+	Name     string `json:"name"`
+	FullPath string `json:"full_path"` // ends with /
+}
+
+type BrowseEntryConfig struct {
+	Type          string       `json:"type"` // config
+	Name          string       `json:"name"`
+	FullPath      string       `json:"full_path"` // no trailing /
+	Format        ConfigFormat `json:"format"`
+	LatestVersion int          `json:"latest_version"`
+}
+
+type BrowseResponse struct {
+	Items      []any      `json:"items"`
+	NextCursor *string    `json:"next_cursor,omitempty"`
+	AsOf       *time.Time `json:"as_of,omitempty"`
+	// Locks lists live locks on the namespace, an enclosing folder, or anything under the browsed prefix.
+	Locks []Lock `json:"locks,omitempty"`
+}
+
+type ConfigTag struct {
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	VersionID string    `json:"version_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TagListResponse struct {
+	Items []ConfigTag `json:"items"`
+}
+
+type TagHistoryEntry struct {
+	ID          string    `json:"id"`
+	Tag         string    `json:"tag"`
+	FromVersion *int      `json:"from_version,omitempty"`
+	ToVersion   *int      `json:"to_version,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	MovedBy     *string   `json:"moved_by,omitempty"`
+	Comment     *string   `json:"comment,omitempty"`
+}
+
+type TagHistoryResponse struct {
+	Items      []TagHistoryEntry `json:"items"`
+	NextCursor *string           `json:"next_cursor,omitempty"`
+}
+
+type NamespacePolicy struct {
+	Namespace         string  `json:"namespace"`
+	RequiredApprovals int     `json:"required_approvals"`
+	RequireComment    bool    `json:"require_comment"`
+	MinCommentLength  int     `json:"min_comment_length"`
+	TicketPattern     *string `json:"ticket_pattern,omitempty"`
+}
+
+type ConfigDraftMeta struct {
+	ID                string     `json:"id"`
+	Status            string     `json:"status"` // open | published | rejected
+	BaseVersion       int        `json:"base_version"`
+	Revision          int        `json:"revision"`
+	Stale             bool       `json:"stale"` // latest moved past base_version; rebase before approving/publishing
+	Approvals         int        `json:"approvals"`
+	RequiredApprovals int        `json:"required_approvals"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	CreatedBy         *string    `json:"created_by,omitempty"`
+	RevisedBy         []string   `json:"revised_by,omitempty"` // changed the body or comment after creation
+	Comment           *string    `json:"comment,omitempty"`
+	ContentSHA256     *string    `json:"content_sha256,omitempty"`
+	PublishedVersion  *int       `json:"published_version,omitempty"`
+	PublishedBy       *string    `json:"published_by,omitempty"`
+	PublishedAt       *time.Time `json:"published_at,omitempty"`
+}
+
+type ConfigDraft struct {
+	ConfigDraftMeta
+	BodyRaw  string `json:"body_raw"`
+	BodyJSON any    `json:"body_json,omitempty"`
+}
+
+type DraftReview struct {
+	ID        string    `json:"id"`
+	Revision  int       `json:"revision"`
+	Reviewer  string    `json:"reviewer"`
+	Decision  string    `json:"decision"` // approve | reject | comment
+	Comment   *string   `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GetDraftResponse struct {
+	Config  Config        `json:"config"`
+	Draft   ConfigDraft   `json:"draft"`
+	Reviews []DraftReview `json:"reviews"`
+}
+
+type DraftListResponse struct {
+	Items      []ConfigDraftMeta `json:"items"`
+	NextCursor *string           `json:"next_cursor,omitempty"`
+}
+
+type ConfigScheduleMeta struct {
+	ID               string     `json:"id"`
+	Namespace        string     `json:"namespace"`
+	Path             string     `json:"path"`
+	Status           string     `json:"status"` // pending | published | cancelled | failed | missed
+	PublishAt        time.Time  `json:"publish_at"`
+	BaseVersion      *int       `json:"base_version,omitempty"`
+	MaxDelaySeconds  *int       `json:"max_delay_seconds,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	CreatedBy        *string    `json:"created_by,omitempty"`
+	Comment          *string    `json:"comment,omitempty"`
+	ContentSHA256    *string    `json:"content_sha256,omitempty"`
+	PublishedVersion *int       `json:"published_version,omitempty"`
+	PublishedAt      *time.Time `json:"published_at,omitempty"`
+	FailureReason    *string    `json:"failure_reason,omitempty"`
+}
+
+type ConfigSchedule struct {
+	ConfigScheduleMeta
+	BodyRaw  string `json:"body_raw"`
+	BodyJSON any    `json:"body_json,omitempty"`
+}
+
+type ScheduleListResponse struct {
+	Items      []ConfigScheduleMeta `json:"items"`
+	NextCursor *string              `json:"next_cursor,omitempty"`
+}
+
+type BlameVersion struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy *string   `json:"created_by,omitempty"`
+	Comment   *string   `json:"comment,omitempty"`
+}
+
+type BlameKey struct {
+	Key   string `json:"key"` // JSON Pointer into body_json, e.g. /server/port
+	Value any    `json:"value"`
+	BlameVersion
+}
+
+type BlameLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+	BlameVersion
+}
+
+type BlameResponse struct {
+	Config  Config      `json:"config"`
+	Version int         `json:"version"`
+	Keys    []BlameKey  `json:"keys"`
+	Lines   []BlameLine `json:"lines,omitempty"`
+}
+
+type ChangesetMeta struct {
+	ID                 string    `json:"id"`
+	CreatedAt          time.Time `json:"created_at"`
+	CreatedBy          *string   `json:"created_by,omitempty"`
+	Comment            *string   `json:"comment,omitempty"`
+	RevertsChangesetID *string   `json:"reverts_changeset_id,omitempty"`
+	ItemCount          int       `json:"item_count"`
+}
+
+type ChangesetItem struct {
+	Action          string  `json:"action"` // create | update | delete
+	Namespace       string  `json:"namespace"`
+	Path            string  `json:"path"`
+	ConfigID        *string `json:"config_id,omitempty"`
+	PreviousVersion *int    `json:"previous_version,omitempty"`
+	Version         *int    `json:"version,omitempty"`
+}
+
+type Changeset struct {
+	ChangesetMeta
+	Items []ChangesetItem `json:"items"`
+	// Warnings of the admission webhooks that allowed the changeset (only set when it is applied).
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type ChangesetListResponse struct {
+	Items      []ChangesetMeta `json:"items"`
+	NextCursor *string         `json:"next_cursor,omitempty"`
+}
+
+type ReleaseMeta struct {
+	ID        string    `json:"id"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy *string   `json:"created_by,omitempty"`
+	Comment   *string   `json:"comment,omitempty"`
+	ItemCount int       `json:"item_count"`
+}
+
+type ReleaseItem struct {
+	Path          string       `json:"path"`
+	Format        ConfigFormat `json:"format"`
+	Version       int          `json:"version"`
+	VersionID     string       `json:"version_id"`
+	ContentSHA256 *string      `json:"content_sha256,omitempty"`
+}
+
+type Release struct {
+	ReleaseMeta
+	Items []ReleaseItem `json:"items"`
+}
+
+type ReleaseListResponse struct {
+	Items      []ReleaseMeta `json:"items"`
+	NextCursor *string       `json:"next_cursor,omitempty"`
+}
+
+type ReleaseDiffEntry struct {
+	Path        string `json:"path"`
+	FromVersion *int   `json:"from_version,omitempty"`
+	ToVersion   *int   `json:"to_version,omitempty"`
+}
+
+type ReleaseDiffResponse struct {
+	From      string             `json:"from"`
+	To        string             `json:"to"`
+	Added     []ReleaseDiffEntry `json:"added"`
+	Removed   []ReleaseDiffEntry `json:"removed"`
+	Changed   []ReleaseDiffEntry `json:"changed"`
+	Unchanged int                `json:"unchanged"`
+}
+
+type RollbackResponse struct {
+	Release   ReleaseMeta     `json:"release"`
+	DryRun    bool            `json:"dry_run"`
+	Items     []ChangesetItem `json:"items"`
+	Changeset *Changeset      `json:"changeset,omitempty"`
+}
+
+type MoveItem struct {
+	ConfigID      string `json:"config_id"`
+	FromNamespace string `json:"from_namespace"`
+	FromPath      string `json:"from_path"`
+	ToNamespace   string `json:"to_namespace"`
+	ToPath        string `json:"to_path"`
+}
+
+type Move struct {
+	ID             string     `json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	MovedBy        *string    `json:"moved_by,omitempty"`
+	Comment        *string    `json:"comment,omitempty"`
+	AliasExpiresAt *time.Time `json:"alias_expires_at,omitempty"`
+	Items          []MoveItem `json:"items"`
+}
+
+// ConfigMove is one entry of a config's move history.
+type ConfigMove struct {
+	MoveID         string     `json:"move_id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	MovedBy        *string    `json:"moved_by,omitempty"`
+	Comment        *string    `json:"comment,omitempty"`
+	AliasExpiresAt *time.Time `json:"alias_expires_at,omitempty"`
+	MoveItem
+}
+
+type ConfigMoveListResponse struct {
+	Items      []ConfigMove `json:"items"`
+	NextCursor *string      `json:"next_cursor,omitempty"`
+}
+
+// CloneItem is the planned (dry_run) or applied outcome of cloning one config.
+type CloneItem struct {
+	FromPath string `json:"from_path"`
+	ToPath   string `json:"to_path"`
+	Action   string `json:"action"`           // create | overwrite | skip
+	Reason   string `json:"reason,omitempty"` // for skip: exists | unchanged
+	// Versions is the number of versions copied (or to be copied).
+	Versions int     `json:"versions"`
+	ConfigID *string `json:"config_id,omitempty"`
+	// Version is the resulting latest version of the destination.
+	Version *int `json:"version,omitempty"`
+}
+
+type CloneResponse struct {
+	DryRun      bool        `json:"dry_run"`
+	Namespace   string      `json:"namespace"`
+	ToNamespace string      `json:"to_namespace"`
+	History     string      `json:"history"`
+	OnConflict  string      `json:"on_conflict"`
+	Created     int         `json:"created"`
+	Overwritten int         `json:"overwritten"`
+	Skipped     int         `json:"skipped"`
+	Items       []CloneItem `json:"items"`
+	// Warnings of the admission webhooks that allowed the written destinations.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type BulkDeleteItem struct {
+	ConfigID      string `json:"config_id"`
+	Path          string `json:"path"`
+	LatestVersion int    `json:"latest_version"`
+	Versions      int    `json:"versions"`
+}
+
+// BulkDeleteResponse describes a folder or cascading namespace delete: the plan (dry_run) or what was deleted.
+type BulkDeleteResponse struct {
+	Scope        string           `json:"scope"` // folder | namespace
+	Namespace    string           `json:"namespace"`
+	Prefix       string           `json:"prefix,omitempty"`
+	DryRun       bool             `json:"dry_run"`
+	ConfigCount  int              `json:"config_count"`
+	VersionCount int              `json:"version_count"`
+	Items        []BulkDeleteItem `json:"items"`
+	// ConfirmToken must be sent back as ?confirm= to execute this exact plan.
+	ConfirmToken string `json:"confirm_token,omitempty"`
+}
+
+// Lock blocks writes to a namespace, a folder prefix or a single config until it is removed or expires.
+type Lock struct {
+	ID        string     `json:"id"`
+	Namespace string     `json:"namespace"`
+	Scope     string     `json:"scope"` // namespace | prefix | config
+	Prefix    string     `json:"prefix,omitempty"`
+	Path      string     `json:"path,omitempty"`
+	Reason    string     `json:"reason"`
+	Owner     string     `json:"owner"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type LockListResponse struct {
+	Items []Lock `json:"items"`
+}
+
+// FolderWatchItem is an active config under a watched prefix.
+type FolderWatchItem struct {
+	Path     string `json:"path"`
+	ConfigID string `json:"config_id"`
+	Version  int    `json:"version"`
+}
+
+// FolderWatchResponse is the state of a watched folder; Token is passed back as ?after= to wait for the next change.
+type FolderWatchResponse struct {
+	Namespace string            `json:"namespace"`
+	Prefix    string            `json:"prefix"`
+	Token     string            `json:"token"`
+	Items     []FolderWatchItem `json:"items"`
+}
+
+// ConfigEvent is one change in the event stream (GET /events).
+type ConfigEvent struct {
+	ID            int64     `json:"id"`
+	Type          string    `json:"type"` // config.created | config.updated | config.deleted | config.moved | version.deleted | namespace.*
+	Namespace     string    `json:"namespace"`
+	Path          *string   `json:"path,omitempty"`
+	ConfigID      *string   `json:"config_id,omitempty"`
+	Version       *int      `json:"version,omitempty"`
+	Author        *string   `json:"author,omitempty"`
+	ContentSHA256 *string   `json:"content_sha256,omitempty"`
+	FromNamespace *string   `json:"from_namespace,omitempty"`
+	FromPath      *string   `json:"from_path,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Webhook is an outgoing webhook subscription. Secret is only returned when it is created or rotated.
+type Webhook struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Namespace  *string   `json:"namespace,omitempty"`
+	Prefix     string    `json:"prefix,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type WebhookListResponse struct {
+	Items []Webhook `json:"items"`
+}
+
+// WebhookDelivery is one outbox entry of a webhook and the outcome of its latest attempt.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	EventID        *int64          `json:"event_id,omitempty"`
+	EventType      string          `json:"event_type"`
+	ReplayOf       *string         `json:"replay_of,omitempty"`
+	Status         string          `json:"status"` // pending | succeeded | dead
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Items      []WebhookDelivery `json:"items"`
+	NextCursor *string           `json:"next_cursor,omitempty"`
+}
+
+// AdmissionWebhook is called synchronously before config creates and updates under Prefix of Namespace commit.
+type AdmissionWebhook struct {
+	ID            string    `json:"id"`
+	Namespace     string    `json:"namespace"`
+	Prefix        string    `json:"prefix,omitempty"`
+	Name          string    `json:"name"`
+	URL           string    `json:"url"`
+	TimeoutMS     int       `json:"timeout_ms"`
+	FailurePolicy string    `json:"failure_policy"` // fail_closed | fail_open
+	Active        bool      `json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type AdmissionWebhookListResponse struct {
+	Items []AdmissionWebhook `json:"items"`
+}
+
+// AdmissionDenial records a write rejected by an admission webhook.
+type AdmissionDenial struct {
+	ID          string    `json:"id"`
+	Namespace   string    `json:"namespace"`
+	Path        string    `json:"path"`
+	Operation   string    `json:"operation"` // create | update
+	WebhookID   *string   `json:"webhook_id,omitempty"`
+	WebhookName string    `json:"webhook_name"`
+	Messages    []string  `json:"messages"`
+	Failed      bool      `json:"failed"` // the hook could not be called (fail_closed)
+	CreatedBy   *string   `json:"created_by,omitempty"`
+	RequestID   *string   `json:"request_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type AdmissionDenialListResponse struct {
+	Items      []AdmissionDenial `json:"items"`
+	NextCursor *string           `json:"next_cursor,omitempty"`
+}
+
+// ExportManifest is manifest.json, the first entry of a namespace export archive.
+type ExportManifest struct {
+	Namespace  string               `json:"namespace"`
+	Prefix     string               `json:"prefix,omitempty"`
+	AsOf       *time.Time           `json:"as_of,omitempty"`
+	ExportedAt time.Time            `json:"exported_at"`
+	Items      []ExportManifestItem `json:"items"`
+}
+
+// ExportManifestItem describes one exported config; File is its name inside the archive.
+type ExportManifestItem struct {
+	File          string       `json:"file"`
+	Path          string       `json:"path"`
+	ConfigID      string       `json:"config_id"`
+	Format        ConfigFormat `json:"format"`
+	Version       int          `json:"version"`
+	VersionID     string       `json:"version_id"`
+	ContentSHA256 string       `json:"content_sha256"`
+	CreatedAt     time.Time    `json:"created_at"`
+	CreatedBy     *string      `json:"created_by,omitempty"`
+	Comment       *string      `json:"comment,omitempty"`
+}
+
+// ImportItem is the planned (dry_run) or applied outcome of importing one archive file.
+type ImportItem struct {
+	File   string       `json:"file"`
+	Path   string       `json:"path,omitempty"`
+	Format ConfigFormat `json:"format,omitempty"`
+	Action string       `json:"action"`           // create | update | unchanged | skip
+	Reason string       `json:"reason,omitempty"` // for skip: unsupported_extension
+	// ConfigID is set for existing configs and, unless dry_run, for created ones.
+	ConfigID        *string `json:"config_id,omitempty"`
+	PreviousVersion *int    `json:"previous_version,omitempty"`
+	// Version is the resulting latest version.
+	Version *int `json:"version,omitempty"`
+}
+
+type ImportResponse struct {
+	DryRun    bool         `json:"dry_run"`
+	Namespace string       `json:"namespace"`
+	Prefix    string       `json:"prefix,omitempty"`
+	Created   int          `json:"created"`
+	Updated   int          `json:"updated"`
+	Unchanged int          `json:"unchanged"`
+	Skipped   int          `json:"skipped"`
+	Items     []ImportItem `json:"items"`
+	// Changeset is the changeset that applied the creates and updates (absent for dry_run or when nothing changed).
+	Changeset *Changeset `json:"changeset,omitempty"`
+	// Warnings of the admission webhooks that allowed the creates and updates (also for dry_run).
+	Warnings []string `json:"warnings,omitempty"`
+}
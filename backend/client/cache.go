@@ -0,0 +1,52 @@
+package client
+
+import (
+	"container/list"
+	"sync"
+)
+
+// etagCache keeps the most recently used GET responses by URL together with their ETag.
+type etagCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front = most recently used; values are *cacheEntry
+	entries    map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key  string
+	etag string
+	body []byte
+}
+
+func newETagCache(maxEntries int) *etagCache {
+	return &etagCache{maxEntries: maxEntries, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *etagCache) get(key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	c.order.MoveToFront(el)
+	return *el.Value.(*cacheEntry), true
+}
+
+func (c *etagCache) put(key, etag string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*cacheEntry)
+		e.etag, e.body = etag, body
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, etag: etag, body: body})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
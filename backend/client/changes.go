@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"config-manager/apitypes"
+)
+
+// Move moves or renames a config or a folder, possibly to another namespace.
+func (c *Client) Move(ctx context.Context, in apitypes.MoveRequest) (*apitypes.Move, error) {
+	var out apitypes.Move
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: "/moves", body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Clone copies a config or a folder, possibly to another namespace. With in.DryRun only the plan is returned.
+func (c *Client) Clone(ctx context.Context, in apitypes.CloneRequest) (*apitypes.CloneResponse, error) {
+	var out apitypes.CloneResponse
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: "/clones", body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListChangesets lists changesets, newest first; namespace "" lists every namespace.
+func (c *Client) ListChangesets(ctx context.Context, namespace string, opts ListOptions) (*apitypes.ChangesetListResponse, error) {
+	q := opts.values()
+	if namespace != "" {
+		q.Set("namespace", namespace)
+	}
+	var out apitypes.ChangesetListResponse
+	if err := c.get(ctx, "/changesets", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateChangeset applies creates, updates and deletes of several configs atomically.
+func (c *Client) CreateChangeset(ctx context.Context, in apitypes.CreateChangesetRequest) (*apitypes.Changeset, error) {
+	var out apitypes.Changeset
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: "/changesets", body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetChangeset(ctx context.Context, id string) (*apitypes.Changeset, error) {
+	var out apitypes.Changeset
+	if err := c.get(ctx, "/changesets/"+url.PathEscape(id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevertChangeset applies a new changeset undoing changeset id.
+func (c *Client) RevertChangeset(ctx context.Context, id string, in apitypes.RevertChangesetRequest) (*apitypes.Changeset, error) {
+	var out apitypes.Changeset
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: "/changesets/" + url.PathEscape(id) + "/revert", body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTicketVersions lists the config versions whose comment referenced ticket; namespace "" searches every namespace.
+func (c *Client) ListTicketVersions(ctx context.Context, ticket, namespace string, opts ListOptions) (*apitypes.TicketVersionListResponse, error) {
+	q := opts.values()
+	if namespace != "" {
+		q.Set("namespace", namespace)
+	}
+	var out apitypes.TicketVersionListResponse
+	if err := c.get(ctx, "/tickets/"+url.PathEscape(ticket)+"/versions", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListWebhooks lists outgoing webhooks; namespace "" lists all of them.
+func (c *Client) ListWebhooks(ctx context.Context, namespace string) (*apitypes.WebhookListResponse, error) {
+	q := url.Values{}
+	if namespace != "" {
+		q.Set("namespace", namespace)
+	}
+	var out apitypes.WebhookListResponse
+	if err := c.get(ctx, "/webhooks", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateWebhook registers an outgoing webhook. The returned Secret is only ever sent back here and on rotation.
+func (c *Client) CreateWebhook(ctx context.Context, in apitypes.CreateWebhookRequest) (*apitypes.Webhook, error) {
+	var out apitypes.Webhook
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: "/webhooks", body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id string) (*apitypes.Webhook, error) {
+	var out apitypes.Webhook
+	if err := c.get(ctx, "/webhooks/"+url.PathEscape(id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id string, in apitypes.UpdateWebhookRequest) (*apitypes.Webhook, error) {
+	var out apitypes.Webhook
+	if _, err := c.call(ctx, request{method: http.MethodPatch, path: "/webhooks/" + url.PathEscape(id), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	_, err := c.call(ctx, request{method: http.MethodDelete, path: "/webhooks/" + url.PathEscape(id)}, nil)
+	return err
+}
+
+// TestWebhook sends a webhook.test event once and returns the recorded delivery.
+func (c *Client) TestWebhook(ctx context.Context, id string) (*apitypes.WebhookDelivery, error) {
+	var out apitypes.WebhookDelivery
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: "/webhooks/" + url.PathEscape(id) + "/test"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListWebhookDeliveries lists the deliveries of a webhook, newest first; status "" lists all of them.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id, status string, opts ListOptions) (*apitypes.WebhookDeliveryListResponse, error) {
+	q := opts.values()
+	if status != "" {
+		q.Set("status", status)
+	}
+	var out apitypes.WebhookDeliveryListResponse
+	if err := c.get(ctx, "/webhooks/"+url.PathEscape(id)+"/deliveries", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetWebhookDelivery(ctx context.Context, id, deliveryID string) (*apitypes.WebhookDelivery, error) {
+	var out apitypes.WebhookDelivery
+	if err := c.get(ctx, "/webhooks/"+url.PathEscape(id)+"/deliveries/"+url.PathEscape(deliveryID), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReplayWebhookDelivery queues a new delivery with the payload of deliveryID.
+func (c *Client) ReplayWebhookDelivery(ctx context.Context, id, deliveryID string) (*apitypes.WebhookDelivery, error) {
+	var out apitypes.WebhookDelivery
+	p := "/webhooks/" + url.PathEscape(id) + "/deliveries/" + url.PathEscape(deliveryID) + "/replay"
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: p}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,297 @@
+// Package client is the Go client of the config-manager HTTP API.
+//
+// Every endpoint has a method taking and returning the typed bodies of package apitypes, which the server uses too.
+// Non-2xx answers are returned as *Error. GET responses carrying an ETag can be kept in a local cache (WithCache)
+// and are then revalidated with If-None-Match, so unchanged configs cost a 304 without a body. WatchConfig,
+// WatchFolder and StreamEvents call back on changes, and Decode / DecodeConfig read a config body straight into a
+// Go value.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"config-manager/apitypes"
+)
+
+// maxErrorBodyBytes bounds the error body read from a non-2xx answer.
+const maxErrorBodyBytes = 1 << 20
+
+// Client calls one config-manager server. It is safe for concurrent use.
+type Client struct {
+	baseURL      string
+	http         *http.Client
+	userAgent    string
+	lockOverride string
+	cache        *etagCache
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests (default http.DefaultClient). Long polls (WatchConfig,
+// WatchFolder) and event streams need a client without a short Timeout; bound them with the context instead.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// WithLockOverride sends token as X-Lock-Override so writes go through config locks (the server's
+// LOCK_OVERRIDE_TOKEN).
+func WithLockOverride(token string) Option {
+	return func(c *Client) { c.lockOverride = token }
+}
+
+// WithCache keeps up to maxEntries GET responses that carry an ETag and revalidates them with If-None-Match.
+func WithCache(maxEntries int) Option {
+	return func(c *Client) {
+		if maxEntries > 0 {
+			c.cache = newETagCache(maxEntries)
+		}
+	}
+}
+
+// New returns a client for the server at baseURL, including any HTTP_BASE_PATH (e.g. http://config-manager:8080/api).
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("client: base URL must be an absolute http(s) URL: %q", baseURL)
+	}
+	c := &Client{
+		baseURL:   strings.TrimRight(u.String(), "/"),
+		http:      http.DefaultClient,
+		userAgent: "config-manager-go-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Error is a non-2xx answer of the server, with the structured error body when it sent one.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    map[string]any
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("config-manager: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("config-manager: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// ErrorCode returns the API error code of err (e.g. "not_found", "version_conflict"), or "" if err is not an *Error.
+func ErrorCode(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// IsNotFound reports whether err is a 404 answer.
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.StatusCode == http.StatusNotFound
+}
+
+// ListOptions pages through list endpoints; pass the previous response's NextCursor as Cursor. Zero values use the
+// server defaults.
+type ListOptions struct {
+	Limit  int
+	Cursor string
+}
+
+func (o ListOptions) values() url.Values {
+	q := url.Values{}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Cursor != "" {
+		q.Set("cursor", o.Cursor)
+	}
+	return q
+}
+
+// request is one API call; path is relative to the base URL and already escaped.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   any // JSON-encoded, or an io.Reader sent as is
+}
+
+// send performs r and returns the response if its status is 2xx or 304; any other status is returned as *Error.
+func (c *Client) send(ctx context.Context, r request) (*http.Response, error) {
+	u := c.baseURL + r.path
+	if len(r.query) > 0 {
+		u += "?" + r.query.Encode()
+	}
+	var body io.Reader
+	var contentType string
+	switch b := r.body.(type) {
+	case nil:
+	case io.Reader:
+		body = b // sent as is (uploads); the caller sets Content-Type
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, r.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range r.header {
+		httpReq.Header[k] = vs
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	if httpReq.Header.Get("Accept") == "" {
+		httpReq.Header.Set("Accept", "application/json")
+	}
+	if c.userAgent != "" {
+		httpReq.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.lockOverride != "" && r.method != http.MethodGet {
+		httpReq.Header.Set("X-Lock-Override", c.lockOverride)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if (resp.StatusCode >= 200 && resp.StatusCode <= 299) || resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var e apitypes.Error
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	if json.Unmarshal(b, &e) != nil {
+		e = apitypes.Error{}
+	}
+	return nil, &Error{StatusCode: resp.StatusCode, Code: e.Code, Message: e.Message, Details: e.Details}
+}
+
+// call performs r and decodes a JSON answer into out (unless out is nil). It returns the response status.
+func (c *Client) call(ctx context.Context, r request, out any) (int, error) {
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if out == nil || resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("client: decode %s %s response: %w", r.method, r.path, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// get performs a GET through the ETag cache: a cached response is revalidated with If-None-Match and reused on 304.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	if c.cache == nil {
+		_, err := c.call(ctx, request{method: http.MethodGet, path: path, query: query}, out)
+		return err
+	}
+
+	key := path
+	if len(query) > 0 {
+		key += "?" + query.Encode()
+	}
+	r := request{method: http.MethodGet, path: path, query: query, header: http.Header{}}
+	cached, ok := c.cache.get(key)
+	if ok {
+		r.header.Set("If-None-Match", cached.etag)
+	}
+	resp, err := c.send(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var body []byte
+	if resp.StatusCode == http.StatusNotModified && ok {
+		body = cached.body
+	} else {
+		if body, err = io.ReadAll(resp.Body); err != nil {
+			return err
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" && resp.StatusCode == http.StatusOK {
+			c.cache.put(key, etag, body)
+		}
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("client: decode GET %s response: %w", path, err)
+	}
+	return nil
+}
+
+// escapePath escapes each segment of a slash-separated path (config paths, folder prefixes).
+func escapePath(p string) string {
+	segs := strings.Split(p, "/")
+	for i, s := range segs {
+		segs[i] = url.PathEscape(s)
+	}
+	return strings.Join(segs, "/")
+}
+
+func namespacePath(namespace string, rest ...string) string {
+	p := "/namespaces/" + url.PathEscape(namespace)
+	for _, r := range rest {
+		p += "/" + r
+	}
+	return p
+}
+
+func configPath(namespace, path string, rest ...string) string {
+	p := "/configs/" + url.PathEscape(namespace) + "/" + escapePath(strings.Trim(path, "/"))
+	for _, r := range rest {
+		p += "/" + r
+	}
+	return p
+}
+
+func ifMatchHeader(etag string) http.Header {
+	if etag == "" {
+		return nil
+	}
+	return http.Header{"If-Match": {etag}}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// Health calls /healthz.
+func (c *Client) Health(ctx context.Context) error {
+	_, err := c.call(ctx, request{method: http.MethodGet, path: "/healthz"}, nil)
+	return err
+}
+
+// Ready calls /readyz; it fails with a 503 *Error while the database is not reachable.
+func (c *Client) Ready(ctx context.Context) error {
+	_, err := c.call(ctx, request{method: http.MethodGet, path: "/readyz"}, nil)
+	return err
+}
@@ -0,0 +1,296 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"config-manager/apitypes"
+)
+
+type ConfigListOptions struct {
+	// Namespace "" lists every namespace.
+	Namespace string
+	Prefix    string
+	// Recursive nil uses the server default.
+	Recursive *bool
+	AsOf      *time.Time
+	ListOptions
+}
+
+func (c *Client) ListConfigs(ctx context.Context, opts ConfigListOptions) (*apitypes.ConfigListResponse, error) {
+	q := opts.values()
+	if opts.Namespace != "" {
+		q.Set("namespace", opts.Namespace)
+	}
+	if opts.Prefix != "" {
+		q.Set("prefix", opts.Prefix)
+	}
+	if opts.Recursive != nil {
+		q.Set("recursive", strconv.FormatBool(*opts.Recursive))
+	}
+	if opts.AsOf != nil {
+		q.Set("as_of", formatTime(*opts.AsOf))
+	}
+	var out apitypes.ConfigListResponse
+	if err := c.get(ctx, "/configs", q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOptions selects the version returned by GetConfig; at most one field may be set. The zero value reads latest.
+type GetOptions struct {
+	Tag     string
+	AsOf    *time.Time
+	Release string
+}
+
+func (o GetOptions) values() url.Values {
+	q := url.Values{}
+	if o.Tag != "" {
+		q.Set("tag", o.Tag)
+	}
+	if o.AsOf != nil {
+		q.Set("as_of", formatTime(*o.AsOf))
+	}
+	if o.Release != "" {
+		q.Set("release", o.Release)
+	}
+	return q
+}
+
+func (c *Client) GetConfig(ctx context.Context, namespace, path string, opts GetOptions) (*apitypes.GetConfigResponse, error) {
+	var out apitypes.GetConfigResponse
+	if err := c.get(ctx, configPath(namespace, path), opts.values(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetConfigInto reads a config like GetConfig and decodes its body into v (see Decode).
+func (c *Client) GetConfigInto(ctx context.Context, namespace, path string, opts GetOptions, v any) (*apitypes.GetConfigResponse, error) {
+	out, err := c.GetConfig(ctx, namespace, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := Decode(out.Config.Format, out.Latest.BodyRaw, v); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *Client) CreateConfig(ctx context.Context, namespace, path string, in apitypes.CreateConfigRequest) (*apitypes.GetConfigResponse, error) {
+	var out apitypes.GetConfigResponse
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: configPath(namespace, path), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateConfig creates a new version. ifMatch (see VersionETag) makes the update fail with 412 unless latest still
+// has that ETag; "" skips the check.
+func (c *Client) UpdateConfig(ctx context.Context, namespace, path string, in apitypes.UpdateConfigRequest, ifMatch string) (*apitypes.GetConfigResponse, error) {
+	var out apitypes.GetConfigResponse
+	r := request{method: http.MethodPut, path: configPath(namespace, path), header: ifMatchHeader(ifMatch), body: in}
+	if _, err := c.call(ctx, r, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteConfig deletes a config (its history stays readable with as_of). ifMatch works as in UpdateConfig.
+func (c *Client) DeleteConfig(ctx context.Context, namespace, path, ifMatch string) error {
+	_, err := c.call(ctx, request{method: http.MethodDelete, path: configPath(namespace, path), header: ifMatchHeader(ifMatch)}, nil)
+	return err
+}
+
+// VersionETag returns the version ETag of v, for If-Match. GET responses carry longer ETags that also hash the body;
+// writes accept either.
+func VersionETag(v apitypes.ConfigVersion) string {
+	sum := ""
+	if v.ContentSHA256 != nil {
+		sum = *v.ContentSHA256
+	}
+	if sum == "" {
+		h := sha256.Sum256([]byte(v.BodyRaw))
+		sum = hex.EncodeToString(h[:])
+	}
+	return `"v` + strconv.Itoa(v.Version) + "-" + sum + `"`
+}
+
+func (c *Client) ListVersions(ctx context.Context, namespace, path string, opts ListOptions) (*apitypes.VersionListResponse, error) {
+	var out apitypes.VersionListResponse
+	if err := c.get(ctx, configPath(namespace, path, "versions"), opts.values(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetVersion(ctx context.Context, namespace, path string, version int) (*apitypes.GetVersionResponse, error) {
+	var out apitypes.GetVersionResponse
+	if err := c.get(ctx, configPath(namespace, path, "versions", strconv.Itoa(version)), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteVersion deletes one version of a config; ifMatch works as in UpdateConfig, against that version.
+func (c *Client) DeleteVersion(ctx context.Context, namespace, path string, version int, ifMatch string) error {
+	r := request{method: http.MethodDelete, path: configPath(namespace, path, "versions", strconv.Itoa(version)), header: ifMatchHeader(ifMatch)}
+	_, err := c.call(ctx, r, nil)
+	return err
+}
+
+func (c *Client) ListTags(ctx context.Context, namespace, path string) (*apitypes.TagListResponse, error) {
+	var out apitypes.TagListResponse
+	if err := c.get(ctx, configPath(namespace, path, "tags"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetTag points tag at in.Version, creating the tag if needed; created reports whether it did.
+func (c *Client) SetTag(ctx context.Context, namespace, path, tag string, in apitypes.SetTagRequest) (out *apitypes.ConfigTag, created bool, err error) {
+	out = &apitypes.ConfigTag{}
+	status, err := c.call(ctx, request{method: http.MethodPut, path: configPath(namespace, path, "tags", url.PathEscape(tag)), body: in}, out)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, status == http.StatusCreated, nil
+}
+
+func (c *Client) DeleteTag(ctx context.Context, namespace, path, tag string) error {
+	_, err := c.call(ctx, request{method: http.MethodDelete, path: configPath(namespace, path, "tags", url.PathEscape(tag))}, nil)
+	return err
+}
+
+func (c *Client) ListTagHistory(ctx context.Context, namespace, path, tag string, opts ListOptions) (*apitypes.TagHistoryResponse, error) {
+	var out apitypes.TagHistoryResponse
+	if err := c.get(ctx, configPath(namespace, path, "tags", url.PathEscape(tag), "history"), opts.values(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListConfigMoves lists the moves and renames a config went through.
+func (c *Client) ListConfigMoves(ctx context.Context, namespace, path string, opts ListOptions) (*apitypes.ConfigMoveListResponse, error) {
+	var out apitypes.ConfigMoveListResponse
+	if err := c.get(ctx, configPath(namespace, path, "moves"), opts.values(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListDrafts lists the drafts of a config; status "" lists all of them.
+func (c *Client) ListDrafts(ctx context.Context, namespace, path, status string, opts ListOptions) (*apitypes.DraftListResponse, error) {
+	q := opts.values()
+	if status != "" {
+		q.Set("status", status)
+	}
+	var out apitypes.DraftListResponse
+	if err := c.get(ctx, configPath(namespace, path, "drafts"), q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) CreateDraft(ctx context.Context, namespace, path string, in apitypes.CreateDraftRequest) (*apitypes.GetDraftResponse, error) {
+	var out apitypes.GetDraftResponse
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: configPath(namespace, path, "drafts"), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetDraft(ctx context.Context, namespace, path, draftID string) (*apitypes.GetDraftResponse, error) {
+	var out apitypes.GetDraftResponse
+	if err := c.get(ctx, configPath(namespace, path, "drafts", url.PathEscape(draftID)), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RebaseDraft moves an open draft onto a newer base version, optionally replacing its body.
+func (c *Client) RebaseDraft(ctx context.Context, namespace, path, draftID string, in apitypes.RebaseDraftRequest) (*apitypes.GetDraftResponse, error) {
+	var out apitypes.GetDraftResponse
+	if _, err := c.call(ctx, request{method: http.MethodPut, path: configPath(namespace, path, "drafts", url.PathEscape(draftID)), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ReviewDraft(ctx context.Context, namespace, path, draftID string, in apitypes.DraftReviewRequest) (*apitypes.GetDraftResponse, error) {
+	var out apitypes.GetDraftResponse
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: configPath(namespace, path, "drafts", url.PathEscape(draftID), "reviews"), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PublishDraft publishes an approved draft as the config's next version.
+func (c *Client) PublishDraft(ctx context.Context, namespace, path, draftID string, in apitypes.PublishDraftRequest) (*apitypes.GetConfigResponse, error) {
+	var out apitypes.GetConfigResponse
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: configPath(namespace, path, "drafts", url.PathEscape(draftID), "publish"), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSchedules lists the scheduled publishes of a config (opts.Prefix is ignored).
+func (c *Client) ListSchedules(ctx context.Context, namespace, path string, opts ScheduleListOptions) (*apitypes.ScheduleListResponse, error) {
+	opts.Prefix = ""
+	var out apitypes.ScheduleListResponse
+	if err := c.get(ctx, configPath(namespace, path, "schedules"), opts.values(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) CreateSchedule(ctx context.Context, namespace, path string, in apitypes.CreateScheduleRequest) (*apitypes.ConfigSchedule, error) {
+	var out apitypes.ConfigSchedule
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: configPath(namespace, path, "schedules"), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetSchedule(ctx context.Context, namespace, path, scheduleID string) (*apitypes.ConfigSchedule, error) {
+	var out apitypes.ConfigSchedule
+	if err := c.get(ctx, configPath(namespace, path, "schedules", url.PathEscape(scheduleID)), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Reschedule moves a pending schedule to publishAt.
+func (c *Client) Reschedule(ctx context.Context, namespace, path, scheduleID string, publishAt time.Time) (*apitypes.ConfigSchedule, error) {
+	var out apitypes.ConfigSchedule
+	in := apitypes.RescheduleRequest{PublishAt: formatTime(publishAt)}
+	if _, err := c.call(ctx, request{method: http.MethodPut, path: configPath(namespace, path, "schedules", url.PathEscape(scheduleID)), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) CancelSchedule(ctx context.Context, namespace, path, scheduleID string) error {
+	_, err := c.call(ctx, request{method: http.MethodDelete, path: configPath(namespace, path, "schedules", url.PathEscape(scheduleID))}, nil)
+	return err
+}
+
+// Blame attributes each key (and with lines, each line) of the latest version to the version that last changed it.
+func (c *Client) Blame(ctx context.Context, namespace, path string, lines bool) (*apitypes.BlameResponse, error) {
+	q := url.Values{}
+	if lines {
+		q.Set("lines", "true")
+	}
+	var out apitypes.BlameResponse
+	if err := c.get(ctx, configPath(namespace, path, "blame"), q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"config-manager/apitypes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Decode decodes a config body (body_raw) of the given format into v: JSON bodies with encoding/json (json struct
+// tags), YAML bodies with gopkg.in/yaml.v3 (yaml struct tags).
+func Decode(format apitypes.ConfigFormat, bodyRaw string, v any) error {
+	switch format {
+	case apitypes.FormatJSON:
+		if err := json.Unmarshal([]byte(bodyRaw), v); err != nil {
+			return fmt.Errorf("client: decode json config: %w", err)
+		}
+	case apitypes.FormatYAML:
+		if err := yaml.Unmarshal([]byte(bodyRaw), v); err != nil {
+			return fmt.Errorf("client: decode yaml config: %w", err)
+		}
+	default:
+		return fmt.Errorf("client: unsupported config format %q", format)
+	}
+	return nil
+}
+
+// DecodeConfig decodes the body of a GetConfig (or WatchConfig) response into a new T.
+func DecodeConfig[T any](resp *apitypes.GetConfigResponse) (T, error) {
+	var v T
+	err := Decode(resp.Config.Format, resp.Latest.BodyRaw, &v)
+	return v, err
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"config-manager/apitypes"
+)
+
+type ImportOptions struct {
+	// Prefix is the folder the archive's files are imported into ("" = namespace root).
+	Prefix string
+	// DryRun returns the plan without writing anything.
+	DryRun    bool
+	CreatedBy string
+	Comment   string
+}
+
+func (o ImportOptions) values() url.Values {
+	q := url.Values{}
+	if o.Prefix != "" {
+		q.Set("prefix", o.Prefix)
+	}
+	if o.DryRun {
+		q.Set("dry_run", "true")
+	}
+	if o.CreatedBy != "" {
+		q.Set("created_by", o.CreatedBy)
+	}
+	if o.Comment != "" {
+		q.Set("comment", o.Comment)
+	}
+	return q
+}
+
+// ImportNamespace uploads a tar, tar.gz or zip archive (e.g. one from ExportNamespace) into a namespace: missing
+// configs are created and changed ones get a new version, all in one changeset.
+func (c *Client) ImportNamespace(ctx context.Context, namespace string, archive io.Reader, opts ImportOptions) (*apitypes.ImportResponse, error) {
+	header := http.Header{"Content-Type": {"application/octet-stream"}}
+	var out apitypes.ImportResponse
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: namespacePath(namespace, "import"), query: opts.values(), header: header, body: archive}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ImportDirectory imports the files below dir: each file's path relative to dir becomes its config path (under
+// opts.Prefix). Hidden files and folders are left out. The directory is streamed as a tar archive.
+func (c *Client) ImportDirectory(ctx context.Context, namespace, dir string, opts ImportOptions) (*apitypes.ImportResponse, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeDirTar(pw, dir))
+	}()
+	defer pr.Close()
+	return c.ImportNamespace(ctx, namespace, pr, opts)
+}
+
+func writeDirTar(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		hdr.Format = tar.FormatPAX
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.CopyN(tw, f, hdr.Size)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"config-manager/apitypes"
+)
+
+func (c *Client) ListNamespaces(ctx context.Context, opts ListOptions) (*apitypes.NamespaceListResponse, error) {
+	var out apitypes.NamespaceListResponse
+	if err := c.get(ctx, "/namespaces", opts.values(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) CreateNamespace(ctx context.Context, name string) (*apitypes.Namespace, error) {
+	var out apitypes.Namespace
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: "/namespaces", body: apitypes.CreateNamespaceRequest{Name: name}}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RenameNamespace renames a namespace; the old name keeps redirecting for in.AliasTTLSeconds.
+func (c *Client) RenameNamespace(ctx context.Context, namespace string, in apitypes.RenameNamespaceRequest) (*apitypes.Namespace, error) {
+	var out apitypes.Namespace
+	if _, err := c.call(ctx, request{method: http.MethodPatch, path: namespacePath(namespace), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteNamespace deletes an empty namespace.
+func (c *Client) DeleteNamespace(ctx context.Context, namespace string) error {
+	_, err := c.call(ctx, request{method: http.MethodDelete, path: namespacePath(namespace)}, nil)
+	return err
+}
+
+// DeleteNamespaceCascade deletes a namespace with all its configs. With confirm "" it only returns the plan; pass
+// the plan's ConfirmToken as confirm to execute it.
+func (c *Client) DeleteNamespaceCascade(ctx context.Context, namespace, confirm string) (*apitypes.BulkDeleteResponse, error) {
+	q := url.Values{"cascade": {"true"}}
+	if confirm != "" {
+		q.Set("confirm", confirm)
+	}
+	var out apitypes.BulkDeleteResponse
+	if _, err := c.call(ctx, request{method: http.MethodDelete, path: namespacePath(namespace), query: q}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteFolder deletes every config under prefix. With confirm "" it only returns the plan; pass the plan's
+// ConfirmToken as confirm to execute it.
+func (c *Client) DeleteFolder(ctx context.Context, namespace, prefix, confirm string) (*apitypes.BulkDeleteResponse, error) {
+	q := url.Values{}
+	if confirm != "" {
+		q.Set("confirm", confirm)
+	}
+	var out apitypes.BulkDeleteResponse
+	p := namespacePath(namespace, "folders", escapePath(strings.Trim(prefix, "/"))+"/")
+	if _, err := c.call(ctx, request{method: http.MethodDelete, path: p, query: q}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type BrowseOptions struct {
+	Prefix string
+	// AsOf browses the namespace as it was at that time.
+	AsOf *time.Time
+	ListOptions
+}
+
+// Browse lists the folders and configs directly under opts.Prefix. Items are apitypes.BrowseEntryFolder and
+// apitypes.BrowseEntryConfig values decoded as map[string]any; switch on "type".
+func (c *Client) Browse(ctx context.Context, namespace string, opts BrowseOptions) (*apitypes.BrowseResponse, error) {
+	q := opts.values()
+	if opts.Prefix != "" {
+		q.Set("prefix", opts.Prefix)
+	}
+	if opts.AsOf != nil {
+		q.Set("as_of", formatTime(*opts.AsOf))
+	}
+	var out apitypes.BrowseResponse
+	if err := c.get(ctx, namespacePath(namespace, "browse"), q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type ExportOptions struct {
+	Prefix string
+	// Format is "tar" (default) or "zip".
+	Format string
+	// AsOf exports the configs as they were at that time.
+	AsOf *time.Time
+}
+
+// ExportNamespace returns the export archive of a namespace as a stream; the caller must close it. The first entry
+// is manifest.json (apitypes.ExportManifest). A connection that breaks mid-archive surfaces as a read error.
+func (c *Client) ExportNamespace(ctx context.Context, namespace string, opts ExportOptions) (io.ReadCloser, error) {
+	q := url.Values{}
+	if opts.Prefix != "" {
+		q.Set("prefix", opts.Prefix)
+	}
+	if opts.Format != "" {
+		q.Set("format", opts.Format)
+	}
+	if opts.AsOf != nil {
+		q.Set("as_of", formatTime(*opts.AsOf))
+	}
+	header := http.Header{"Accept": {"application/x-tar, application/zip"}}
+	resp, err := c.send(ctx, request{method: http.MethodGet, path: namespacePath(namespace, "export"), query: q, header: header})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) GetNamespacePolicy(ctx context.Context, namespace string) (*apitypes.NamespacePolicy, error) {
+	var out apitypes.NamespacePolicy
+	if err := c.get(ctx, namespacePath(namespace, "policy"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) UpdateNamespacePolicy(ctx context.Context, namespace string, in apitypes.UpdateNamespacePolicyRequest) (*apitypes.NamespacePolicy, error) {
+	var out apitypes.NamespacePolicy
+	if _, err := c.call(ctx, request{method: http.MethodPut, path: namespacePath(namespace, "policy"), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type ScheduleListOptions struct {
+	// Prefix only applies to ListNamespaceSchedules.
+	Prefix string
+	Status string // pending | published | cancelled | failed | missed
+	ListOptions
+}
+
+func (o ScheduleListOptions) values() url.Values {
+	q := o.ListOptions.values()
+	if o.Prefix != "" {
+		q.Set("prefix", o.Prefix)
+	}
+	if o.Status != "" {
+		q.Set("status", o.Status)
+	}
+	return q
+}
+
+// ListNamespaceSchedules lists the scheduled publishes of every config in a namespace.
+func (c *Client) ListNamespaceSchedules(ctx context.Context, namespace string, opts ScheduleListOptions) (*apitypes.ScheduleListResponse, error) {
+	var out apitypes.ScheduleListResponse
+	if err := c.get(ctx, namespacePath(namespace, "schedules"), opts.values(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListLocks lists the live locks of a namespace; prefix "" lists all of them.
+func (c *Client) ListLocks(ctx context.Context, namespace, prefix string) (*apitypes.LockListResponse, error) {
+	q := url.Values{}
+	if prefix != "" {
+		q.Set("prefix", prefix)
+	}
+	var out apitypes.LockListResponse
+	if err := c.get(ctx, namespacePath(namespace, "locks"), q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) CreateLock(ctx context.Context, namespace string, in apitypes.CreateLockRequest) (*apitypes.Lock, error) {
+	var out apitypes.Lock
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: namespacePath(namespace, "locks"), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteLock(ctx context.Context, namespace, lockID string) error {
+	_, err := c.call(ctx, request{method: http.MethodDelete, path: namespacePath(namespace, "locks", url.PathEscape(lockID))}, nil)
+	return err
+}
+
+func (c *Client) ListReleases(ctx context.Context, namespace string, opts ListOptions) (*apitypes.ReleaseListResponse, error) {
+	var out apitypes.ReleaseListResponse
+	if err := c.get(ctx, namespacePath(namespace, "releases"), opts.values(), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) CreateRelease(ctx context.Context, namespace string, in apitypes.CreateReleaseRequest) (*apitypes.Release, error) {
+	var out apitypes.Release
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: namespacePath(namespace, "releases"), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetRelease(ctx context.Context, namespace, release string) (*apitypes.Release, error) {
+	var out apitypes.Release
+	if err := c.get(ctx, namespacePath(namespace, "releases", url.PathEscape(release)), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DiffReleases compares release from with release to.
+func (c *Client) DiffReleases(ctx context.Context, namespace, from, to string) (*apitypes.ReleaseDiffResponse, error) {
+	var out apitypes.ReleaseDiffResponse
+	if err := c.get(ctx, namespacePath(namespace, "releases", url.PathEscape(from), "diff"), url.Values{"to": {to}}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RollbackRelease restores the configs under a release's prefix to the versions it pinned, as one changeset.
+// With in.DryRun only the planned items are returned.
+func (c *Client) RollbackRelease(ctx context.Context, namespace, release string, in apitypes.RollbackRequest) (*apitypes.RollbackResponse, error) {
+	var out apitypes.RollbackResponse
+	p := namespacePath(namespace, "releases", url.PathEscape(release), "rollback")
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: p, body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListAdmissionWebhooks(ctx context.Context, namespace string) (*apitypes.AdmissionWebhookListResponse, error) {
+	var out apitypes.AdmissionWebhookListResponse
+	if err := c.get(ctx, namespacePath(namespace, "admission-webhooks"), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) CreateAdmissionWebhook(ctx context.Context, namespace string, in apitypes.CreateAdmissionWebhookRequest) (*apitypes.AdmissionWebhook, error) {
+	var out apitypes.AdmissionWebhook
+	if _, err := c.call(ctx, request{method: http.MethodPost, path: namespacePath(namespace, "admission-webhooks"), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetAdmissionWebhook(ctx context.Context, namespace, id string) (*apitypes.AdmissionWebhook, error) {
+	var out apitypes.AdmissionWebhook
+	if err := c.get(ctx, namespacePath(namespace, "admission-webhooks", url.PathEscape(id)), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) UpdateAdmissionWebhook(ctx context.Context, namespace, id string, in apitypes.UpdateAdmissionWebhookRequest) (*apitypes.AdmissionWebhook, error) {
+	var out apitypes.AdmissionWebhook
+	if _, err := c.call(ctx, request{method: http.MethodPatch, path: namespacePath(namespace, "admission-webhooks", url.PathEscape(id)), body: in}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) DeleteAdmissionWebhook(ctx context.Context, namespace, id string) error {
+	_, err := c.call(ctx, request{method: http.MethodDelete, path: namespacePath(namespace, "admission-webhooks", url.PathEscape(id))}, nil)
+	return err
+}
+
+// ListAdmissionDenials lists writes rejected by admission webhooks, newest first; path "" lists every config.
+func (c *Client) ListAdmissionDenials(ctx context.Context, namespace, path string, opts ListOptions) (*apitypes.AdmissionDenialListResponse, error) {
+	q := opts.values()
+	if path != "" {
+		q.Set("path", path)
+	}
+	var out apitypes.AdmissionDenialListResponse
+	if err := c.get(ctx, namespacePath(namespace, "admission-denials"), q, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"config-manager/apitypes"
+)
+
+const (
+	// defaultWatchWait is the long-poll wait used when WatchOptions.Wait is zero (the server allows up to 5m).
+	defaultWatchWait = 30 * time.Second
+	// watchRetryBase is the delay before retrying a failed poll or a dropped stream; it doubles up to watchRetryMax.
+	watchRetryBase = time.Second
+	watchRetryMax  = 30 * time.Second
+	// maxEventBytes bounds one line of the event stream.
+	maxEventBytes = 1 << 20
+)
+
+type WatchOptions struct {
+	// Wait is how long each long poll blocks before the server answers 304 and the poll is repeated.
+	Wait time.Duration
+	// AfterVersion is the version the caller already has; 0 calls back with the current latest first.
+	AfterVersion int
+}
+
+// WatchConfig long-polls a config and calls fn with each new latest version until ctx is done or fn returns an error.
+// Network and 5xx failures are retried with backoff; other errors (e.g. the config was deleted: 404) end the watch.
+// It returns fn's error, ctx's error or the error that ended the watch.
+func (c *Client) WatchConfig(ctx context.Context, namespace, path string, opts WatchOptions, fn func(*apitypes.GetConfigResponse) error) error {
+	wait := watchWait(opts.Wait)
+	after := opts.AfterVersion
+	return c.poll(ctx, func() error {
+		q := url.Values{"wait": {wait}, "after_version": {strconv.Itoa(after)}}
+		var out apitypes.GetConfigResponse
+		status, err := c.call(ctx, request{method: http.MethodGet, path: configPath(namespace, path), query: q}, &out)
+		if err != nil || status == http.StatusNotModified {
+			return err
+		}
+		after = out.Latest.Version
+		return callback(fn(&out))
+	})
+}
+
+// WatchFolder long-polls the configs under prefix and calls fn with the folder state (every active config and its
+// latest version) first, then whenever a config under prefix is created, updated, deleted or moved. It ends and
+// retries like WatchConfig.
+func (c *Client) WatchFolder(ctx context.Context, namespace, prefix string, wait time.Duration, fn func(*apitypes.FolderWatchResponse) error) error {
+	w := watchWait(wait)
+	token := ""
+	return c.poll(ctx, func() error {
+		q := url.Values{"wait": {w}}
+		if prefix != "" {
+			q.Set("prefix", prefix)
+		}
+		if token != "" {
+			q.Set("after", token)
+		}
+		var out apitypes.FolderWatchResponse
+		status, err := c.call(ctx, request{method: http.MethodGet, path: namespacePath(namespace, "watch"), query: q}, &out)
+		if err != nil || status == http.StatusNotModified {
+			return err
+		}
+		token = out.Token
+		return callback(fn(&out))
+	})
+}
+
+type EventOptions struct {
+	// Namespace and Prefix filter the stream; "" matches everything.
+	Namespace string
+	Prefix    string
+	// LastEventID resumes after that event; 0 starts with the next change.
+	LastEventID int64
+}
+
+// StreamEvents reads the server-sent event stream (GET /events) and calls fn with each event until ctx is done or fn
+// returns an error. Dropped connections are resumed after the last event seen (Last-Event-ID), so once an event was
+// received none is skipped or repeated.
+func (c *Client) StreamEvents(ctx context.Context, opts EventOptions, fn func(apitypes.ConfigEvent) error) error {
+	lastID := opts.LastEventID
+	return c.poll(ctx, func() error {
+		q := url.Values{}
+		if opts.Namespace != "" {
+			q.Set("namespace", opts.Namespace)
+		}
+		if opts.Prefix != "" {
+			q.Set("prefix", opts.Prefix)
+		}
+		header := http.Header{"Accept": {"text/event-stream"}}
+		if lastID > 0 {
+			header.Set("Last-Event-ID", strconv.FormatInt(lastID, 10))
+		}
+		resp, err := c.send(ctx, request{method: http.MethodGet, path: "/events", query: q, header: header})
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		sc := bufio.NewScanner(resp.Body)
+		sc.Buffer(make([]byte, 0, 64<<10), maxEventBytes)
+		var data strings.Builder
+		for sc.Scan() {
+			line := sc.Text()
+			if line != "" {
+				// Only data is needed: id and type are repeated inside it.
+				if v, ok := strings.CutPrefix(line, "data:"); ok {
+					data.WriteString(strings.TrimPrefix(v, " "))
+				}
+				continue
+			}
+			if data.Len() == 0 {
+				continue
+			}
+			var e apitypes.ConfigEvent
+			if err := json.Unmarshal([]byte(data.String()), &e); err != nil {
+				return callback(fmt.Errorf("client: decode event: %w", err))
+			}
+			data.Reset()
+			if err := fn(e); err != nil {
+				return callback(err)
+			}
+			lastID = e.ID
+		}
+		if err := sc.Err(); err != nil {
+			return err
+		}
+		return errors.New("client: event stream closed")
+	})
+}
+
+func watchWait(d time.Duration) string {
+	if d <= 0 {
+		d = defaultWatchWait
+	}
+	return strconv.Itoa(max(int(d.Seconds()), 1))
+}
+
+// callbackError marks an error that ends a watch without retry.
+type callbackError struct{ err error }
+
+func (e callbackError) Error() string { return e.err.Error() }
+
+func callback(err error) error {
+	if err == nil {
+		return nil
+	}
+	return callbackError{err}
+}
+
+// poll calls once until ctx is done or once fails permanently: with a callback error, or an *Error other than 5xx.
+// Other failures are retried with backoff; a successful or long-lived call (a stream that ran for a while) resets it.
+func (c *Client) poll(ctx context.Context, once func() error) error {
+	delay := watchRetryBase
+	for {
+		start := time.Now()
+		err := once()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var cbErr callbackError
+		if errors.As(err, &cbErr) {
+			return cbErr.err
+		}
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode < 500 {
+			return err
+		}
+		if err == nil || time.Since(start) > watchRetryMax {
+			delay = watchRetryBase
+		}
+		if err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, watchRetryMax)
+	}
+}
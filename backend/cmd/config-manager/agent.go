@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"config-manager/internal/agent"
+)
+
+// runAgent is `config-manager agent`: it syncs configs from a config-manager server to local files (see
+// confs/agent.example.yaml).
+func runAgent(args []string) {
+	fs := flag.NewFlagSet("agent", flag.ExitOnError)
+	configPath := fs.String("config", "agent.yaml", "path to the agent configuration (server, targetDir, syncs)")
+	_ = fs.Parse(args)
+
+	cfg, err := agent.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("agent config: %v", err)
+	}
+	a, err := agent.New(cfg)
+	if err != nil {
+		log.Fatalf("agent: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("config-manager agent syncing %d selector(s) from %s to %s", len(cfg.Syncs), cfg.Server, cfg.TargetDir)
+	if err := a.Run(ctx); err != nil {
+		log.Fatalf("agent: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"config-manager/apitypes"
+	"config-manager/client"
+)
+
+// runImport is `config-manager import`: it uploads a local directory (or a tar, tar.gz or zip archive) into a
+// namespace and prints the plan or the result.
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	server := fs.String("server", "http://localhost:8080", "base URL of the config-manager API")
+	namespace := fs.String("namespace", "", "namespace to import into (required)")
+	prefix := fs.String("prefix", "", "folder to import into (default: namespace root)")
+	author := fs.String("author", "", "created_by of the new versions")
+	comment := fs.String("comment", "", "comment of the new versions")
+	dryRun := fs.Bool("dry-run", false, "print the plan without writing")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: config-manager import -namespace NS [flags] DIR|ARCHIVE")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *namespace == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	src := fs.Arg(0)
+
+	c, err := client.New(*server, client.WithUserAgent("config-manager-import"))
+	if err != nil {
+		log.Fatalf("import: %v", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := client.ImportOptions{Prefix: *prefix, DryRun: *dryRun, CreatedBy: *author, Comment: *comment}
+	info, err := os.Stat(src)
+	if err != nil {
+		log.Fatalf("import: %v", err)
+	}
+	var resp *apitypes.ImportResponse
+	if info.IsDir() {
+		resp, err = c.ImportDirectory(ctx, *namespace, src, opts)
+	} else {
+		var f *os.File
+		if f, err = os.Open(src); err != nil {
+			log.Fatalf("import: %v", err)
+		}
+		defer f.Close()
+		resp, err = c.ImportNamespace(ctx, *namespace, f, opts)
+	}
+	if err != nil {
+		var e *client.Error
+		if errors.As(err, &e) && e.Details != nil {
+			log.Fatalf("import: %v %v", err, e.Details)
+		}
+		log.Fatalf("import: %v", err)
+	}
+	printImport(resp)
+}
+
+func printImport(resp *apitypes.ImportResponse) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, it := range resp.Items {
+		version := ""
+		switch {
+		case it.PreviousVersion != nil && it.Version != nil:
+			version = fmt.Sprintf("v%d -> v%d", *it.PreviousVersion, *it.Version)
+		case it.Version != nil:
+			version = fmt.Sprintf("v%d", *it.Version)
+		case it.Reason != "":
+			version = it.Reason
+		}
+		path := it.Path
+		if path == "" {
+			path = it.File
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", it.Action, path, version)
+	}
+	tw.Flush()
+	for _, w := range resp.Warnings {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
+
+	summary := fmt.Sprintf("%d created, %d updated, %d unchanged, %d skipped", resp.Created, resp.Updated, resp.Unchanged, resp.Skipped)
+	switch {
+	case resp.DryRun:
+		summary = "dry run: " + summary + " (nothing written)"
+	case resp.Changeset != nil:
+		summary += " (changeset " + resp.Changeset.ID + ")"
+	}
+	fmt.Println(summary)
+}
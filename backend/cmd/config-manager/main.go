@@ -26,6 +26,17 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "agent":
+			runAgent(os.Args[2:])
+			return
+		case "import":
+			runImport(os.Args[2:])
+			return
+		}
+	}
+
 	configPath := flag.String("config", "", "path to application.yaml (server, DB retry, timeouts); overridden by CONFIG_MANAGER_* env vars")
 	flag.Parse()
 
@@ -61,6 +72,21 @@ func main() {
 		log.Fatalf("migrate: %v", err)
 	}
 
+	schedulerInterval := time.Duration(config.Int("api.scheduler.pollIntervalSeconds", 5)) * time.Second
+	go httpapi.RunScheduler(ctx, pool, schedulerInterval)
+
+	eventRetention := time.Duration(config.Int("api.events.retentionHours", 168)) * time.Hour
+	go httpapi.RunEventRetention(ctx, pool, eventRetention)
+	go httpapi.RunChangeListener(ctx, pool, maxAttempts, initialBackoff)
+
+	webhookInterval := time.Duration(config.Int("api.webhooks.pollIntervalSeconds", 2)) * time.Second
+	go httpapi.RunWebhookDispatcher(ctx, pool, webhookInterval)
+
+	if mirror, ok := gitMirrorConfigFromEnv(); ok {
+		mirror.Interval = time.Duration(config.Int("api.gitMirror.pollIntervalSeconds", 30)) * time.Second
+		go httpapi.RunGitMirror(ctx, pool, mirror)
+	}
+
 	readHeaderTimeout := time.Duration(config.Int("api.server.readHeaderTimeoutSeconds", 5)) * time.Second
 	readTimeout := time.Duration(config.Int("api.server.readTimeoutSeconds", 30)) * time.Second
 	writeTimeout := time.Duration(config.Int("api.server.writeTimeoutSeconds", 30)) * time.Second
@@ -76,6 +102,8 @@ func main() {
 		IdleTimeout:       idleTimeout,
 		MaxHeaderBytes:    1 << 20, // 1 MiB
 	}
+	// Shutdown waits for handlers to return; event streams would otherwise hold it until shutdownTimeout.
+	srv.RegisterOnShutdown(httpapi.CloseEventStreams)
 
 	go func() {
 		log.Printf("config-manager listening on %s", srv.Addr)
@@ -156,3 +184,22 @@ func databaseURLFromEnv() (string, error) {
 	u.RawQuery = q.Encode()
 	return u.String(), nil
 }
+
+// gitMirrorConfigFromEnv reads the Git mirror settings; the mirror is disabled unless GIT_MIRROR_REPO is set.
+func gitMirrorConfigFromEnv() (httpapi.GitMirrorConfig, bool) {
+	repo := strings.TrimSpace(os.Getenv("GIT_MIRROR_REPO"))
+	if repo == "" {
+		return httpapi.GitMirrorConfig{}, false
+	}
+	cfg := httpapi.GitMirrorConfig{
+		Repo:   repo,
+		Remote: strings.TrimSpace(os.Getenv("GIT_MIRROR_REMOTE")),
+		Branch: strings.TrimSpace(getenvDefault("GIT_MIRROR_BRANCH", "main")),
+	}
+	for _, ns := range strings.Split(os.Getenv("GIT_MIRROR_NAMESPACES"), ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			cfg.Namespaces = append(cfg.Namespaces, ns)
+		}
+	}
+	return cfg, true
+}
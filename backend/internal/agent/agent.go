@@ -0,0 +1,285 @@
+// Package agent implements `config-manager agent`: it watches configs on a config-manager server and materializes
+// them as files for workloads that read their configuration from disk.
+//
+// Each file is written atomically (temp file + rename) after a last-known-good copy was stored in StateDir. When the
+// server cannot be reached, files are left as they are, and files missing at startup are restored from their
+// last-known-good copy, so a workload can always start with the last configuration the agent saw.
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"config-manager/apitypes"
+	"config-manager/client"
+)
+
+// pingInterval is how often the agent checks that the server is reachable (reported by /healthz).
+const pingInterval = 10 * time.Second
+
+type Agent struct {
+	cfg    *Config
+	client *client.Client
+	store  *store
+
+	mu        sync.Mutex
+	reachable bool
+	serverErr string
+	syncs     []*syncStatus
+}
+
+// syncStatus is the health of one selector.
+type syncStatus struct {
+	Namespace string `json:"namespace"`
+	Path      string `json:"path,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	// Ready is set once the selector's files were synced from the server or restored from their last-known-good copy.
+	Ready       bool       `json:"ready"`
+	Files       int        `json:"files"`
+	SyncedAt    *time.Time `json:"synced_at,omitempty"`
+	ReloadedAt  *time.Time `json:"reloaded_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	ReloadError string     `json:"reload_error,omitempty"`
+}
+
+func New(cfg *Config) (*Agent, error) {
+	c, err := client.New(cfg.Server, client.WithUserAgent("config-manager-agent"))
+	if err != nil {
+		return nil, err
+	}
+	st, err := openStore(cfg.TargetDir, cfg.StateDir, cfg.fileMode)
+	if err != nil {
+		return nil, err
+	}
+	a := &Agent{cfg: cfg, client: c, store: st}
+	for _, s := range cfg.Syncs {
+		ss := &syncStatus{Namespace: s.Namespace, Path: s.Path, Prefix: s.Prefix}
+		if s.folder && s.Prefix == "" {
+			ss.Prefix = "/"
+		}
+		a.syncs = append(a.syncs, ss)
+	}
+	return a, nil
+}
+
+// Run restores missing files from their last-known-good copies, then keeps every selector in sync until ctx is done.
+func (a *Agent) Run(ctx context.Context) error {
+	restored, err := a.store.restore()
+	for _, rel := range restored {
+		log.Printf("agent: restored %s from its last-known-good copy", rel)
+	}
+	if err != nil {
+		return err
+	}
+	for i, s := range a.cfg.Syncs {
+		if n := len(a.store.filesOf(s.key())); n > 0 {
+			a.update(i, func(st *syncStatus) { st.Ready, st.Files = true, n })
+		}
+	}
+
+	if a.cfg.HealthAddr != "" {
+		if err := a.serveHealth(ctx); err != nil {
+			return err
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		a.ping(ctx)
+	}()
+	for i := range a.cfg.Syncs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runSync(ctx, i)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// runSync watches selector i until ctx is done. When the watch ends with an error (the config does not exist, a file
+// could not be written, ...) it starts over after RetrySeconds, which also retries whatever failed.
+func (a *Agent) runSync(ctx context.Context, i int) {
+	s := a.cfg.Syncs[i]
+	wait := time.Duration(a.cfg.WaitSeconds) * time.Second
+	for {
+		var err error
+		if s.folder {
+			err = a.client.WatchFolder(ctx, s.Namespace, s.Prefix, wait, func(state *apitypes.FolderWatchResponse) error {
+				return a.syncFolder(ctx, i, state)
+			})
+		} else {
+			// AfterVersion 0: the current latest is applied first; an unchanged file is not rewritten.
+			err = a.client.WatchConfig(ctx, s.Namespace, s.Path, client.WatchOptions{Wait: wait}, func(resp *apitypes.GetConfigResponse) error {
+				changed, err := a.apply(i, resp)
+				a.synced(ctx, i, changed, err)
+				return err
+			})
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("agent: watch %s: %v (retrying in %ds)", s.key(), err, a.cfg.RetrySeconds)
+		a.update(i, func(st *syncStatus) { st.LastError = err.Error() })
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(a.cfg.RetrySeconds) * time.Second):
+		}
+	}
+}
+
+// apply writes the config in resp to its target file. It reports whether the file changed.
+func (a *Agent) apply(i int, resp *apitypes.GetConfigResponse) (bool, error) {
+	s := a.cfg.Syncs[i]
+	out := s.Format
+	if out == "" {
+		out = resp.Config.Format
+	}
+	data, err := render(resp.Config.Format, resp.Latest.BodyRaw, out)
+	if err != nil {
+		return false, fmt.Errorf("%s/%s version %d: %w", resp.Config.Namespace, resp.Config.Path, resp.Latest.Version, err)
+	}
+	rel := s.target(resp.Config.Path, out)
+	changed, err := a.store.put(rel, data, fileState{
+		Sync: s.key(), Namespace: resp.Config.Namespace, Path: resp.Config.Path, Version: resp.Latest.Version, SyncedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("write %s: %w", rel, err)
+	}
+	if changed {
+		log.Printf("agent: wrote %s (%s/%s version %d)", rel, resp.Config.Namespace, resp.Config.Path, resp.Latest.Version)
+	}
+	return changed, nil
+}
+
+// syncFolder brings the files of folder selector i in line with state: configs whose version changed are fetched
+// and written, and with Prune the files of configs that left the folder are deleted. Failures are returned after
+// the other configs were synced.
+func (a *Agent) syncFolder(ctx context.Context, i int, state *apitypes.FolderWatchResponse) error {
+	s := a.cfg.Syncs[i]
+	known := a.store.filesOf(s.key())
+	byPath := make(map[string]string, len(known)) // config path -> file
+	for rel, st := range known {
+		byPath[st.Path] = rel
+	}
+
+	changed := false
+	var errs []error
+	present := make(map[string]bool, len(state.Items))
+	for _, it := range state.Items {
+		present[it.Path] = true
+		if rel, ok := byPath[it.Path]; ok && known[rel].Version == it.Version {
+			continue
+		}
+		resp, err := a.client.GetConfig(ctx, s.Namespace, it.Path, client.GetOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("read %s/%s: %w", s.Namespace, it.Path, err))
+			continue
+		}
+		c, err := a.apply(i, resp)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		changed = changed || c
+	}
+	if s.Prune {
+		for p, rel := range byPath {
+			if present[p] {
+				continue
+			}
+			if err := a.store.remove(rel); err != nil {
+				errs = append(errs, fmt.Errorf("remove %s: %w", rel, err))
+				continue
+			}
+			log.Printf("agent: removed %s (%s/%s left the folder)", rel, s.Namespace, p)
+			changed = true
+		}
+	}
+	err := errors.Join(errs...)
+	a.synced(ctx, i, changed, err)
+	return err
+}
+
+// synced records the outcome of a sync of selector i and runs its reload if files changed (even if others failed).
+func (a *Agent) synced(ctx context.Context, i int, changed bool, err error) {
+	s := a.cfg.Syncs[i]
+	now := time.Now().UTC()
+	files := len(a.store.filesOf(s.key()))
+	a.update(i, func(st *syncStatus) {
+		st.Files = files
+		st.LastError = ""
+		if err != nil {
+			st.LastError = err.Error()
+			return
+		}
+		st.Ready = true
+		st.SyncedAt = &now
+	})
+	if !changed {
+		return
+	}
+
+	reloadErr := s.Reload.run(ctx)
+	if reloadErr != nil {
+		log.Printf("agent: %v", reloadErr)
+	}
+	a.update(i, func(st *syncStatus) {
+		st.ReloadError = ""
+		if reloadErr != nil {
+			st.ReloadError = reloadErr.Error()
+			return
+		}
+		if len(s.Reload.Command) > 0 || s.Reload.Signal != "" {
+			st.ReloadedAt = &now
+		}
+	})
+}
+
+// ping tracks whether the server is reachable until ctx is done, logging each change.
+func (a *Agent) ping(ctx context.Context) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, pingInterval)
+		err := a.client.Health(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		a.mu.Lock()
+		switch {
+		case err != nil && (a.reachable || a.serverErr == ""):
+			log.Printf("agent: server %s unreachable, keeping last-known-good files: %v", a.cfg.Server, err)
+		case err == nil && !a.reachable:
+			log.Printf("agent: server %s reachable", a.cfg.Server)
+		}
+		a.reachable = err == nil
+		a.serverErr = ""
+		if err != nil {
+			a.serverErr = err.Error()
+		}
+		a.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Agent) update(i int, fn func(*syncStatus)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	fn(a.syncs[i])
+}
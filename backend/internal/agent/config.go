@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"config-manager/apitypes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the agent's YAML configuration file.
+type Config struct {
+	// Server is the base URL of the config-manager API, including any HTTP_BASE_PATH.
+	Server string `yaml:"server"`
+	// TargetDir is where configs are written.
+	TargetDir string `yaml:"targetDir"`
+	// StateDir keeps the last-known-good copies and the sync state (default <targetDir>/.config-manager-agent). Put
+	// it on persistent storage to restore files on a restart while the server is unreachable.
+	StateDir string `yaml:"stateDir"`
+	// HealthAddr is the listen address of the agent's /healthz and /readyz (e.g. ":8081"); "" disables them.
+	HealthAddr string `yaml:"healthAddr"`
+	// WaitSeconds is the long-poll wait per watch request (default 30, at most 300).
+	WaitSeconds int `yaml:"waitSeconds"`
+	// RetrySeconds is the delay before a failed selector (e.g. a config that does not exist yet) is watched again
+	// (default 30).
+	RetrySeconds int `yaml:"retrySeconds"`
+	// FileMode is the octal permission of written files (default 0644).
+	FileMode string `yaml:"fileMode"`
+	Syncs    []Sync `yaml:"syncs"`
+
+	fileMode os.FileMode
+}
+
+// Sync selects one config (Path) or every config under a folder (Prefix; "/" selects the whole namespace) of
+// Namespace.
+type Sync struct {
+	Namespace string `yaml:"namespace"`
+	Path      string `yaml:"path"`
+	Prefix    string `yaml:"prefix"`
+	// File is the target, relative to TargetDir: the file of a Path selector (default: the config path with the
+	// extension of the output format) or the directory of a Prefix selector (default: the prefix).
+	File string `yaml:"file"`
+	// Format converts bodies to json or yaml; "" writes each config in its own format.
+	Format apitypes.ConfigFormat `yaml:"format"`
+	// Prune deletes the files of configs that left the folder (Prefix selectors only); by default they are kept.
+	Prune  bool   `yaml:"prune"`
+	Reload Reload `yaml:"reload"`
+
+	folder bool
+}
+
+// Reload is run after a selector's files changed: Command is executed, and Signal is sent to the process whose pid
+// is in PIDFile. Both are optional.
+type Reload struct {
+	Command []string `yaml:"command"`
+	// Signal is HUP, INT, TERM, USR1 or USR2.
+	Signal         string `yaml:"signal"`
+	PIDFile        string `yaml:"pidFile"`
+	TimeoutSeconds int    `yaml:"timeoutSeconds"`
+}
+
+// LoadConfig reads and validates the agent configuration at path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func (c *Config) validate() error {
+	if strings.TrimSpace(c.Server) == "" {
+		return errors.New("server is required")
+	}
+	if strings.TrimSpace(c.TargetDir) == "" {
+		return errors.New("targetDir is required")
+	}
+	c.TargetDir = filepath.Clean(c.TargetDir)
+	if c.StateDir == "" {
+		c.StateDir = filepath.Join(c.TargetDir, ".config-manager-agent")
+	}
+	c.StateDir = filepath.Clean(c.StateDir)
+	if c.WaitSeconds <= 0 {
+		c.WaitSeconds = 30
+	}
+	if c.WaitSeconds > 300 {
+		return errors.New("waitSeconds must be at most 300")
+	}
+	if c.RetrySeconds <= 0 {
+		c.RetrySeconds = 30
+	}
+	c.fileMode = 0o644
+	if c.FileMode != "" {
+		m, err := strconv.ParseUint(c.FileMode, 8, 32)
+		if err != nil || m > 0o777 {
+			return fmt.Errorf("fileMode must be an octal permission such as 0644")
+		}
+		c.fileMode = os.FileMode(m)
+	}
+	if len(c.Syncs) == 0 {
+		return errors.New("syncs must not be empty")
+	}
+
+	targets := map[string]int{}
+	for i := range c.Syncs {
+		s := &c.Syncs[i]
+		if err := s.validate(); err != nil {
+			return fmt.Errorf("syncs[%d]: %w", i, err)
+		}
+		if j, ok := targets[s.key()]; ok {
+			return fmt.Errorf("syncs[%d]: selects the same configs as syncs[%d]", i, j)
+		}
+		targets[s.key()] = i
+	}
+	return nil
+}
+
+func (s *Sync) validate() error {
+	s.Namespace = strings.TrimSpace(s.Namespace)
+	if s.Namespace == "" {
+		return errors.New("namespace is required")
+	}
+	s.Path = strings.Trim(strings.TrimSpace(s.Path), "/")
+	s.Prefix = strings.TrimSpace(s.Prefix)
+	if (s.Path == "") == (s.Prefix == "") {
+		return errors.New("exactly one of path and prefix is required")
+	}
+	s.folder = s.Prefix != ""
+	if s.Prefix = strings.Trim(s.Prefix, "/"); s.Prefix != "" {
+		s.Prefix += "/"
+	}
+	if s.Format != "" && s.Format != apitypes.FormatJSON && s.Format != apitypes.FormatYAML {
+		return errors.New("format must be json or yaml")
+	}
+	if s.Prune && !s.folder {
+		return errors.New("prune only applies to prefix selectors")
+	}
+
+	if s.File == "" && s.folder {
+		s.File = strings.TrimSuffix(s.Prefix, "/")
+	}
+	if s.File != "" {
+		s.File = path.Clean(filepath.ToSlash(strings.TrimSpace(s.File)))
+		if path.IsAbs(s.File) || s.File == ".." || strings.HasPrefix(s.File, "../") || (s.File == "." && !s.folder) {
+			return errors.New("file must be a path inside targetDir")
+		}
+	}
+
+	if s.Reload.Signal != "" {
+		if _, ok := signals[strings.TrimPrefix(strings.ToUpper(s.Reload.Signal), "SIG")]; !ok {
+			return fmt.Errorf("reload.signal %q is not supported", s.Reload.Signal)
+		}
+		if s.Reload.PIDFile == "" {
+			return errors.New("reload.signal requires reload.pidFile")
+		}
+	}
+	if s.Reload.TimeoutSeconds <= 0 {
+		s.Reload.TimeoutSeconds = 30
+	}
+	return nil
+}
+
+// key identifies the selector in the sync state.
+func (s Sync) key() string {
+	if s.folder {
+		return s.Namespace + ":" + s.Prefix + "*"
+	}
+	return s.Namespace + ":" + s.Path
+}
+
+// target returns the file, relative to TargetDir, that config path is written to in format.
+func (s Sync) target(p string, format apitypes.ConfigFormat) string {
+	if !s.folder {
+		if s.File != "" {
+			return s.File
+		}
+		return fileName(p, format)
+	}
+	return path.Join(s.File, fileName(strings.TrimPrefix(p, s.Prefix), format))
+}
+
+// fileName is the file name of config path p written in format: any .json/.yaml/.yml extension is replaced by the
+// format's.
+func fileName(p string, format apitypes.ConfigFormat) string {
+	switch path.Ext(p) {
+	case ".json", ".yaml", ".yml":
+		p = strings.TrimSuffix(p, path.Ext(p))
+	}
+	return p + "." + string(format)
+}
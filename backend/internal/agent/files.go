@@ -0,0 +1,209 @@
+package agent
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"config-manager/apitypes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// render returns bodyRaw (a config in format) as the content of a file in out.
+func render(format apitypes.ConfigFormat, bodyRaw string, out apitypes.ConfigFormat) ([]byte, error) {
+	if out == format {
+		return []byte(bodyRaw), nil
+	}
+	// JSON is a subset of YAML, so yaml.v3 parses both and keeps integers exact.
+	var v any
+	if err := yaml.Unmarshal([]byte(bodyRaw), &v); err != nil {
+		return nil, fmt.Errorf("parse %s body: %w", format, err)
+	}
+	switch out {
+	case apitypes.FormatJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("convert to json: %w", err)
+		}
+		return append(b, '\n'), nil
+	case apitypes.FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return nil, fmt.Errorf("convert to yaml: %w", err)
+		}
+		return buf.Bytes(), enc.Close()
+	default:
+		return nil, fmt.Errorf("unsupported format %q", out)
+	}
+}
+
+// writeFileAtomic replaces name with data so readers see either the old or the new content, never a partial file.
+func writeFileAtomic(name string, data []byte, mode os.FileMode) error {
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return err
+	}
+	// Persist the rename itself; not every platform can sync a directory.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// fileState is what the agent last wrote to a target file.
+type fileState struct {
+	Sync      string    `json:"sync"` // Sync.key()
+	Namespace string    `json:"namespace"`
+	Path      string    `json:"path"`
+	Version   int       `json:"version"`
+	SHA256    string    `json:"sha256"`
+	SyncedAt  time.Time `json:"synced_at"`
+}
+
+// store keeps the last-known-good copy of every target file under <StateDir>/lkg and the sync state in
+// <StateDir>/state.json, keyed by the file's path relative to TargetDir.
+type store struct {
+	mu        sync.Mutex
+	targetDir string
+	stateDir  string
+	mode      os.FileMode
+	files     map[string]fileState
+}
+
+func openStore(targetDir, stateDir string, mode os.FileMode) (*store, error) {
+	s := &store{targetDir: targetDir, stateDir: stateDir, mode: mode, files: map[string]fileState{}}
+	data, err := os.ReadFile(filepath.Join(stateDir, "state.json"))
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.files); err != nil {
+		return nil, fmt.Errorf("read sync state: %w", err)
+	}
+	return s, nil
+}
+
+// filesOf returns the target files written for the selector with key syncKey.
+func (s *store) filesOf(syncKey string) map[string]fileState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := map[string]fileState{}
+	for rel, st := range s.files {
+		if st.Sync == syncKey {
+			out[rel] = st
+		}
+	}
+	return out
+}
+
+// put writes data to target file rel, last-known-good copy first. It reports false (and writes nothing) when the
+// file already has exactly this content.
+func (s *store) put(rel string, data []byte, st fileState) (bool, error) {
+	st.SHA256 = sha256Hex(data)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prev, ok := s.files[rel]; ok && prev.SHA256 == st.SHA256 && s.targetMatches(rel, st.SHA256) {
+		if prev.Version != st.Version {
+			s.files[rel] = st
+			return false, s.save()
+		}
+		return false, nil
+	}
+	if err := writeFileAtomic(filepath.Join(s.stateDir, "lkg", filepath.FromSlash(rel)), data, s.mode); err != nil {
+		return false, fmt.Errorf("write last-known-good copy: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(s.targetDir, filepath.FromSlash(rel)), data, s.mode); err != nil {
+		return false, err
+	}
+	s.files[rel] = st
+	return true, s.save()
+}
+
+// remove deletes target file rel and its last-known-good copy.
+func (s *store) remove(rel string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range []string{filepath.Join(s.targetDir, filepath.FromSlash(rel)), filepath.Join(s.stateDir, "lkg", filepath.FromSlash(rel))} {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	delete(s.files, rel)
+	return s.save()
+}
+
+// restore copies the last-known-good copy of every known file whose target is missing or was changed locally back
+// into place. It returns the restored files.
+func (s *store) restore() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var restored []string
+	for rel, st := range s.files {
+		if s.targetMatches(rel, st.SHA256) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.stateDir, "lkg", filepath.FromSlash(rel)))
+		if err != nil {
+			return restored, fmt.Errorf("read last-known-good copy of %s: %w", rel, err)
+		}
+		if err := writeFileAtomic(filepath.Join(s.targetDir, filepath.FromSlash(rel)), data, s.mode); err != nil {
+			return restored, err
+		}
+		restored = append(restored, rel)
+	}
+	return restored, nil
+}
+
+// targetMatches reports whether target file rel exists with the given content hash. Callers hold mu.
+func (s *store) targetMatches(rel, sum string) bool {
+	data, err := os.ReadFile(filepath.Join(s.targetDir, filepath.FromSlash(rel)))
+	return err == nil && sha256Hex(data) == sum
+}
+
+// save writes state.json. Callers hold mu.
+func (s *store) save() error {
+	data, err := json.MarshalIndent(s.files, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.stateDir, "state.json"), data, 0o600)
+}
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// healthResponse is the body of the agent's /healthz and /readyz.
+type healthResponse struct {
+	OK              bool         `json:"ok"`
+	Server          string       `json:"server"`
+	ServerReachable bool         `json:"server_reachable"`
+	ServerError     string       `json:"server_error,omitempty"`
+	Syncs           []syncStatus `json:"syncs"`
+}
+
+func (a *Agent) health() healthResponse {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	h := healthResponse{OK: true, Server: a.cfg.Server, ServerReachable: a.reachable, ServerError: a.serverErr}
+	for _, st := range a.syncs {
+		h.Syncs = append(h.Syncs, *st)
+		if !st.Ready {
+			h.OK = false
+		}
+	}
+	return h
+}
+
+// serveHealth listens on HealthAddr until ctx is done. /healthz always answers 200 with the sync status; /readyz
+// answers 503 until every selector's files are in place (synced, or restored while the server is unreachable).
+func (a *Agent) serveHealth(ctx context.Context) error {
+	ln, err := net.Listen("tcp", a.cfg.HealthAddr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeHealth(w, http.StatusOK, a.health())
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		h := a.health()
+		status := http.StatusOK
+		if !h.OK {
+			status = http.StatusServiceUnavailable
+		}
+		writeHealth(w, status, h)
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		log.Printf("agent: health endpoint listening on %s", ln.Addr())
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("agent: health endpoint: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	return nil
+}
+
+func writeHealth(w http.ResponseWriter, status int, h healthResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(h); err != nil {
+		log.Printf("agent: write health response: %v", err)
+	}
+}
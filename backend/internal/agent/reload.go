@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// run executes the reload: the command first (bounded by TimeoutSeconds), then the signal.
+func (r Reload) run(ctx context.Context) error {
+	if len(r.Command) > 0 {
+		ctx, cancel := context.WithTimeout(ctx, time.Duration(r.TimeoutSeconds)*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, r.Command[0], r.Command[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("reload command %q: %v: %s", strings.Join(r.Command, " "), err, clip(strings.TrimSpace(string(out)), 500))
+		}
+	}
+	if r.Signal != "" {
+		raw, err := os.ReadFile(r.PIDFile)
+		if err != nil {
+			return fmt.Errorf("reload signal: %w", err)
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+		if err != nil || pid <= 0 {
+			return fmt.Errorf("reload signal: %s does not hold a pid", r.PIDFile)
+		}
+		p, err := os.FindProcess(pid)
+		if err != nil {
+			return fmt.Errorf("reload signal: %w", err)
+		}
+		if err := p.Signal(signals[strings.TrimPrefix(strings.ToUpper(r.Signal), "SIG")]); err != nil {
+			return fmt.Errorf("reload signal %s to pid %d: %w", r.Signal, pid, err)
+		}
+	}
+	return nil
+}
+
+func clip(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
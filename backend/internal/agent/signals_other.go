@@ -0,0 +1,10 @@
+//go:build !unix
+
+package agent
+
+import "os"
+
+// signals are the reload signals accepted in Reload.Signal; only the interrupt can be sent here.
+var signals = map[string]os.Signal{
+	"INT": os.Interrupt,
+}
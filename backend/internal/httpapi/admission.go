@@ -0,0 +1,237 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	admissionFailClosed = "fail_closed"
+	admissionFailOpen   = "fail_open"
+
+	admissionOpCreate = "create"
+	admissionOpUpdate = "update"
+
+	minAdmissionTimeoutMS     = 100
+	maxAdmissionTimeoutMS     = 30000
+	defaultAdmissionTimeoutMS = 5000
+
+	// admissionDeadlineMargin is kept free before the request deadline so the write can still commit (or report a
+	// denial) after its hooks ran out of time.
+	admissionDeadlineMargin = 2 * time.Second
+
+	// maxAdmissionResponseBytes bounds the verdict read from a hook.
+	maxAdmissionResponseBytes = 1 << 20
+	// maxAdmissionMessages bounds the messages (and warnings) kept per hook; each is cut to maxAdmissionMessageLen.
+	maxAdmissionMessages   = 20
+	maxAdmissionMessageLen = 1000
+)
+
+// admissionObject is a config body in an admission review.
+type admissionObject struct {
+	Version  int             `json:"version"`
+	BodyRaw  string          `json:"body_raw"`
+	BodyJSON json.RawMessage `json:"body_json,omitempty"`
+}
+
+// admissionReview is the request body POSTed to admission webhooks. Old is null for creates.
+type admissionReview struct {
+	RequestID *string          `json:"request_id,omitempty"`
+	Operation string           `json:"operation"`
+	Namespace string           `json:"namespace"`
+	Path      string           `json:"path"`
+	Format    ConfigFormat     `json:"format"`
+	Old       *admissionObject `json:"old"`
+	New       admissionObject  `json:"new"`
+	Author    *string          `json:"author,omitempty"`
+	Comment   *string          `json:"comment,omitempty"`
+
+	// configID and oldVersion locate Old for updates; admit only loads it when a hook matches.
+	configID   pgtype.UUID
+	oldVersion int
+	// userAgent and sourceIP are recorded with denials (with RequestID).
+	userAgent *string
+	sourceIP  net.IP
+}
+
+// withRequest sets the audit fields of the review from req.
+func (r admissionReview) withRequest(req *http.Request) admissionReview {
+	r.RequestID, r.userAgent, r.sourceIP = requestAuditFields(req)
+	return r
+}
+
+// admissionVerdict is a hook's answer; allowed is required.
+type admissionVerdict struct {
+	Allowed  *bool    `json:"allowed"`
+	Messages []string `json:"messages"`
+	Warnings []string `json:"warnings"`
+}
+
+// admissionHook is an active admission webhook matching a write.
+type admissionHook struct {
+	ID            pgtype.UUID
+	Name          string
+	URL           string
+	Timeout       time.Duration
+	FailurePolicy string
+}
+
+// admissionDenialReason is one hook's reason to reject a write (details.denials of 422 admission_denied).
+type admissionDenialReason struct {
+	WebhookID string   `json:"webhook_id"`
+	Webhook   string   `json:"webhook"`
+	Messages  []string `json:"messages"`
+	Failed    bool     `json:"failed,omitempty"`
+}
+
+// admit calls the active admission webhooks of the review's namespace whose prefix covers its path, concurrently,
+// within the caller's transaction q (before commit). It returns the warnings to pass on to the client, or a 422
+// *httpError (code admission_denied) if a hook denied the write or a fail_closed hook could not be called; fail_open
+// hooks that cannot be called only add a warning. Denials are recorded through db because q is rolled back.
+//
+// Every write path that creates a version calls admit. A hook's timeout is cut to what is left of ctx's deadline
+// (minus admissionDeadlineMargin), so a hook outliving the request counts as a failed hook under its failure policy.
+func admit(ctx context.Context, db *pgxpool.Pool, q querier, review admissionReview) ([]string, error) {
+	hooks, err := storeMatchingAdmissionWebhooks(ctx, q, review.Namespace, review.Path)
+	if err != nil || len(hooks) == 0 {
+		return nil, err
+	}
+	if review.Operation == admissionOpUpdate && review.Old == nil {
+		if review.Old, err = storeAdmissionObject(ctx, q, review.configID, review.oldVersion); err != nil {
+			return nil, err
+		}
+	}
+	payload, err := json.Marshal(review)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	verdicts := make([]admissionVerdict, len(hooks))
+	errs := make([]error, len(hooks))
+	var wg sync.WaitGroup
+	for i, h := range hooks {
+		timeout := h.Timeout
+		if hasDeadline {
+			timeout = min(timeout, time.Until(deadline)-admissionDeadlineMargin)
+		}
+		if timeout <= 0 {
+			errs[i] = errors.New("no time left before the request deadline")
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			verdicts[i], errs[i] = callAdmissionHook(ctx, h, timeout, payload)
+		}()
+	}
+	wg.Wait()
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, ctx.Err()
+	}
+
+	var warnings []string
+	var denials []admissionDenialReason
+	for i, h := range hooks {
+		reason := admissionDenialReason{WebhookID: uuidToString(h.ID), Webhook: h.Name}
+		switch {
+		case errs[i] != nil && h.FailurePolicy == admissionFailOpen:
+			warnings = append(warnings, fmt.Sprintf("%s: admission webhook unavailable, write allowed (fail_open): %v", h.Name, errs[i]))
+			continue
+		case errs[i] != nil:
+			reason.Failed = true
+			reason.Messages = []string{clipAdmissionMessage(fmt.Sprintf("admission webhook unavailable (fail_closed): %v", errs[i]))}
+		case !*verdicts[i].Allowed:
+			reason.Messages = clipAdmissionMessages(verdicts[i].Messages)
+			if len(reason.Messages) == 0 {
+				reason.Messages = []string{"denied by admission webhook " + h.Name}
+			}
+		}
+		for _, msg := range clipAdmissionMessages(verdicts[i].Warnings) {
+			warnings = append(warnings, h.Name+": "+msg)
+		}
+		if reason.Messages != nil {
+			denials = append(denials, reason)
+		}
+	}
+	if len(denials) == 0 {
+		return warnings, nil
+	}
+
+	if err := storeRecordAdmissionDenials(ctx, db, review, denials); err != nil {
+		log.Printf("admission: record denial of %s/%s: %v", review.Namespace, review.Path, err)
+	}
+	details := map[string]any{"denials": denials}
+	if len(warnings) > 0 {
+		details["warnings"] = warnings
+	}
+	return nil, &httpError{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "admission_denied",
+		Message: "denied by admission webhook " + denials[0].Webhook + ": " + denials[0].Messages[0],
+		Details: details,
+	}
+}
+
+// callAdmissionHook POSTs the review to one hook and decodes its verdict. Transport errors, non-2xx answers
+// (redirects included), timeouts and verdicts without allowed are errors.
+func callAdmissionHook(ctx context.Context, h admissionHook, timeout time.Duration, payload []byte) (admissionVerdict, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(payload))
+	if err != nil {
+		return admissionVerdict{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "config-manager-admission")
+
+	resp, err := webhookClient.Do(httpReq)
+	if err != nil {
+		return admissionVerdict{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return admissionVerdict{}, errors.New("unexpected status " + resp.Status)
+	}
+	var v admissionVerdict
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAdmissionResponseBytes)).Decode(&v); err != nil {
+		return admissionVerdict{}, fmt.Errorf("invalid response: %v", err)
+	}
+	if v.Allowed == nil {
+		return admissionVerdict{}, errors.New("invalid response: allowed is required")
+	}
+	return v, nil
+}
+
+func clipAdmissionMessages(msgs []string) []string {
+	var out []string
+	for _, m := range msgs {
+		if len(out) == maxAdmissionMessages {
+			break
+		}
+		if m != "" {
+			out = append(out, clipAdmissionMessage(m))
+		}
+	}
+	return out
+}
+
+func clipAdmissionMessage(m string) string {
+	if len(m) > maxAdmissionMessageLen {
+		return m[:maxAdmissionMessageLen]
+	}
+	return m
+}
@@ -0,0 +1,251 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxBlameDiffCells bounds the LCS table used for line blame (4 bytes per cell). Larger hunks are attributed to the
+// newer version as a whole.
+const maxBlameDiffCells = 1_000_000
+
+// blameSource is one version's contribution to a blame walk.
+type blameSource struct {
+	meta     BlameVersion
+	bodyRaw  string
+	bodyJSON []byte
+}
+
+// blameWalk attributes the leaves and lines of the latest version while stepping back through older versions, one
+// at a time, so only two bodies are held at once. A key or line stays pending while the older version still has it
+// unchanged; once it differs, the newer version is the one that last changed it.
+type blameWalk struct {
+	newer     blameSource
+	newerKeys map[string]string // pointer -> canonical value in newer
+	newerRaw  []string
+
+	latestValues map[string]any
+	keyOwners    map[string]BlameVersion
+	pendingKeys  map[string]bool
+
+	lines        []string
+	lineOwners   []BlameVersion
+	pendingLines map[int]int // line index in newer -> line index in latest
+}
+
+func newBlameWalk(latest blameSource, withLines bool) *blameWalk {
+	b := &blameWalk{
+		newer:        latest,
+		latestValues: map[string]any{},
+		keyOwners:    map[string]BlameVersion{},
+		pendingKeys:  map[string]bool{},
+	}
+	if root, ok := decodeJSONNumbers(latest.bodyJSON); ok {
+		flattenJSONLeaves("", root, b.latestValues)
+	}
+	b.newerKeys = make(map[string]string, len(b.latestValues))
+	for k, v := range b.latestValues {
+		b.newerKeys[k] = canonicalJSON(v)
+		b.pendingKeys[k] = true
+	}
+	if withLines {
+		b.lines = splitLines(latest.bodyRaw)
+		b.newerRaw = b.lines
+		b.lineOwners = make([]BlameVersion, len(b.lines))
+		b.pendingLines = make(map[int]int, len(b.lines))
+		for i := range b.lines {
+			b.pendingLines[i] = i
+		}
+	}
+	return b
+}
+
+// done reports whether every key (and line) of the latest version is attributed.
+func (b *blameWalk) done() bool {
+	return len(b.pendingKeys) == 0 && len(b.pendingLines) == 0
+}
+
+// step compares the next older version with the newer one and attributes what changed between them to the newer one.
+func (b *blameWalk) step(older blameSource) {
+	olderLeaves := map[string]any{}
+	if root, ok := decodeJSONNumbers(older.bodyJSON); ok {
+		flattenJSONLeaves("", root, olderLeaves)
+	}
+	olderKeys := make(map[string]string, len(olderLeaves))
+	for k, v := range olderLeaves {
+		olderKeys[k] = canonicalJSON(v)
+	}
+	for k := range b.pendingKeys {
+		if prev, ok := olderKeys[k]; !ok || prev != b.newerKeys[k] {
+			b.keyOwners[k] = b.newer.meta
+			delete(b.pendingKeys, k)
+		}
+	}
+
+	var olderRaw []string
+	if b.pendingLines != nil {
+		olderRaw = splitLines(older.bodyRaw)
+		next := make(map[int]int, len(b.pendingLines))
+		for _, m := range matchLines(olderRaw, b.newerRaw) {
+			if latestIdx, ok := b.pendingLines[m[1]]; ok {
+				next[m[0]] = latestIdx
+				delete(b.pendingLines, m[1])
+			}
+		}
+		for _, latestIdx := range b.pendingLines {
+			b.lineOwners[latestIdx] = b.newer.meta
+		}
+		b.pendingLines = next
+	}
+
+	b.newer, b.newerKeys, b.newerRaw = older, olderKeys, olderRaw
+}
+
+// finish attributes whatever is still pending to the oldest version walked (the first version of the config).
+func (b *blameWalk) finish() {
+	for k := range b.pendingKeys {
+		b.keyOwners[k] = b.newer.meta
+	}
+	b.pendingKeys = nil
+	for _, latestIdx := range b.pendingLines {
+		b.lineOwners[latestIdx] = b.newer.meta
+	}
+	b.pendingLines = nil
+}
+
+func (b *blameWalk) keys() []BlameKey {
+	keys := make([]string, 0, len(b.latestValues))
+	for k := range b.latestValues {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]BlameKey, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, BlameKey{Key: k, Value: b.latestValues[k], BlameVersion: b.keyOwners[k]})
+	}
+	return out
+}
+
+func (b *blameWalk) blameLines() []BlameLine {
+	out := make([]BlameLine, 0, len(b.lines))
+	for i, text := range b.lines {
+		out = append(out, BlameLine{Line: i + 1, Text: text, BlameVersion: b.lineOwners[i]})
+	}
+	return out
+}
+
+// matchLines returns index pairs (a, b) of lines kept unchanged between a and b (longest common subsequence).
+func matchLines(a, b []string) [][2]int {
+	var pairs [][2]int
+
+	// Trim common prefix and suffix; most edits touch a small region.
+	start := 0
+	for start < len(a) && start < len(b) && a[start] == b[start] {
+		pairs = append(pairs, [2]int{start, start})
+		start++
+	}
+	endA, endB := len(a), len(b)
+	var suffix [][2]int
+	for endA > start && endB > start && a[endA-1] == b[endB-1] {
+		endA--
+		endB--
+		suffix = append(suffix, [2]int{endA, endB})
+	}
+
+	midA, midB := a[start:endA], b[start:endB]
+	if len(midA) > 0 && len(midB) > 0 && len(midA)*len(midB) <= maxBlameDiffCells {
+		n, m := len(midA), len(midB)
+		// lcs[i][j] = LCS length of midA[i:] and midB[j:].
+		lcs := make([][]int32, n+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+		for i, j := 0, 0; i < n && j < m; {
+			switch {
+			case midA[i] == midB[j]:
+				pairs = append(pairs, [2]int{start + i, start + j})
+				i++
+				j++
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				i++
+			default:
+				j++
+			}
+		}
+	}
+
+	for i := len(suffix) - 1; i >= 0; i-- {
+		pairs = append(pairs, suffix[i])
+	}
+	return pairs
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.TrimSuffix(s, "\n")
+	return strings.Split(s, "\n")
+}
+
+func decodeJSONNumbers(raw []byte) (any, bool) {
+	if len(raw) == 0 {
+		return nil, false
+	}
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// flattenJSONLeaves maps JSON Pointers (RFC 6901) to leaf values. Scalars and empty containers are leaves.
+func flattenJSONLeaves(pointer string, v any, out map[string]any) {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 0 {
+			out[pointer] = t
+			return
+		}
+		for k, child := range t {
+			flattenJSONLeaves(pointer+"/"+escapeJSONPointer(k), child, out)
+		}
+	case []any:
+		if len(t) == 0 {
+			out[pointer] = t
+			return
+		}
+		for i, child := range t {
+			flattenJSONLeaves(pointer+"/"+strconv.Itoa(i), child, out)
+		}
+	default:
+		out[pointer] = t
+	}
+}
+
+func escapeJSONPointer(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
+
+func canonicalJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	bulkDeleteScopeFolder    = "folder"
+	bulkDeleteScopeNamespace = "namespace"
+)
+
+// planBulkDelete lists the active configs of namespace under prefix ("" = all) with their version counts.
+// The confirm token is derived from the plan, so it stops matching as soon as a config is added, removed or updated.
+func planBulkDelete(ctx context.Context, q querier, scope, namespace, prefix string) (BulkDeleteResponse, []pgtype.UUID, error) {
+	rows, err := q.Query(ctx, `
+		SELECT c.id, c.path, COALESCE(MAX(v.version), 0), COUNT(v.id)
+		FROM configs c
+		LEFT JOIN config_versions v ON v.config_id = c.id
+		WHERE c.namespace = $1
+		  AND c.deleted_at IS NULL
+		  AND ($2 = '' OR left(c.path, length($2::text)) = $2)
+		GROUP BY c.id, c.path
+		ORDER BY c.path ASC
+	`, namespace, prefix)
+	if err != nil {
+		return BulkDeleteResponse{}, nil, err
+	}
+	defer rows.Close()
+
+	plan := BulkDeleteResponse{Scope: scope, Namespace: namespace, Prefix: prefix, DryRun: true, Items: []BulkDeleteItem{}}
+	var ids []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		var it BulkDeleteItem
+		if err := rows.Scan(&id, &it.Path, &it.LatestVersion, &it.Versions); err != nil {
+			return BulkDeleteResponse{}, nil, err
+		}
+		it.ConfigID = uuidToString(id)
+		plan.Items = append(plan.Items, it)
+		plan.VersionCount += it.Versions
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return BulkDeleteResponse{}, nil, err
+	}
+	plan.ConfigCount = len(plan.Items)
+	plan.ConfirmToken = bulkDeleteToken(plan)
+	return plan, ids, nil
+}
+
+func bulkDeleteToken(plan BulkDeleteResponse) string {
+	var b strings.Builder
+	b.WriteString(plan.Scope + "\x00" + plan.Namespace + "\x00" + plan.Prefix + "\x00")
+	for _, it := range plan.Items {
+		b.WriteString(it.ConfigID + ":" + strconv.Itoa(it.LatestVersion) + "\n")
+	}
+	return sha256Hex(b.String())[:32]
+}
@@ -0,0 +1,484 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	changeActionCreate = "create"
+	changeActionUpdate = "update"
+	changeActionDelete = "delete"
+	// changeActionMove is not a changeset action; it names moves in checkDirectWrite.
+	changeActionMove = "move"
+
+	maxChangesetItems = 100
+)
+
+// changesetItemInput is one create/update/delete of a changeset.
+type changesetItemInput struct {
+	Action      string
+	Namespace   string
+	Path        string
+	Format      ConfigFormat
+	BodyRaw     string
+	BaseVersion *int
+
+	// expectConfigID pins update/delete to a specific config row (used by reverts, so a path that was
+	// deleted and recreated since is not touched by mistake). On a create it names a deleted row to restore
+	// (with its latest version, pinned by BaseVersion) instead of inserting a new config; Format and BodyRaw are
+	// unused then.
+	expectConfigID pgtype.UUID
+}
+
+// changesetInput describes a changeset to apply.
+type changesetInput struct {
+	CreatedBy *string
+	Comment   *string
+	RevertsID pgtype.UUID
+	RequestID *string
+	UserAgent *string
+	SourceIP  net.IP
+	Items     []changesetItemInput
+	// LockOverride applies the items even where a config lock is live.
+	LockOverride bool
+	// DryRun skips the admission webhooks: the caller rolls the transaction back, so nothing may leave it
+	// (no hook requests, no recorded denials).
+	DryRun bool
+}
+
+func changesetItemsFromRequest(items []ChangesetItemRequest) []changesetItemInput {
+	out := make([]changesetItemInput, len(items))
+	for i, it := range items {
+		out[i] = changesetItemInput{Action: it.Action, Namespace: it.Namespace, Path: it.Path, Format: it.Format, BodyRaw: it.BodyRaw, BaseVersion: it.BaseVersion}
+	}
+	return out
+}
+
+// normalizeChangesetItems validates items (shape only; existence and versions are checked when applying).
+func normalizeChangesetItems(items []changesetItemInput) error {
+	if len(items) == 0 {
+		return &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: "items must not be empty", Details: map[string]any{"field": "items"}}
+	}
+	if len(items) > maxChangesetItems {
+		return &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: fmt.Sprintf("a changeset can have at most %d items", maxChangesetItems), Details: map[string]any{"field": "items"}}
+	}
+
+	seen := make(map[string]int, len(items))
+	for i := range items {
+		it := &items[i]
+		bad := func(msg string) error {
+			return &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: msg, Details: map[string]any{"item": i}}
+		}
+
+		it.Namespace = strings.TrimSpace(it.Namespace)
+		if err := validateNamespace(it.Namespace); err != nil {
+			return bad(err.Error())
+		}
+		path, err := normalizeConfigPath(it.Path)
+		if err != nil {
+			return bad(err.Error())
+		}
+		it.Path = path
+
+		key := it.Namespace + "/" + it.Path
+		if prev, dup := seen[key]; dup {
+			return bad(fmt.Sprintf("%s is already changed by item %d", key, prev))
+		}
+		seen[key] = i
+
+		switch it.Action {
+		case changeActionCreate:
+			if it.Format != FormatJSON && it.Format != FormatYAML {
+				return bad("format must be one of: json, yaml")
+			}
+			if it.BaseVersion != nil {
+				return bad("base_version is not allowed for create")
+			}
+			if it.BodyRaw == "" {
+				return bad("body_raw is required")
+			}
+		case changeActionUpdate:
+			if it.BodyRaw == "" {
+				return bad("body_raw is required")
+			}
+		case changeActionDelete:
+			if it.BodyRaw != "" {
+				return bad("body_raw is not allowed for delete")
+			}
+		default:
+			return bad("action must be one of: create, update, delete")
+		}
+	}
+	return nil
+}
+
+// applyChangeset applies all items in tx and records the changeset. Client errors are returned as *httpError
+// with the failing item index in details; nothing is written unless every item succeeds. Creates and updates go
+// through the admission webhooks (admit, denials recorded through db) unless in.DryRun; their warnings are returned
+// in cs.Warnings.
+//
+// Items are applied in (namespace, path) order so concurrent changesets lock config rows in the same order.
+func applyChangeset(ctx context.Context, db *pgxpool.Pool, tx *eventTx, in changesetInput) (Changeset, error) {
+	var csID pgtype.UUID
+	var cs Changeset
+	var revertsID *pgtype.UUID
+	if in.RevertsID.Valid {
+		revertsID = &in.RevertsID
+	}
+	err := tx.QueryRow(ctx, `
+		INSERT INTO changesets (created_by, comment, reverts_changeset_id, request_id, user_agent, source_ip)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, in.CreatedBy, in.Comment, revertsID, in.RequestID, in.UserAgent, in.SourceIP).Scan(&csID, &cs.CreatedAt)
+	if err != nil {
+		return Changeset{}, err
+	}
+	cs.ID = uuidToString(csID)
+	cs.CreatedBy = in.CreatedBy
+	cs.Comment = in.Comment
+	if revertsID != nil {
+		cs.RevertsChangesetID = ptr(uuidToString(in.RevertsID))
+	}
+	cs.ItemCount = len(in.Items)
+	cs.Items = make([]ChangesetItem, len(in.Items))
+
+	policies := map[string]NamespacePolicy{}
+	order := make([]int, len(in.Items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		x, y := in.Items[order[a]], in.Items[order[b]]
+		if x.Namespace != y.Namespace {
+			return x.Namespace < y.Namespace
+		}
+		return x.Path < y.Path
+	})
+
+	for _, i := range order {
+		it := in.Items[i]
+		itemErr := func(status int, code, msg string, details map[string]any) error {
+			if details == nil {
+				details = map[string]any{}
+			}
+			details["item"] = i
+			details["namespace"] = it.Namespace
+			details["path"] = it.Path
+			return &httpError{Status: status, Code: code, Message: msg, Details: details}
+		}
+
+		policy, ok := policies[it.Namespace]
+		if !ok {
+			policy, err = storeGetNamespacePolicy(ctx, tx, it.Namespace)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return Changeset{}, itemErr(http.StatusNotFound, "not_found", "namespace not found", nil)
+			}
+			if err != nil {
+				return Changeset{}, err
+			}
+			policies[it.Namespace] = policy
+		}
+		// Same rule as POST, PUT and DELETE: namespaces that require approvals only change through published drafts.
+		if err := checkDirectWrite(policy, it.Action); err != nil {
+			var he *httpError
+			if errors.As(err, &he) {
+				return Changeset{}, itemErr(he.Status, he.Code, he.Message, he.Details)
+			}
+			return Changeset{}, err
+		}
+
+		if err := checkLocks(ctx, tx, it.Namespace, []string{it.Path}, in.LockOverride); err != nil {
+			var he *httpError
+			if errors.As(err, &he) {
+				return Changeset{}, itemErr(he.Status, he.Code, he.Message, he.Details)
+			}
+			return Changeset{}, err
+		}
+
+		// Deletes and undeletes do not produce a version, so the comment policy only applies to creates and updates.
+		restore := it.Action == changeActionCreate && it.expectConfigID.Valid
+		var ticketRefs []string
+		if it.Action != changeActionDelete && !restore {
+			if ticketRefs, err = checkComment(policy, in.Comment); err != nil {
+				var he *httpError
+				if errors.As(err, &he) {
+					return Changeset{}, itemErr(he.Status, he.Code, he.Message, he.Details)
+				}
+				return Changeset{}, err
+			}
+		}
+
+		item := ChangesetItem{Action: it.Action, Namespace: it.Namespace, Path: it.Path}
+		var cfgID pgtype.UUID
+		admitItem := func(review admissionReview) error {
+			if in.DryRun {
+				return nil
+			}
+			review.Namespace, review.Path, review.Author, review.Comment = it.Namespace, it.Path, in.CreatedBy, in.Comment
+			review.RequestID, review.userAgent, review.sourceIP = in.RequestID, in.UserAgent, in.SourceIP
+			warnings, err := admit(ctx, db, tx, review)
+			if err != nil {
+				var he *httpError
+				if errors.As(err, &he) {
+					return itemErr(he.Status, he.Code, he.Message, he.Details)
+				}
+				return err
+			}
+			for _, w := range warnings {
+				cs.Warnings = append(cs.Warnings, it.Namespace+"/"+it.Path+": "+w)
+			}
+			return nil
+		}
+
+		if restore {
+			// Undelete: the config keeps its id, so its versions, tags and drafts come back with it. Its latest
+			// version is live again as it was; config_lifetimes records the restore, so as_of reads inside the
+			// deletion still miss it.
+			var fmtStr string
+			err = tx.QueryRow(ctx, `
+				SELECT id, format::text FROM configs
+				WHERE id = $1 AND namespace = $2 AND path = $3 AND deleted_at IS NOT NULL
+				FOR UPDATE
+			`, it.expectConfigID, it.Namespace, it.Path).Scan(&cfgID, &fmtStr)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return Changeset{}, itemErr(http.StatusConflict, "conflict", "config is no longer deleted", nil)
+			}
+			if err != nil {
+				return Changeset{}, err
+			}
+			latest, err := storeGetLatestVersion(ctx, tx, cfgID)
+			if err != nil {
+				return Changeset{}, err
+			}
+			_, latestJSON, err := parseBody(ConfigFormat(fmtStr), latest.BodyRaw)
+			if err != nil {
+				return Changeset{}, err
+			}
+			if it.BaseVersion != nil && *it.BaseVersion != latest.Version {
+				return Changeset{}, itemErr(http.StatusConflict, "conflict", "base_version does not match current latest", map[string]any{
+					"base_version":    *it.BaseVersion,
+					"current_version": latest.Version,
+				})
+			}
+			if _, err := tx.Exec(ctx, `UPDATE configs SET deleted_at = NULL WHERE id = $1`, cfgID); err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+					return Changeset{}, itemErr(http.StatusConflict, "conflict", "path is taken by another config", nil)
+				}
+				return Changeset{}, err
+			}
+			if err := admitItem(admissionReview{
+				Operation: admissionOpCreate,
+				Format:    ConfigFormat(fmtStr),
+				New:       admissionObject{Version: latest.Version, BodyRaw: latest.BodyRaw, BodyJSON: latestJSON},
+			}); err != nil {
+				return Changeset{}, err
+			}
+			if err := storeInsertEvent(ctx, tx, eventInput{
+				Type: eventConfigCreated, Namespace: it.Namespace, Path: it.Path, ConfigID: cfgID, Version: latest.Version,
+				Author: in.CreatedBy, SHA256: sha256Hex(latest.BodyRaw), RequestID: in.RequestID,
+			}); err != nil {
+				return Changeset{}, err
+			}
+			item.Version = ptr(latest.Version)
+		} else if it.Action == changeActionCreate {
+			_, parsedJSON, err := parseBody(it.Format, it.BodyRaw)
+			if err != nil {
+				return Changeset{}, itemErr(http.StatusBadRequest, "bad_request", err.Error(), nil)
+			}
+			err = tx.QueryRow(ctx, `
+				INSERT INTO configs (namespace, path, format)
+				VALUES ($1, $2, $3)
+				RETURNING id
+			`, it.Namespace, it.Path, string(it.Format)).Scan(&cfgID)
+			if err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+					return Changeset{}, itemErr(http.StatusConflict, "conflict", "config already exists", nil)
+				}
+				return Changeset{}, err
+			}
+			if err := admitItem(admissionReview{
+				Operation: admissionOpCreate,
+				Format:    it.Format,
+				New:       admissionObject{Version: 1, BodyRaw: it.BodyRaw, BodyJSON: parsedJSON},
+			}); err != nil {
+				return Changeset{}, err
+			}
+			ver := versionInput{
+				ConfigID:    cfgID,
+				Version:     1,
+				BodyRaw:     it.BodyRaw,
+				BodyJSON:    parsedJSON,
+				CreatedBy:   in.CreatedBy,
+				Comment:     in.Comment,
+				SHA256:      sha256Hex(it.BodyRaw),
+				TicketRefs:  ticketRefs,
+				ChangesetID: csID,
+				RequestID:   in.RequestID,
+				UserAgent:   in.UserAgent,
+				SourceIP:    in.SourceIP,
+			}
+			if _, _, err := storeInsertVersion(ctx, tx, ver); err != nil {
+				return Changeset{}, err
+			}
+			if err := storeInsertEvent(ctx, tx, versionEvent(eventConfigCreated, it.Namespace, it.Path, ver)); err != nil {
+				return Changeset{}, err
+			}
+			item.Version = ptr(1)
+		} else {
+			cfg, id, err := storeLockConfig(ctx, tx, it.Namespace, it.Path)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return Changeset{}, itemErr(http.StatusNotFound, "not_found", "config not found", nil)
+			}
+			if err != nil {
+				return Changeset{}, err
+			}
+			cfgID = id
+			if it.expectConfigID.Valid && it.expectConfigID != cfgID {
+				return Changeset{}, itemErr(http.StatusConflict, "conflict", "config was deleted and recreated since", nil)
+			}
+			latestNum, err := storeLatestVersionNumber(ctx, tx, cfgID)
+			if err != nil {
+				return Changeset{}, err
+			}
+			if it.BaseVersion != nil && *it.BaseVersion != latestNum {
+				return Changeset{}, itemErr(http.StatusConflict, "conflict", "base_version does not match current latest", map[string]any{
+					"base_version":    *it.BaseVersion,
+					"current_version": latestNum,
+				})
+			}
+			item.PreviousVersion = ptr(latestNum)
+
+			if it.Action == changeActionDelete {
+				if _, err := tx.Exec(ctx, `UPDATE configs SET deleted_at = now() WHERE id = $1`, cfgID); err != nil {
+					return Changeset{}, err
+				}
+				if err := storeInsertEvent(ctx, tx, eventInput{
+					Type: eventConfigDeleted, Namespace: it.Namespace, Path: it.Path, ConfigID: cfgID, RequestID: in.RequestID,
+				}); err != nil {
+					return Changeset{}, err
+				}
+			} else {
+				sha := sha256Hex(it.BodyRaw)
+				if latestNum > 0 {
+					latestSHA, err := storeVersionContentSHA(ctx, tx, cfgID, latestNum)
+					if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+						return Changeset{}, err
+					}
+					if latestSHA == sha {
+						return Changeset{}, itemErr(http.StatusConflict, "no_change", "body_raw matches current latest", map[string]any{
+							"current_version": latestNum,
+						})
+					}
+				}
+				_, parsedJSON, err := parseBody(cfg.Format, it.BodyRaw)
+				if err != nil {
+					return Changeset{}, itemErr(http.StatusBadRequest, "bad_request", err.Error(), nil)
+				}
+				if err := admitItem(admissionReview{
+					Operation:  admissionOpUpdate,
+					Format:     cfg.Format,
+					New:        admissionObject{Version: latestNum + 1, BodyRaw: it.BodyRaw, BodyJSON: parsedJSON},
+					configID:   cfgID,
+					oldVersion: latestNum,
+				}); err != nil {
+					return Changeset{}, err
+				}
+				ver := versionInput{
+					ConfigID:    cfgID,
+					Version:     latestNum + 1,
+					BodyRaw:     it.BodyRaw,
+					BodyJSON:    parsedJSON,
+					CreatedBy:   in.CreatedBy,
+					Comment:     in.Comment,
+					SHA256:      sha,
+					TicketRefs:  ticketRefs,
+					ChangesetID: csID,
+					RequestID:   in.RequestID,
+					UserAgent:   in.UserAgent,
+					SourceIP:    in.SourceIP,
+				}
+				if _, _, err := storeInsertVersion(ctx, tx, ver); err != nil {
+					return Changeset{}, err
+				}
+				if err := storeInsertEvent(ctx, tx, versionEvent(eventConfigUpdated, it.Namespace, it.Path, ver)); err != nil {
+					return Changeset{}, err
+				}
+				item.Version = ptr(latestNum + 1)
+			}
+		}
+
+		item.ConfigID = ptr(uuidToString(cfgID))
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO changeset_items (changeset_id, position, action, namespace, path, config_id, previous_version, version)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, csID, i, item.Action, item.Namespace, item.Path, cfgID, item.PreviousVersion, item.Version); err != nil {
+			return Changeset{}, err
+		}
+		cs.Items[i] = item
+	}
+	return cs, nil
+}
+
+// revertChangesetItems builds the items that undo a changeset, in reverse order:
+// creates become deletes, updates restore the previous body, deletes undelete the config (same id, same latest version).
+// Each item is pinned to the version the changeset produced, so later edits make the revert fail with 409.
+func revertChangesetItems(ctx context.Context, q querier, cs Changeset) ([]changesetItemInput, error) {
+	out := make([]changesetItemInput, 0, len(cs.Items))
+	for i := len(cs.Items) - 1; i >= 0; i-- {
+		it := cs.Items[i]
+		itemErr := func(msg string) error {
+			return &httpError{Status: http.StatusConflict, Code: "conflict", Message: msg, Details: map[string]any{
+				"item": i, "namespace": it.Namespace, "path": it.Path,
+			}}
+		}
+		if it.ConfigID == nil {
+			return nil, itemErr("config no longer exists")
+		}
+		cfgID, err := parseUUID(*it.ConfigID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch it.Action {
+		case changeActionCreate:
+			out = append(out, changesetItemInput{
+				Action: changeActionDelete, Namespace: it.Namespace, Path: it.Path,
+				BaseVersion: it.Version, expectConfigID: cfgID,
+			})
+		case changeActionUpdate:
+			var bodyRaw string
+			err := q.QueryRow(ctx, `
+				SELECT body_raw FROM config_versions WHERE config_id = $1 AND version = $2
+			`, cfgID, *it.PreviousVersion).Scan(&bodyRaw)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, itemErr(fmt.Sprintf("version %d no longer exists", *it.PreviousVersion))
+			}
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, changesetItemInput{
+				Action: changeActionUpdate, Namespace: it.Namespace, Path: it.Path, BodyRaw: bodyRaw,
+				BaseVersion: it.Version, expectConfigID: cfgID,
+			})
+		case changeActionDelete:
+			out = append(out, changesetItemInput{
+				Action: changeActionCreate, Namespace: it.Namespace, Path: it.Path,
+				BaseVersion: it.PreviousVersion, expectConfigID: cfgID,
+			})
+		}
+	}
+	return out, nil
+}
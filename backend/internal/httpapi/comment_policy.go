@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	maxMinCommentLength = 1000
+	maxTicketPatternLen = 200
+	// maxTicketRefs bounds the ticket IDs recorded per version.
+	maxTicketRefs = 50
+)
+
+// ticketPatterns caches compiled namespace ticket patterns by source.
+var ticketPatterns sync.Map // string -> *regexp.Regexp
+
+func compileTicketPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := ticketPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	ticketPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// validateTicketPattern checks a ticket_pattern policy value; "" (clear) is valid.
+func validateTicketPattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	if len(pattern) > maxTicketPatternLen {
+		return fmt.Errorf("ticket_pattern must be at most %d characters", maxTicketPatternLen)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("ticket_pattern is not a valid regular expression: %v", err)
+	}
+	if re.MatchString("") {
+		return errors.New("ticket_pattern must not match the empty string")
+	}
+	return nil
+}
+
+// extractTicketRefs returns the distinct matches of pattern in comment, in order of first appearance.
+func extractTicketRefs(pattern *string, comment *string) []string {
+	if pattern == nil || comment == nil {
+		return nil
+	}
+	re, err := compileTicketPattern(*pattern)
+	if err != nil {
+		return nil
+	}
+	var refs []string
+	seen := map[string]bool{}
+	for _, m := range re.FindAllString(*comment, -1) {
+		if seen[m] || len(refs) == maxTicketRefs {
+			continue
+		}
+		seen[m] = true
+		refs = append(refs, m)
+	}
+	return refs
+}
+
+// checkComment enforces the namespace comment policy p on a write and returns the ticket IDs referenced by the
+// comment. Violations are a 400 *httpError with code "comment_policy".
+func checkComment(p NamespacePolicy, comment *string) ([]string, error) {
+	violation := func(msg string) error {
+		return &httpError{
+			Status:  http.StatusBadRequest,
+			Code:    "comment_policy",
+			Message: msg,
+			Details: map[string]any{"field": "comment", "policy": p},
+		}
+	}
+	text := ""
+	if comment != nil {
+		text = strings.TrimSpace(*comment)
+	}
+	if text == "" && (p.RequireComment || p.MinCommentLength > 0 || p.TicketPattern != nil) {
+		return nil, violation("comment is required in namespace " + p.Namespace)
+	}
+	if n := utf8.RuneCountInString(text); n < p.MinCommentLength {
+		return nil, violation(fmt.Sprintf("comment must be at least %d characters", p.MinCommentLength))
+	}
+	refs := extractTicketRefs(p.TicketPattern, comment)
+	if p.TicketPattern != nil && len(refs) == 0 {
+		return nil, violation("comment must reference a ticket matching " + *p.TicketPattern)
+	}
+	return refs, nil
+}
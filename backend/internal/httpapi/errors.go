@@ -1,12 +1,9 @@
 package httpapi
 
-import "net/http"
-
-type apiError struct {
-	Code    string         `json:"code"`
-	Message string         `json:"message"`
-	Details map[string]any `json:"details,omitempty"`
-}
+import (
+	"errors"
+	"net/http"
+)
 
 func writeError(w http.ResponseWriter, status int, code, message string, details map[string]any) {
 	writeJSON(w, status, apiError{
@@ -15,3 +12,24 @@ func writeError(w http.ResponseWriter, status int, code, message string, details
 		Details: details,
 	})
 }
+
+// httpError is returned by write paths shared between handlers (e.g. changesets) so each caller can
+// report the same status, code and details.
+type httpError struct {
+	Status  int
+	Code    string
+	Message string
+	Details map[string]any
+}
+
+func (e *httpError) Error() string { return e.Message }
+
+// writeHTTPError writes err as an API error: an *httpError as-is, anything else as 500 internal_error with fallback.
+func writeHTTPError(w http.ResponseWriter, err error, fallback string) {
+	var he *httpError
+	if errors.As(err, &he) {
+		writeError(w, he.Status, he.Code, he.Message, he.Details)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, "internal_error", fallback, nil)
+}
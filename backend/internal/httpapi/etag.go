@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// versionETag is the strong ETag of a config version: it changes whenever the version number or content does.
+func versionETag(version int, contentSHA256 string) string {
+	return `"v` + strconv.Itoa(version) + "-" + contentSHA256 + `"`
+}
+
+// configVersionETag derives the ETag of a full version, hashing body_raw for rows that predate content_sha256.
+func configVersionETag(v ConfigVersion) string {
+	if v.ContentSHA256 != nil && *v.ContentSHA256 != "" {
+		return versionETag(v.Version, *v.ContentSHA256)
+	}
+	return versionETag(v.Version, sha256Hex(v.BodyRaw))
+}
+
+// representationETag is the strong ETag of one encoded response about a version: the version ETag extended with a
+// hash of body, so it also changes with everything else the body carries (e.g. locks). If-Match accepts it in place
+// of the version ETag (see etagVersionPart).
+func representationETag(versionETag string, body []byte) string {
+	return strings.TrimSuffix(versionETag, `"`) + "." + sha256Hex(string(body))[:16] + `"`
+}
+
+// etagVersionPart strips the body hash of a representationETag, leaving the version ETag. Other ETags are returned
+// unchanged.
+func etagVersionPart(etag string) string {
+	if i := strings.LastIndexByte(etag, '.'); strings.HasPrefix(etag, `"v`) && i > 0 {
+		return etag[:i] + `"`
+	}
+	return etag
+}
+
+// etagListMatches reports whether an If-Match / If-None-Match header value matches etag.
+// "*" matches any existing representation. With weak, W/ prefixes are ignored (RFC 9110 weak comparison).
+func etagListMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		} else if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONWithETag writes v with an ETag, or 304 Not Modified if the request's If-None-Match already matches it.
+// Clients and caches must revalidate (Cache-Control: no-cache) because latest can move at any time.
+func writeJSONWithETag(w http.ResponseWriter, req *http.Request, status int, v any, etag string) {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if inm := req.Header.Get("If-None-Match"); inm != "" && etagListMatches(inm, etag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, status, v)
+}
+
+// writeJSONWithVersionETag is writeJSONWithETag for responses about one version, with a representationETag of
+// versionETag and the encoded body.
+func writeJSONWithVersionETag(w http.ResponseWriter, req *http.Request, status int, v any, versionETag string) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "encode failed", nil)
+		return
+	}
+	writeJSONWithETag(w, req, status, v, representationETag(versionETag, b))
+}
+
+// writeJSONWithBodyETag is writeJSONWithETag for responses without a natural version (e.g. lists):
+// the ETag is a hash of the encoded body.
+func writeJSONWithBodyETag(w http.ResponseWriter, req *http.Request, status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "encode failed", nil)
+		return
+	}
+	writeJSONWithETag(w, req, status, v, `"`+sha256Hex(string(b))[:32]+`"`)
+}
+
+// checkIfMatch enforces If-Match for writes against the current ETag ("" if the resource does not exist).
+// Representation ETags from GET responses match the version they describe. On mismatch it writes 412
+// precondition_failed and returns false.
+func checkIfMatch(w http.ResponseWriter, req *http.Request, currentETag string) bool {
+	im := req.Header.Get("If-Match")
+	if im == "" {
+		return true
+	}
+	if currentETag != "" {
+		for _, candidate := range strings.Split(im, ",") {
+			if etagListMatches(etagVersionPart(strings.TrimSpace(candidate)), currentETag, false) {
+				return true
+			}
+		}
+	}
+	details := map[string]any{"if_match": im}
+	if currentETag != "" {
+		details["current_etag"] = currentETag
+	}
+	writeError(w, http.StatusPreconditionFailed, "precondition_failed", "If-Match does not match the current version", details)
+	return false
+}
@@ -0,0 +1,238 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	eventConfigCreated    = "config.created"
+	eventConfigUpdated    = "config.updated"
+	eventConfigDeleted    = "config.deleted"
+	eventConfigMoved      = "config.moved"
+	eventVersionDeleted   = "version.deleted"
+	eventNamespaceCreated = "namespace.created"
+	eventNamespaceDeleted = "namespace.deleted"
+	eventNamespaceRenamed = "namespace.renamed"
+
+	// eventsLockKey is the advisory lock event writers hold while committing (arbitrary, unique within this schema).
+	eventsLockKey = 7265001
+)
+
+// eventInput is one change to record in config_events. Empty strings and zero values are stored as NULL.
+type eventInput struct {
+	Type          string
+	Namespace     string
+	Path          string
+	ConfigID      pgtype.UUID
+	Version       int
+	Author        *string
+	SHA256        string
+	FromNamespace string
+	FromPath      string
+	RequestID     *string
+}
+
+// versionEvent describes a write that appended in as the new latest version of namespace/path.
+func versionEvent(typ, namespace, path string, in versionInput) eventInput {
+	return eventInput{
+		Type:      typ,
+		Namespace: namespace,
+		Path:      path,
+		ConfigID:  in.ConfigID,
+		Version:   in.Version,
+		Author:    in.CreatedBy,
+		SHA256:    in.SHA256,
+		RequestID: in.RequestID,
+	}
+}
+
+// eventTx is a transaction that records events. storeInsertEvent only queues them; Commit writes all of them in one
+// statement, together with the outbox rows of the webhooks they match (see webhookMatch), and commits. Writers
+// serialise on an advisory lock taken just before that statement and held until commit, so event ids are assigned in
+// commit order and readers resuming after an id cannot skip a late commit, while large transactions (imports,
+// rollbacks) hold the lock only for their last statement.
+type eventTx struct {
+	pgx.Tx
+	events []eventInput
+}
+
+func beginEventTx(ctx context.Context, db *pgxpool.Pool) (*eventTx, error) {
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &eventTx{Tx: tx}, nil
+}
+
+// storeInsertEvent records an event of tx; it is written when tx commits.
+func storeInsertEvent(_ context.Context, tx *eventTx, in eventInput) error {
+	tx.events = append(tx.events, in)
+	return nil
+}
+
+func (tx *eventTx) Commit(ctx context.Context) error {
+	if len(tx.events) > 0 {
+		if err := tx.flushEvents(ctx); err != nil {
+			tx.Tx.Rollback(ctx)
+			return err
+		}
+	}
+	return tx.Tx.Commit(ctx)
+}
+
+func (tx *eventTx) flushEvents(ctx context.Context) error {
+	n := len(tx.events)
+	types, namespaces, paths := make([]string, n), make([]string, n), make([]string, n)
+	configIDs, versions, authors := make([]pgtype.UUID, n), make([]int, n), make([]*string, n)
+	shas, fromNamespaces, fromPaths, requestIDs := make([]string, n), make([]string, n), make([]string, n), make([]*string, n)
+	for i, in := range tx.events {
+		types[i], namespaces[i], paths[i] = in.Type, in.Namespace, in.Path
+		configIDs[i], versions[i], authors[i] = in.ConfigID, in.Version, in.Author
+		shas[i], fromNamespaces[i], fromPaths[i], requestIDs[i] = in.SHA256, in.FromNamespace, in.FromPath, in.RequestID
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, eventsLockKey); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		WITH e AS (
+			INSERT INTO config_events (type, namespace, path, config_id, version, author, content_sha256, from_namespace, from_path, request_id)
+			SELECT i.type, i.namespace, NULLIF(i.path, ''), i.config_id, NULLIF(i.version, 0), i.author,
+				NULLIF(i.sha, ''), NULLIF(i.from_namespace, ''), NULLIF(i.from_path, ''), i.request_id
+			FROM unnest($1::text[], $2::text[], $3::text[], $4::uuid[], $5::int[], $6::text[], $7::text[], $8::text[], $9::text[], $10::text[])
+				WITH ORDINALITY AS i(type, namespace, path, config_id, version, author, sha, from_namespace, from_path, request_id, n)
+			ORDER BY i.n
+			RETURNING *
+		)
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT w.id, e.id, e.type, `+eventPayloadSQL+`
+		FROM e
+		JOIN webhooks w ON `+webhookMatch+`
+	`, types, namespaces, paths, configIDs, versions, authors, shas, fromNamespaces, fromPaths, requestIDs)
+	if err != nil {
+		return err
+	}
+	tx.events = nil
+	return nil
+}
+
+// eventPayloadSQL renders a config_events row e as the JSON of ConfigEvent.
+const eventPayloadSQL = `jsonb_strip_nulls(jsonb_build_object(
+	'id', e.id, 'type', e.type, 'namespace', e.namespace, 'path', e.path, 'config_id', e.config_id,
+	'version', e.version, 'author', e.author, 'content_sha256', e.content_sha256,
+	'from_namespace', e.from_namespace, 'from_path', e.from_path, 'created_at', e.created_at
+))`
+
+// webhookMatch selects the active webhooks w subscribed to event e. Like event streams, moves and renames also match
+// subscriptions on their previous location.
+const webhookMatch = `w.active
+	AND (cardinality(w.event_types) = 0 OR e.type = ANY(w.event_types))
+	AND (
+		(w.namespace IS NULL OR w.namespace = e.namespace) AND (w.prefix = '' OR left(e.path, length(w.prefix)) = w.prefix OR e.path IS NULL)
+		OR (w.namespace IS NULL OR w.namespace = e.from_namespace) AND (w.prefix = '' OR left(e.from_path, length(w.prefix)) = w.prefix)
+	)`
+
+const eventColumns = `id, type, namespace, path, config_id, version, author, content_sha256, from_namespace, from_path, created_at`
+
+func scanConfigEvent(s rowScanner) (ConfigEvent, error) {
+	var e ConfigEvent
+	var path, author, sha, fromNamespace, fromPath sql.NullString
+	var cfgID pgtype.UUID
+	var version sql.NullInt32
+	if err := s.Scan(&e.ID, &e.Type, &e.Namespace, &path, &cfgID, &version, &author, &sha, &fromNamespace, &fromPath, &e.CreatedAt); err != nil {
+		return ConfigEvent{}, err
+	}
+	if path.Valid {
+		e.Path = &path.String
+	}
+	if cfgID.Valid {
+		e.ConfigID = ptr(uuidToString(cfgID))
+	}
+	if version.Valid {
+		e.Version = ptr(int(version.Int32))
+	}
+	if author.Valid {
+		e.Author = &author.String
+	}
+	if sha.Valid {
+		e.ContentSHA256 = &sha.String
+	}
+	if fromNamespace.Valid {
+		e.FromNamespace = &fromNamespace.String
+	}
+	if fromPath.Valid {
+		e.FromPath = &fromPath.String
+	}
+	return e, nil
+}
+
+// storeListEvents returns up to limit events after id afterID, oldest first. namespace and prefix ("" = any) match
+// either the current or (for moves and renames) the previous location.
+func storeListEvents(ctx context.Context, q querier, afterID int64, namespace, prefix string, limit int) ([]ConfigEvent, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+eventColumns+`
+		FROM config_events
+		WHERE id > $1
+		  AND (
+		    ($2 = '' OR namespace = $2) AND ($3 = '' OR left(path, length($3::text)) = $3 OR path IS NULL)
+		    OR ($2 = '' OR from_namespace = $2) AND ($3 = '' OR left(from_path, length($3::text)) = $3)
+		  )
+		ORDER BY id ASC
+		LIMIT $4
+	`, afterID, namespace, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []ConfigEvent
+	for rows.Next() {
+		e, err := scanConfigEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, e)
+	}
+	return items, rows.Err()
+}
+
+// storeLastEventID returns the id of the newest event (0 if there is none).
+func storeLastEventID(ctx context.Context, q querier) (int64, error) {
+	var id int64
+	err := q.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM config_events`).Scan(&id)
+	return id, err
+}
+
+// RunEventRetention deletes events, and finished (succeeded or dead) webhook deliveries, older than retention once an
+// hour until ctx is cancelled. Streams resuming from a pruned id continue with the oldest retained event.
+func RunEventRetention(ctx context.Context, db *pgxpool.Pool, retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if _, err := db.Exec(ctx, `
+			DELETE FROM config_events WHERE created_at < now() - $1::int * interval '1 second'
+		`, int(retention.Seconds())); err != nil && ctx.Err() == nil {
+			log.Printf("events: retention cleanup failed: %v", err)
+		}
+		if _, err := db.Exec(ctx, `
+			DELETE FROM webhook_deliveries
+			WHERE status <> 'pending' AND created_at < now() - $1::int * interval '1 second'
+		`, int(retention.Seconds())); err != nil && ctx.Err() == nil {
+			log.Printf("events: webhook delivery cleanup failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// exportManifestName is the first entry of every export archive.
+const exportManifestName = "manifest.json"
+
+// archiveWriter writes the entries of an export archive straight to the response.
+type archiveWriter interface {
+	add(name string, modTime time.Time, data []byte) error
+	Close() error
+}
+
+// newArchiveWriter returns a writer for format "tar" or "zip" and the archive's content type and file extension.
+func newArchiveWriter(format string, w io.Writer) (archiveWriter, string, string) {
+	if format == "zip" {
+		return zipArchive{zip.NewWriter(w)}, "application/zip", ".zip"
+	}
+	return tarArchive{tar.NewWriter(w)}, "application/x-tar", ".tar"
+}
+
+type tarArchive struct{ w *tar.Writer }
+
+func (a tarArchive) add(name string, modTime time.Time, data []byte) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(data)),
+		ModTime:  modTime.UTC().Truncate(time.Second),
+		Format:   tar.FormatPAX, // long paths and UTF-8 names
+	}
+	if err := a.w.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := a.w.Write(data)
+	return err
+}
+
+func (a tarArchive) Close() error { return a.w.Close() }
+
+type zipArchive struct{ w *zip.Writer }
+
+func (a zipArchive) add(name string, modTime time.Time, data []byte) error {
+	f, err := a.w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime.UTC()})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func (a zipArchive) Close() error { return a.w.Close() }
+
+// exportFileNames sets the archive file name of each item: its path with the extension of its format appended
+// unless the path already has one (app -> app.json, app.yml stays app.yml). Paths that already carry their extension
+// keep it; other names that would clash with them (or with the manifest) get the config ID before the extension.
+func exportFileNames(items []ExportManifestItem) {
+	used := map[string]bool{exportManifestName: true}
+	for i := range items {
+		it := &items[i]
+		if strings.HasSuffix(it.Path, exportExtension(it.Path, it.Format)) && !used[it.Path] {
+			it.File = it.Path
+			used[it.File] = true
+		}
+	}
+	for i := range items {
+		it := &items[i]
+		if it.File != "" {
+			continue
+		}
+		ext := exportExtension(it.Path, it.Format)
+		name := strings.TrimSuffix(it.Path, ext) + ext
+		if used[name] {
+			name = strings.TrimSuffix(name, ext) + "." + it.ConfigID + ext
+		}
+		used[name] = true
+		it.File = name
+	}
+}
+
+// exportExtension is the native file extension of a config: .json, or .yaml unless the path already ends in .yml.
+func exportExtension(p string, format ConfigFormat) string {
+	if format == FormatJSON {
+		return ".json"
+	}
+	if path.Ext(p) == ".yml" {
+		return ".yml"
+	}
+	return ".yaml"
+}
@@ -0,0 +1,546 @@
+package httpapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// gitMirrorLockKey is the session advisory lock held during a mirror pass, so one replica mirrors at a time.
+	gitMirrorLockKey = 7265002
+	// gitMirrorBatchSize is the number of versions written per git fast-import run.
+	gitMirrorBatchSize = 500
+	// gitMirrorStateFile records, in the mirrored tree, the last exported version and the file of every config.
+	gitMirrorStateFile = ".config-manager/mirror.json"
+	gitMirrorCommitter = "config-manager <config-manager@localhost>"
+)
+
+// GitMirrorConfig selects what RunGitMirror mirrors and where.
+type GitMirrorConfig struct {
+	// Repo is the local bare repository the mirror writes to (created if missing).
+	Repo string
+	// Remote, if set, is pushed to after every pass (e.g. file:///srv/git/configs.git).
+	Remote string
+	Branch string
+	// Namespaces are mirrored into top-level folders of the same name; empty mirrors every namespace.
+	Namespaces []string
+	Interval   time.Duration
+}
+
+// RunGitMirror mirrors configs into a Git repository until ctx is cancelled: every config version becomes one commit
+// with the version's author, time and comment, so `git log` and `git blame` show the config history.
+//
+// Progress is kept in the repository itself (gitMirrorStateFile, as per-config version cursors), so a mirror resumes
+// where it stopped, back-fills the whole history on its first run, and picks up versions whose transaction committed
+// after later ones were mirrored. Deleted configs (and configs moved out of the mirrored namespaces) are removed, moved
+// configs are renamed. Passes run every interval and after changes. With a Remote, every pass first fast-forwards the
+// local branch to the remote one, so it is safe to run on every replica: whichever replica holds the lock continues
+// from what the others pushed.
+func RunGitMirror(ctx context.Context, db *pgxpool.Pool, cfg GitMirrorConfig) {
+	m := &gitMirror{db: db, cfg: cfg}
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	cw := configChanges.subscribe("", "", "")
+	defer configChanges.unsubscribe("", cw)
+
+	for {
+		if err := m.sync(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("git mirror: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-cw.ch:
+		}
+	}
+}
+
+type gitMirror struct {
+	db          *pgxpool.Pool
+	cfg         GitMirrorConfig
+	ready       bool
+	pushPending bool
+}
+
+// mirrorState is the content of gitMirrorStateFile, keyed by config ID.
+type mirrorState struct {
+	Configs map[string]*mirrorEntry `json:"configs"`
+
+	owners map[string]string // file -> config ID of live entries
+}
+
+type mirrorEntry struct {
+	Namespace string `json:"namespace"`
+	Path      string `json:"path"`
+	File      string `json:"file"`
+	Version   int    `json:"version"`
+	// Deleted entries keep their cursor so the history of a deleted config is not exported again.
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// mirrorCommit is one commit to write with git fast-import.
+type mirrorCommit struct {
+	author  string // "Name <email>"
+	when    time.Time
+	message string
+	deletes []string
+	renames [][2]string
+	files   map[string][]byte
+}
+
+// sync runs one mirror pass: new versions in batches, then deletes and moves, then the push.
+func (m *gitMirror) sync(ctx context.Context) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, gitMirrorLockKey).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil // another replica is mirroring
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, gitMirrorLockKey)
+
+	if !m.ready {
+		if err := m.init(ctx); err != nil {
+			return err
+		}
+		m.ready = true
+	}
+	if m.cfg.Remote != "" {
+		if err := m.pull(ctx); err != nil {
+			return err
+		}
+	}
+
+	st, err := m.loadState(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		n, err := m.exportVersions(ctx, st)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			m.pushPending = true
+		}
+		if n < gitMirrorBatchSize {
+			break
+		}
+	}
+	n, err := m.reconcile(ctx, st)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		m.pushPending = true
+	}
+
+	if m.cfg.Remote != "" && m.pushPending {
+		ref := "refs/heads/" + m.cfg.Branch
+		if _, err := m.git(ctx, nil, "push", "--quiet", m.cfg.Remote, ref+":"+ref); err != nil {
+			return fmt.Errorf("push: %w", err)
+		}
+		m.pushPending = false
+	}
+	return nil
+}
+
+// init creates the bare repository if needed.
+func (m *gitMirror) init(ctx context.Context) error {
+	if _, err := m.git(ctx, nil, "rev-parse", "--git-dir"); err != nil {
+		if err := os.MkdirAll(m.cfg.Repo, 0o755); err != nil {
+			return err
+		}
+		if _, err := m.git(ctx, nil, "init", "--quiet", "--bare", "--initial-branch="+m.cfg.Branch); err != nil {
+			return fmt.Errorf("init %s: %w", m.cfg.Repo, err)
+		}
+		log.Printf("git mirror: created %s", m.cfg.Repo)
+	}
+	return nil
+}
+
+// pull fast-forwards the local branch to the branch of Remote, which other replicas (or this one, before its local
+// repository was lost) may have moved. A local branch ahead of the remote is kept and pushed at the end of the pass;
+// a diverged one stops the mirror rather than writing versions twice onto a history that can never be pushed.
+func (m *gitMirror) pull(ctx context.Context) error {
+	ref := "refs/heads/" + m.cfg.Branch
+	if _, err := m.git(ctx, nil, "ls-remote", "--exit-code", m.cfg.Remote, ref); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+			return nil // the remote has no mirror yet
+		}
+		return fmt.Errorf("fetch: %w", err)
+	}
+	tracking := "refs/remotes/mirror/" + m.cfg.Branch
+	if _, err := m.git(ctx, nil, "fetch", "--quiet", m.cfg.Remote, "+"+ref+":"+tracking); err != nil {
+		return fmt.Errorf("fetch: %w", err)
+	}
+	if !m.branchExists(ctx) {
+		_, err := m.git(ctx, nil, "update-ref", ref, tracking)
+		return err
+	}
+	if _, err := m.git(ctx, nil, "merge-base", "--is-ancestor", ref, tracking); err == nil {
+		old, err := m.git(ctx, nil, "rev-parse", ref)
+		if err != nil {
+			return err
+		}
+		_, err = m.git(ctx, nil, "update-ref", ref, tracking, strings.TrimSpace(string(old)))
+		return err
+	}
+	if _, err := m.git(ctx, nil, "merge-base", "--is-ancestor", tracking, ref); err == nil {
+		m.pushPending = true
+		return nil
+	}
+	return fmt.Errorf("%s of %s has diverged from %s; reset it to the remote branch to resume", ref, m.cfg.Repo, m.cfg.Remote)
+}
+
+func (m *gitMirror) branchExists(ctx context.Context) bool {
+	_, err := m.git(ctx, nil, "rev-parse", "--verify", "--quiet", "refs/heads/"+m.cfg.Branch)
+	return err == nil
+}
+
+func (m *gitMirror) loadState(ctx context.Context) (*mirrorState, error) {
+	st := &mirrorState{Configs: map[string]*mirrorEntry{}}
+	obj := "refs/heads/" + m.cfg.Branch + ":" + gitMirrorStateFile
+	if _, err := m.git(ctx, nil, "cat-file", "-e", obj); err == nil {
+		data, err := m.git(ctx, nil, "cat-file", "blob", obj)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, st); err != nil {
+			return nil, fmt.Errorf("read %s: %w", gitMirrorStateFile, err)
+		}
+	}
+	st.owners = map[string]string{}
+	for id, e := range st.Configs {
+		if !e.Deleted {
+			st.owners[e.File] = id
+		}
+	}
+	return st, nil
+}
+
+// file returns the mirrored file of a config: <namespace>/<path> with the native extension appended if missing (as
+// in exports), or with the config ID before the extension if another config already owns that name.
+func (st *mirrorState) file(id, namespace, p string, format ConfigFormat) string {
+	ext := exportExtension(p, format)
+	name := path.Join(namespace, strings.TrimSuffix(p, ext)+ext)
+	if owner, ok := st.owners[name]; ok && owner != id {
+		name = path.Join(namespace, strings.TrimSuffix(p, ext)+"."+id+ext)
+	}
+	return name
+}
+
+// exportVersions writes the next batch of unexported versions of the mirrored namespaces, oldest first. It returns
+// the number of versions written.
+func (m *gitMirror) exportVersions(ctx context.Context, st *mirrorState) (int, error) {
+	ids := make([]string, 0, len(st.Configs))
+	versions := make([]int, 0, len(st.Configs))
+	for id, e := range st.Configs {
+		ids = append(ids, id)
+		versions = append(versions, e.Version)
+	}
+	namespaces := m.cfg.Namespaces
+	if namespaces == nil {
+		namespaces = []string{}
+	}
+	rows, err := m.db.Query(ctx, `
+		SELECT c.id, c.namespace, c.path, c.format::text, v.version, v.body_raw, v.created_at, v.created_by, v.comment
+		FROM config_versions v
+		JOIN configs c ON c.id = v.config_id
+		LEFT JOIN unnest($2::uuid[], $3::int[]) AS s(config_id, version) ON s.config_id = c.id
+		WHERE (cardinality($1::text[]) = 0 OR c.namespace = ANY($1))
+		  AND v.version > COALESCE(s.version, 0)
+		ORDER BY v.created_at, c.id, v.version
+		LIMIT $4
+	`, namespaces, ids, versions, gitMirrorBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	return m.fastImport(ctx, st, func() (*mirrorCommit, error) {
+		if !rows.Next() {
+			return nil, rows.Err()
+		}
+		var cfgID pgtype.UUID
+		var namespace, p, fmtStr, bodyRaw string
+		var version int
+		var createdAt time.Time
+		var createdBy, comment sql.NullString
+		if err := rows.Scan(&cfgID, &namespace, &p, &fmtStr, &version, &bodyRaw, &createdAt, &createdBy, &comment); err != nil {
+			return nil, err
+		}
+		id := uuidToString(cfgID)
+		file := st.file(id, namespace, p, ConfigFormat(fmtStr))
+		c := &mirrorCommit{
+			author:  gitAuthor(createdBy),
+			when:    createdAt,
+			message: gitMirrorMessage(comment, namespace, p, id, version),
+			files:   map[string][]byte{file: []byte(bodyRaw)},
+		}
+		e := st.Configs[id]
+		if e != nil && !e.Deleted && e.File != file {
+			c.deletes = append(c.deletes, e.File) // moved since the previous version
+			delete(st.owners, e.File)
+		}
+		st.Configs[id] = &mirrorEntry{Namespace: namespace, Path: p, File: file, Version: version}
+		st.owners[file] = id
+		return c, nil
+	})
+}
+
+// reconcile removes the files of configs that were deleted or left the mirrored namespaces, renames the files of
+// moved configs, and restores configs that came back into a mirrored namespace. It returns the commits written.
+func (m *gitMirror) reconcile(ctx context.Context, st *mirrorState) (int, error) {
+	type current struct {
+		namespace, path string
+		format          ConfigFormat
+		deletedAt       *time.Time
+	}
+	ids := make([]string, 0, len(st.Configs))
+	for id := range st.Configs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	rows, err := m.db.Query(ctx, `SELECT id, namespace, path, format::text, deleted_at FROM configs WHERE id = ANY($1::uuid[])`, ids)
+	if err != nil {
+		return 0, err
+	}
+	live := map[string]current{}
+	for rows.Next() {
+		var cfgID pgtype.UUID
+		var c current
+		var fmtStr string
+		if err := rows.Scan(&cfgID, &c.namespace, &c.path, &fmtStr, &c.deletedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		c.format = ConfigFormat(fmtStr)
+		live[uuidToString(cfgID)] = c
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	var commits []*mirrorCommit
+	for _, id := range ids {
+		e := st.Configs[id]
+		c, exists := live[id]
+		mirrored := exists && c.deletedAt == nil && (len(m.cfg.Namespaces) == 0 || slices.Contains(m.cfg.Namespaces, c.namespace))
+		switch {
+		case !mirrored && !e.Deleted:
+			when, what := now, "left the mirrored namespaces"
+			if !exists {
+				what = "deleted"
+			} else if c.deletedAt != nil {
+				when, what = *c.deletedAt, "deleted"
+			}
+			commits = append(commits, &mirrorCommit{
+				author: gitMirrorCommitter, when: when, deletes: []string{e.File},
+				message: fmt.Sprintf("Delete %s/%s\n\nThe config was %s.\n\nConfig-Id: %s\n", e.Namespace, e.Path, what, id),
+			})
+			delete(st.owners, e.File)
+			e.Deleted = true
+		case mirrored && e.Deleted:
+			cfgID, err := parseUUID(id)
+			if err != nil {
+				return 0, err
+			}
+			ver, err := storeGetLatestVersion(ctx, m.db, cfgID)
+			if err != nil {
+				return 0, err
+			}
+			file := st.file(id, c.namespace, c.path, c.format)
+			commits = append(commits, &mirrorCommit{
+				author: gitMirrorCommitter, when: now, files: map[string][]byte{file: []byte(ver.BodyRaw)},
+				message: fmt.Sprintf("Restore %s/%s\n\nThe config was moved into a mirrored namespace.\n\nConfig-Id: %s\nConfig-Version: %d\n", c.namespace, c.path, id, ver.Version),
+			})
+			*e = mirrorEntry{Namespace: c.namespace, Path: c.path, File: file, Version: ver.Version}
+			st.owners[file] = id
+		case mirrored && (c.namespace != e.Namespace || c.path != e.Path):
+			file := st.file(id, c.namespace, c.path, c.format)
+			commits = append(commits, &mirrorCommit{
+				author: gitMirrorCommitter, when: now, renames: [][2]string{{e.File, file}},
+				message: fmt.Sprintf("Move %s/%s to %s/%s\n\nConfig-Id: %s\n", e.Namespace, e.Path, c.namespace, c.path, id),
+			})
+			delete(st.owners, e.File)
+			st.owners[file] = id
+			e.Namespace, e.Path, e.File = c.namespace, c.path, file
+		}
+	}
+
+	i := 0
+	return m.fastImport(ctx, st, func() (*mirrorCommit, error) {
+		if i == len(commits) {
+			return nil, nil
+		}
+		i++
+		return commits[i-1], nil
+	})
+}
+
+// fastImport writes the commits returned by next (until it returns nil) onto the branch with one git fast-import
+// run; the last commit also records st. The branch only moves if every commit was written. It returns the number of
+// commits.
+func (m *gitMirror) fastImport(ctx context.Context, st *mirrorState, next func() (*mirrorCommit, error)) (int, error) {
+	c, err := next()
+	if err != nil || c == nil {
+		return 0, err
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", m.cfg.Repo, "fast-import", "--quiet")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	w := bufio.NewWriter(stdin)
+	from := ""
+	if m.branchExists(ctx) {
+		from = "refs/heads/" + m.cfg.Branch + "^0"
+	}
+
+	n := 0
+	for c != nil && err == nil {
+		var following *mirrorCommit
+		if following, err = next(); err != nil {
+			break
+		}
+		if following == nil {
+			state, merr := json.MarshalIndent(st, "", "  ")
+			if merr != nil {
+				err = merr
+				break
+			}
+			if c.files == nil {
+				c.files = map[string][]byte{}
+			}
+			c.files[gitMirrorStateFile] = append(state, '\n')
+		}
+		writeMirrorCommit(w, m.cfg.Branch, from, c)
+		from = ""
+		n++
+		c = following
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		stdin.Close()
+		cmd.Process.Kill()
+		cmd.Wait()
+		return 0, err
+	}
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return 0, fmt.Errorf("git fast-import: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return n, nil
+}
+
+func writeMirrorCommit(w io.Writer, branch, from string, c *mirrorCommit) {
+	when := fmt.Sprintf("%d +0000", c.when.Unix())
+	fmt.Fprintf(w, "commit refs/heads/%s\n", branch)
+	fmt.Fprintf(w, "author %s %s\n", c.author, when)
+	fmt.Fprintf(w, "committer %s %s\n", gitMirrorCommitter, when)
+	fmt.Fprintf(w, "data %d\n%s\n", len(c.message), c.message)
+	if from != "" {
+		fmt.Fprintf(w, "from %s\n", from)
+	}
+	for _, p := range c.deletes {
+		fmt.Fprintf(w, "D %s\n", fastImportPath(p))
+	}
+	for _, r := range c.renames {
+		fmt.Fprintf(w, "R %s %s\n", fastImportPath(r[0]), fastImportPath(r[1]))
+	}
+	names := make([]string, 0, len(c.files))
+	for name := range c.files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "M 100644 inline %s\ndata %d\n", fastImportPath(name), len(c.files[name]))
+		w.Write(c.files[name])
+		fmt.Fprint(w, "\n")
+	}
+	fmt.Fprint(w, "\n")
+}
+
+// fastImportPath quotes a path for git fast-import when it contains spaces, quotes, backslashes or newlines.
+func fastImportPath(p string) string {
+	if !strings.ContainsAny(p, " \"\\\n") {
+		return p
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(p) + `"`
+}
+
+// gitAuthor turns a version's created_by into a Git identity: an email address is used as both name and email.
+func gitAuthor(createdBy sql.NullString) string {
+	name := strings.NewReplacer("<", "", ">", "", "\n", " ").Replace(strings.TrimSpace(createdBy.String))
+	if name == "" {
+		return "unknown <>"
+	}
+	if strings.Contains(name, "@") && !strings.Contains(name, " ") {
+		return name + " <" + name + ">"
+	}
+	return name + " <>"
+}
+
+// gitMirrorMessage is the commit message of a version: its comment (or a generated subject) and trailers that tie
+// the commit to the version.
+func gitMirrorMessage(comment sql.NullString, namespace, p, id string, version int) string {
+	subject := strings.TrimSpace(comment.String)
+	if subject == "" {
+		verb := "Update"
+		if version == 1 {
+			verb = "Create"
+		}
+		subject = fmt.Sprintf("%s %s/%s", verb, namespace, p)
+	}
+	return fmt.Sprintf("%s\n\nConfig-Namespace: %s\nConfig-Path: %s\nConfig-Id: %s\nConfig-Version: %d\n", subject, namespace, p, id, version)
+}
+
+// git runs a git command in the mirror repository and returns its stdout.
+func (m *gitMirror) git(ctx context.Context, stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", m.cfg.Repo}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,300 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// admissionSpec is the validated, writable part of an admission webhook.
+type admissionSpec struct {
+	Name          string
+	URL           string
+	Prefix        string
+	TimeoutMS     int
+	FailurePolicy string
+	Active        bool
+}
+
+// validate normalizes the spec in place and returns a 400 *httpError naming the offending field.
+func (s *admissionSpec) validate() error {
+	bad := func(field, msg string) error {
+		return &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: msg, Details: map[string]any{"field": field}}
+	}
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" || len(s.Name) > maxWebhookNameLength {
+		return bad("name", "name is required and must be at most 200 characters")
+	}
+	s.URL = strings.TrimSpace(s.URL)
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(s.URL) > maxWebhookURLLength {
+		return bad("url", "url must be an absolute http(s) URL of at most 2048 characters")
+	}
+	if s.Prefix, err = normalizePrefix(s.Prefix); err != nil {
+		return bad("prefix", err.Error())
+	}
+	if s.TimeoutMS < minAdmissionTimeoutMS || s.TimeoutMS > maxAdmissionTimeoutMS {
+		return bad("timeout_ms", fmt.Sprintf("timeout_ms must be between %d and %d", minAdmissionTimeoutMS, maxAdmissionTimeoutMS))
+	}
+	if s.FailurePolicy != admissionFailClosed && s.FailurePolicy != admissionFailOpen {
+		return bad("failure_policy", "failure_policy must be fail_closed or fail_open")
+	}
+	return nil
+}
+
+func getAdmissionWebhookID(w http.ResponseWriter, req *http.Request) (pgtype.UUID, bool) {
+	id, err := parseUUID(chi.URLParam(req, "hook"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "hook must be a UUID", nil)
+		return pgtype.UUID{}, false
+	}
+	return id, true
+}
+
+// writeAdmissionStoreError maps a failed admission webhook insert/update to a response.
+func writeAdmissionStoreError(w http.ResponseWriter, err error, fallback string) {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		writeError(w, http.StatusNotFound, "not_found", "admission webhook not found", nil)
+	case errors.As(err, &pgErr) && pgErr.Code == "23503":
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+	case errors.As(err, &pgErr) && pgErr.Code == "23505":
+		writeError(w, http.StatusConflict, "conflict", "an admission webhook with this name already exists", map[string]any{"field": "name"})
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", fallback, nil)
+	}
+}
+
+// handleCreateAdmissionWebhook registers a hook called before config creates and updates under prefix commit.
+func handleCreateAdmissionWebhook(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	var body CreateAdmissionWebhookRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	spec := admissionSpec{Name: body.Name, URL: body.URL, Prefix: body.Prefix, TimeoutMS: defaultAdmissionTimeoutMS, FailurePolicy: admissionFailClosed, Active: true}
+	if body.TimeoutMS != nil {
+		spec.TimeoutMS = *body.TimeoutMS
+	}
+	if body.FailurePolicy != "" {
+		spec.FailurePolicy = body.FailurePolicy
+	}
+	if body.Active != nil {
+		spec.Active = *body.Active
+	}
+	if err := spec.validate(); err != nil {
+		writeHTTPError(w, err, "invalid admission webhook")
+		return
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	hook, err := scanAdmissionWebhook(db.QueryRow(req.Context(), `
+		WITH a AS (
+			INSERT INTO admission_webhooks (namespace, prefix, name, url, timeout_ms, failure_policy, active, request_id, user_agent, source_ip)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING *
+		)
+		SELECT `+admissionWebhookColumns+` FROM a
+	`, namespace, spec.Prefix, spec.Name, spec.URL, spec.TimeoutMS, spec.FailurePolicy, spec.Active, reqID, userAgent, sourceIP))
+	if err != nil {
+		writeAdmissionStoreError(w, err, "insert failed")
+		return
+	}
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+func handleListAdmissionWebhooks(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	nsOK, err := storeNamespaceExists(req.Context(), db, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !nsOK {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+	hooks, err := storeListAdmissionWebhooks(req.Context(), db, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, AdmissionWebhookListResponse{Items: hooks})
+}
+
+func handleGetAdmissionWebhook(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	id, ok := getAdmissionWebhookID(w, req)
+	if !ok {
+		return
+	}
+	hook, err := storeGetAdmissionWebhook(req.Context(), db, namespace, id)
+	if err != nil {
+		writeAdmissionStoreError(w, err, "query failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// handleUpdateAdmissionWebhook changes the given fields of an admission webhook.
+func handleUpdateAdmissionWebhook(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	id, ok := getAdmissionWebhookID(w, req)
+	if !ok {
+		return
+	}
+	var body UpdateAdmissionWebhookRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "tx begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	hook, err := scanAdmissionWebhook(tx.QueryRow(req.Context(), `
+		SELECT `+admissionWebhookColumns+` FROM admission_webhooks a WHERE a.id = $1 AND a.namespace = $2 FOR UPDATE
+	`, id, namespace))
+	if err != nil {
+		writeAdmissionStoreError(w, err, "query failed")
+		return
+	}
+	spec := admissionSpec{Name: hook.Name, URL: hook.URL, Prefix: hook.Prefix, TimeoutMS: hook.TimeoutMS, FailurePolicy: hook.FailurePolicy, Active: hook.Active}
+	if body.Name != nil {
+		spec.Name = *body.Name
+	}
+	if body.URL != nil {
+		spec.URL = *body.URL
+	}
+	if body.Prefix != nil {
+		spec.Prefix = *body.Prefix
+	}
+	if body.TimeoutMS != nil {
+		spec.TimeoutMS = *body.TimeoutMS
+	}
+	if body.FailurePolicy != nil {
+		spec.FailurePolicy = *body.FailurePolicy
+	}
+	if body.Active != nil {
+		spec.Active = *body.Active
+	}
+	if err := spec.validate(); err != nil {
+		writeHTTPError(w, err, "invalid admission webhook")
+		return
+	}
+
+	hook, err = scanAdmissionWebhook(tx.QueryRow(req.Context(), `
+		WITH a AS (
+			UPDATE admission_webhooks
+			SET name = $2, url = $3, prefix = $4, timeout_ms = $5, failure_policy = $6, active = $7
+			WHERE id = $1
+			RETURNING *
+		)
+		SELECT `+admissionWebhookColumns+` FROM a
+	`, id, spec.Name, spec.URL, spec.Prefix, spec.TimeoutMS, spec.FailurePolicy, spec.Active))
+	if err != nil {
+		writeAdmissionStoreError(w, err, "update failed")
+		return
+	}
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// handleDeleteAdmissionWebhook removes an admission webhook; its recorded denials are kept.
+func handleDeleteAdmissionWebhook(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	id, ok := getAdmissionWebhookID(w, req)
+	if !ok {
+		return
+	}
+	var deleted pgtype.UUID
+	err := db.QueryRow(req.Context(), `
+		DELETE FROM admission_webhooks WHERE id = $1 AND namespace = $2 RETURNING id
+	`, id, namespace).Scan(&deleted)
+	if err != nil {
+		writeAdmissionStoreError(w, err, "delete failed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListAdmissionDenials lists writes rejected by admission webhooks in a namespace, newest first; ?path= filters
+// by config.
+func handleListAdmissionDenials(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	path := ""
+	if raw := req.URL.Query().Get("path"); raw != "" {
+		var err error
+		if path, err = normalizeConfigPath(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "path"})
+			return
+		}
+	}
+	limit, err := parseLimit(req, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	offset, err := parseCursorOffset(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	nsOK, err := storeNamespaceExists(req.Context(), db, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !nsOK {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+	items, err := storeListAdmissionDenials(req.Context(), db, namespace, path, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	var next *string
+	if len(items) == limit {
+		c := encodeCursorOffset(offset + limit)
+		next = &c
+	}
+	writeJSON(w, http.StatusOK, AdmissionDenialListResponse{Items: items, NextCursor: next})
+}
@@ -0,0 +1,131 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// handleBlameConfig walks the version history newest first and reports, for each leaf key of the latest body_json
+// (and optionally each line of body_raw), the version that last changed it. The walk stops as soon as everything
+// is attributed.
+func handleBlameConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	withLines, _, err := parseOptionalBool(req, "lines")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	cfg, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	versions, err := storeBlameSources(req.Context(), db, cfgID, 0, 1)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if len(versions) == 0 {
+		writeError(w, http.StatusNotFound, "not_found", "config has no versions", nil)
+		return
+	}
+	latest := versions[0]
+	walk := newBlameWalk(latest.blameSource, withLines)
+
+	// Step back through history in batches until everything is attributed or the history runs out.
+	before, walked := latest.meta.Version, 0
+	for !walk.done() {
+		batch, err := storeBlameSources(req.Context(), db, cfgID, before, blameBatchSize)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, v := range batch {
+			if walk.done() {
+				break
+			}
+			if walked == maxBlameVersions {
+				writeError(w, http.StatusUnprocessableEntity, "history_too_long",
+					"blame needs more than the allowed number of older versions", map[string]any{"max_versions": maxBlameVersions})
+				return
+			}
+			walk.step(v.blameSource)
+			walked++
+		}
+		before = batch[len(batch)-1].meta.Version
+	}
+	walk.finish()
+
+	cfg.LatestVersionID = ptr(uuidToString(latest.id))
+	resp := BlameResponse{
+		Config:  cfg,
+		Version: latest.meta.Version,
+		Keys:    walk.keys(),
+	}
+	if withLines {
+		resp.Lines = walk.blameLines()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+const (
+	// maxBlameVersions bounds how many older versions one blame may compare; histories that leave keys or lines
+	// unattributed past that depth are refused.
+	maxBlameVersions = 1000
+	blameBatchSize   = 50
+)
+
+type blameRow struct {
+	blameSource
+	id pgtype.UUID
+}
+
+// storeBlameSources loads up to limit versions of a config newest first, only those older than before when before > 0.
+func storeBlameSources(ctx context.Context, q querier, cfgID pgtype.UUID, before, limit int) ([]blameRow, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, version, created_at, created_by, comment, body_raw, body_json
+		FROM config_versions
+		WHERE config_id = $1 AND ($2 = 0 OR version < $2)
+		ORDER BY version DESC
+		LIMIT $3
+	`, cfgID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]blameRow, 0, limit)
+	for rows.Next() {
+		var r blameRow
+		var createdBy, comment sql.NullString
+		if err := rows.Scan(&r.id, &r.meta.Version, &r.meta.CreatedAt, &createdBy, &comment, &r.bodyRaw, &r.bodyJSON); err != nil {
+			return nil, err
+		}
+		if createdBy.Valid {
+			r.meta.CreatedBy = &createdBy.String
+		}
+		if comment.Valid {
+			r.meta.Comment = &comment.String
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,212 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxChangesetBodyBytes allows a changeset to carry several full config bodies.
+const maxChangesetBodyBytes = int64(20 << 20) // 20 MiB
+
+// handleCreateChangeset applies creates/updates/deletes of several configs in one transaction.
+func handleCreateChangeset(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	var body CreateChangesetRequest
+	if err := decodeJSONBody(w, req, &body, maxChangesetBodyBytes); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	items := changesetItemsFromRequest(body.Items)
+	if err := normalizeChangesetItems(items); err != nil {
+		writeHTTPError(w, err, "invalid changeset")
+		return
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	tx, err := beginEventTx(req.Context(), db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	cs, err := applyChangeset(req.Context(), db, tx, changesetInput{
+		CreatedBy:    body.CreatedBy,
+		Comment:      body.Comment,
+		RequestID:    reqID,
+		UserAgent:    userAgent,
+		SourceIP:     sourceIP,
+		LockOverride: lockOverride(req),
+		Items:        items,
+	})
+	if err != nil {
+		writeHTTPError(w, err, "apply changeset failed")
+		return
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	notifyChangeset(cs)
+	writeJSON(w, http.StatusCreated, cs)
+}
+
+func handleListChangesets(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	limit, err := parseLimit(req, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	offset, err := parseCursorOffset(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	namespace := strings.TrimSpace(req.URL.Query().Get("namespace"))
+
+	rows, err := db.Query(req.Context(), `
+		SELECT `+changesetMetaColumns+`
+		FROM changesets cs
+		WHERE ($1 = '' OR EXISTS (
+			SELECT 1 FROM changeset_items i WHERE i.changeset_id = cs.id AND i.namespace = $1
+		))
+		ORDER BY cs.created_at DESC, cs.id DESC
+		LIMIT $2 OFFSET $3
+	`, namespace, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	defer rows.Close()
+
+	items := make([]ChangesetMeta, 0, limit)
+	for rows.Next() {
+		m, err := scanChangesetMeta(rows)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "scan failed", nil)
+			return
+		}
+		items = append(items, m)
+	}
+
+	var next *string
+	if len(items) == limit {
+		c := encodeCursorOffset(offset + limit)
+		next = &c
+	}
+	writeJSON(w, http.StatusOK, ChangesetListResponse{Items: items, NextCursor: next})
+}
+
+func handleGetChangeset(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	id, ok := getChangesetID(w, req)
+	if !ok {
+		return
+	}
+	cs, err := storeGetChangeset(req.Context(), db, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "changeset not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, cs)
+}
+
+// handleRevertChangeset applies a new changeset that undoes every item of an earlier one.
+// It fails with 409 if any affected config changed after the changeset, or if it was already reverted.
+func handleRevertChangeset(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	id, ok := getChangesetID(w, req)
+	if !ok {
+		return
+	}
+	var body RevertChangesetRequest
+	if req.ContentLength != 0 {
+		if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+			return
+		}
+	}
+
+	tx, err := beginEventTx(req.Context(), db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	// Lock the changeset so concurrent reverts of it serialize.
+	var locked pgtype.UUID
+	err = tx.QueryRow(req.Context(), `SELECT id FROM changesets WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "changeset not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	revertedBy, err := storeChangesetRevertedBy(req.Context(), tx, id)
+	if err == nil {
+		writeError(w, http.StatusConflict, "conflict", "changeset was already reverted", map[string]any{"reverted_by": revertedBy})
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	original, err := storeGetChangeset(req.Context(), tx, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	items, err := revertChangesetItems(req.Context(), tx, original)
+	if err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
+	comment := body.Comment
+	if comment == nil {
+		comment = ptr("Revert changeset " + original.ID)
+	}
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	cs, err := applyChangeset(req.Context(), db, tx, changesetInput{
+		CreatedBy:    body.CreatedBy,
+		Comment:      comment,
+		RevertsID:    id,
+		RequestID:    reqID,
+		UserAgent:    userAgent,
+		SourceIP:     sourceIP,
+		LockOverride: lockOverride(req),
+		Items:        items,
+	})
+	if err != nil {
+		writeHTTPError(w, err, "apply changeset failed")
+		return
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	notifyChangeset(cs)
+	writeJSON(w, http.StatusCreated, cs)
+}
+
+func getChangesetID(w http.ResponseWriter, req *http.Request) (pgtype.UUID, bool) {
+	id, err := parseUUID(chi.URLParam(req, "changeset"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "changeset must be a UUID", nil)
+		return pgtype.UUID{}, false
+	}
+	return id, true
+}
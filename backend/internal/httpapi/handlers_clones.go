@@ -0,0 +1,415 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	cloneHistoryLatest = "latest"
+	cloneHistoryFull   = "full"
+
+	cloneOnConflictFail      = "fail"
+	cloneOnConflictSkip      = "skip"
+	cloneOnConflictOverwrite = "overwrite"
+
+	cloneActionCreate    = "create"
+	cloneActionOverwrite = "overwrite"
+	cloneActionSkip      = "skip"
+
+	maxCloneConfigs = 1000
+)
+
+// clonePlan is what cloning one source config will do.
+type clonePlan struct {
+	item     CloneItem
+	format   ConfigFormat
+	versions []versionBody
+	targetID pgtype.UUID // existing destination (overwrite)
+	latest   int         // its latest version (overwrite)
+}
+
+// cloneWrite carries the request-level fields of a clone.
+type cloneWrite struct {
+	FromNamespace string
+	ToNamespace   string
+	History       string
+	CreatedBy     *string
+	Comment       *string
+	Policy        NamespacePolicy // of ToNamespace
+	RequestID     *string
+	UserAgent     *string
+	SourceIP      net.IP
+}
+
+// handleCloneConfigs copies a config (path -> to_path) or a folder (prefix -> to_prefix) into another namespace or
+// location. The source is left untouched; destinations are new configs with their own ids.
+//
+//   - history=latest copies only the latest version (as version 1, authored by created_by);
+//     history=full copies every version with its number, author and comment.
+//   - on_conflict decides what happens when a destination already exists: fail (409, nothing is copied),
+//     skip, or overwrite (append the source latest as a new version).
+//   - dry_run returns the plan without writing.
+func handleCloneConfigs(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	var body CloneRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	namespace := strings.TrimSpace(body.Namespace)
+	if err := validateNamespace(namespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "namespace"})
+		return
+	}
+	toNamespace := strings.TrimSpace(body.ToNamespace)
+	if toNamespace == "" {
+		toNamespace = namespace
+	}
+	if err := validateNamespace(toNamespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "to_namespace"})
+		return
+	}
+	history := strings.TrimSpace(body.History)
+	if history == "" {
+		history = cloneHistoryLatest
+	}
+	if history != cloneHistoryLatest && history != cloneHistoryFull {
+		writeError(w, http.StatusBadRequest, "bad_request", "history must be one of: latest, full", map[string]any{"field": "history"})
+		return
+	}
+	onConflict := strings.TrimSpace(body.OnConflict)
+	if onConflict == "" {
+		onConflict = cloneOnConflictFail
+	}
+	if onConflict != cloneOnConflictFail && onConflict != cloneOnConflictSkip && onConflict != cloneOnConflictOverwrite {
+		writeError(w, http.StatusBadRequest, "bad_request", "on_conflict must be one of: fail, skip, overwrite", map[string]any{"field": "on_conflict"})
+		return
+	}
+
+	mapping, err := newPathMapping(body.Path, body.Prefix, body.ToPath, body.ToPrefix)
+	if err == nil {
+		err = mapping.checkDistinct(toNamespace == namespace)
+	}
+	if err != nil {
+		writeHTTPError(w, err, "invalid request")
+		return
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	tx, err := beginEventTx(req.Context(), db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	policy, err := storeGetNamespacePolicy(req.Context(), tx, toNamespace)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "to_namespace not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	selected, err := storeSelectConfigs(req.Context(), tx, namespace, mapping, false)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if len(selected) == 0 {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if len(selected) > maxCloneConfigs {
+		writeError(w, http.StatusBadRequest, "bad_request", "too many configs to clone in one request", map[string]any{"count": len(selected), "max": maxCloneConfigs})
+		return
+	}
+
+	// Plan every item before writing anything, so on_conflict=fail can report all conflicts at once.
+	plans := make([]clonePlan, 0, len(selected))
+	var conflicts []string
+	for _, src := range selected {
+		to, err := mapping.target(src.Path)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", "invalid destination path: "+err.Error(), map[string]any{"path": src.Path})
+			return
+		}
+		versions, err := storeVersionBodies(req.Context(), tx, src.ID, history == cloneHistoryLatest)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		p := clonePlan{
+			item:     CloneItem{FromPath: src.Path, ToPath: to, Action: cloneActionCreate, Versions: len(versions)},
+			versions: versions,
+			format:   src.Format,
+		}
+
+		target, targetID, err := storeLockConfig(req.Context(), tx, toNamespace, to)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			// Same rule as POST: namespaces that require approvals only change through published drafts.
+			if err := checkDirectWrite(policy, changeActionCreate); err != nil {
+				var he *httpError
+				if errors.As(err, &he) {
+					he.Details["path"] = to
+				}
+				writeHTTPError(w, err, "query failed")
+				return
+			}
+			p.item.Version = ptr(versions[len(versions)-1].Version)
+			if history == cloneHistoryLatest {
+				p.item.Version = ptr(1)
+			}
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		default:
+			p.item.ConfigID = ptr(target.ID)
+			p.targetID = targetID
+			switch onConflict {
+			case cloneOnConflictFail:
+				conflicts = append(conflicts, to)
+			case cloneOnConflictSkip:
+				p.item.Action, p.item.Reason, p.item.Versions = cloneActionSkip, "exists", 0
+			case cloneOnConflictOverwrite:
+				if target.Format != src.Format {
+					writeError(w, http.StatusConflict, "conflict", "destination has a different format", map[string]any{
+						"path": to, "format": target.Format, "source_format": src.Format,
+					})
+					return
+				}
+				if p.latest, err = storeLatestVersionNumber(req.Context(), tx, targetID); err != nil {
+					writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+					return
+				}
+				latestSHA, err := storeVersionContentSHA(req.Context(), tx, targetID, p.latest)
+				if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+					writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+					return
+				}
+				if latestSHA == versions[len(versions)-1].SHA256 {
+					p.item.Action, p.item.Reason, p.item.Versions = cloneActionSkip, "unchanged", 0
+					p.item.Version = ptr(p.latest)
+					break
+				}
+				// Same rule as PUT: namespaces that require approvals only accept updates through published drafts.
+				if err := checkDirectWrite(policy, changeActionUpdate); err != nil {
+					var he *httpError
+					if errors.As(err, &he) {
+						he.Details["path"] = to
+					}
+					writeHTTPError(w, err, "query failed")
+					return
+				}
+				p.item.Action, p.item.Versions = cloneActionOverwrite, 1
+				p.item.Version = ptr(p.latest + 1)
+			}
+		}
+		plans = append(plans, p)
+	}
+	if len(conflicts) > 0 {
+		writeError(w, http.StatusConflict, "conflict", "destination already exists", map[string]any{"paths": conflicts})
+		return
+	}
+	// Full-history creates keep the original comments; everything else writes a new version that must satisfy the
+	// destination comment policy.
+	for _, p := range plans {
+		if p.item.Action == cloneActionOverwrite || (p.item.Action == cloneActionCreate && history == cloneHistoryLatest) {
+			if _, err := checkComment(policy, p.comment(namespace, body.Comment)); err != nil {
+				writeHTTPError(w, err, "query failed")
+				return
+			}
+		}
+	}
+
+	if !body.DryRun {
+		var writes []string
+		for _, p := range plans {
+			if p.item.Action != cloneActionSkip {
+				writes = append(writes, p.item.ToPath)
+			}
+		}
+		if err := checkLocks(req.Context(), tx, toNamespace, writes, lockOverride(req)); err != nil {
+			writeHTTPError(w, err, "query failed")
+			return
+		}
+	}
+
+	resp := CloneResponse{
+		DryRun:      body.DryRun,
+		Namespace:   namespace,
+		ToNamespace: toNamespace,
+		History:     history,
+		OnConflict:  onConflict,
+		Items:       make([]CloneItem, 0, len(plans)),
+	}
+	for i := range plans {
+		p := &plans[i]
+		if !body.DryRun {
+			warnings, err := p.apply(req.Context(), db, tx, cloneWrite{
+				FromNamespace: namespace,
+				ToNamespace:   toNamespace,
+				History:       history,
+				CreatedBy:     body.CreatedBy,
+				Comment:       body.Comment,
+				Policy:        policy,
+				RequestID:     reqID,
+				UserAgent:     userAgent,
+				SourceIP:      sourceIP,
+			})
+			if err != nil {
+				writeHTTPError(w, err, "clone failed")
+				return
+			}
+			for _, msg := range warnings {
+				resp.Warnings = append(resp.Warnings, toNamespace+"/"+p.item.ToPath+": "+msg)
+			}
+		}
+		switch p.item.Action {
+		case cloneActionCreate:
+			resp.Created++
+		case cloneActionOverwrite:
+			resp.Overwritten++
+		default:
+			resp.Skipped++
+		}
+		resp.Items = append(resp.Items, p.item)
+	}
+
+	if body.DryRun {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	for _, it := range resp.Items {
+		if it.Action != cloneActionSkip {
+			configChanges.notify(toNamespace, it.ToPath)
+		}
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// comment returns the comment of a version written from the source latest: the request comment, or a note
+// pointing at the source.
+func (p *clonePlan) comment(fromNamespace string, comment *string) *string {
+	if comment != nil {
+		return comment
+	}
+	src := p.versions[len(p.versions)-1]
+	return ptr(fmt.Sprintf("Cloned from %s/%s@v%d", fromNamespace, p.item.FromPath, src.Version))
+}
+
+// apply writes the plan and fills in the resulting config id and version. The new latest body goes through the
+// admission webhooks of the destination first; their warnings are returned.
+func (p *clonePlan) apply(ctx context.Context, db *pgxpool.Pool, tx *eventTx, in cloneWrite) ([]string, error) {
+	comment := p.comment(in.FromNamespace, in.Comment)
+	latest := p.versions[len(p.versions)-1]
+	review := admissionReview{
+		Namespace: in.ToNamespace,
+		Path:      p.item.ToPath,
+		Format:    p.format,
+		New:       admissionObject{BodyRaw: latest.BodyRaw, BodyJSON: latest.BodyJSON},
+		Author:    in.CreatedBy,
+		Comment:   comment,
+		RequestID: in.RequestID,
+		userAgent: in.UserAgent,
+		sourceIP:  in.SourceIP,
+	}
+	// last is the version that ends up latest; one event is recorded for it.
+	var last versionInput
+	version := func(cfgID pgtype.UUID, number int, b versionBody, createdBy, comment *string) error {
+		last = versionInput{
+			ConfigID:   cfgID,
+			Version:    number,
+			BodyRaw:    b.BodyRaw,
+			BodyJSON:   b.BodyJSON,
+			CreatedBy:  createdBy,
+			Comment:    comment,
+			SHA256:     b.SHA256,
+			TicketRefs: extractTicketRefs(in.Policy.TicketPattern, comment),
+			RequestID:  in.RequestID,
+			UserAgent:  in.UserAgent,
+			SourceIP:   in.SourceIP,
+		}
+		_, _, err := storeInsertVersion(ctx, tx, last)
+		return err
+	}
+
+	switch p.item.Action {
+	case cloneActionCreate:
+		review.Operation, review.New.Version = admissionOpCreate, 1
+		if in.History == cloneHistoryFull {
+			review.New.Version, review.Author, review.Comment = latest.Version, latest.CreatedBy, latest.Comment
+		}
+		var cfgID pgtype.UUID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO configs (namespace, path, format)
+			VALUES ($1, $2, $3)
+			RETURNING id
+		`, in.ToNamespace, p.item.ToPath, string(p.format)).Scan(&cfgID)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return nil, &httpError{Status: http.StatusConflict, Code: "conflict", Message: "destination already exists", Details: map[string]any{"paths": []string{p.item.ToPath}}}
+			}
+			return nil, err
+		}
+		warnings, err := admitClone(ctx, db, tx, review)
+		if err != nil {
+			return nil, err
+		}
+		if in.History == cloneHistoryFull {
+			// Keep version numbers, authors and comments so history reads the same in both places.
+			for _, b := range p.versions {
+				if err := version(cfgID, b.Version, b, b.CreatedBy, b.Comment); err != nil {
+					return nil, err
+				}
+			}
+		} else if err := version(cfgID, 1, p.versions[0], in.CreatedBy, comment); err != nil {
+			return nil, err
+		}
+		p.item.ConfigID = ptr(uuidToString(cfgID))
+		return warnings, storeInsertEvent(ctx, tx, versionEvent(eventConfigCreated, in.ToNamespace, p.item.ToPath, last))
+	case cloneActionOverwrite:
+		review.Operation, review.New.Version = admissionOpUpdate, p.latest+1
+		review.configID, review.oldVersion = p.targetID, p.latest
+		warnings, err := admitClone(ctx, db, tx, review)
+		if err != nil {
+			return nil, err
+		}
+		if err := version(p.targetID, p.latest+1, latest, in.CreatedBy, comment); err != nil {
+			return nil, err
+		}
+		return warnings, storeInsertEvent(ctx, tx, versionEvent(eventConfigUpdated, in.ToNamespace, p.item.ToPath, last))
+	}
+	return nil, nil
+}
+
+// admitClone runs admit for one destination and names it in the details of a denial.
+func admitClone(ctx context.Context, db *pgxpool.Pool, tx *eventTx, review admissionReview) ([]string, error) {
+	warnings, err := admit(ctx, db, tx, review)
+	var he *httpError
+	if errors.As(err, &he) && he.Details != nil {
+		he.Details["path"] = review.Path
+	}
+	return warnings, err
+}
@@ -2,11 +2,11 @@ package httpapi
 
 import (
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
@@ -44,49 +44,57 @@ func handleListConfigs(w http.ResponseWriter, req *http.Request, db *pgxpool.Poo
 	if !hasRecursive {
 		recursive = true
 	}
+	asOf, err := parseAsOf(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
 
 	// List configs; folder-style browse is /namespaces/{namespace}/browse.
+	// With as_of, configs alive at that moment are listed with the version that was latest then.
 	var rows pgx.Rows
 	if recursive {
 		rows, err = db.Query(req.Context(), `
 			SELECT
 				c.id, c.namespace, c.path, c.format::text, c.created_at, c.updated_at,
 				lv.id, lv.version, lv.created_at, lv.created_by, lv.comment, lv.content_sha256
-			FROM configs c
+			FROM `+configSource("c", "$5", asOf != nil)+`
 			LEFT JOIN LATERAL (
 				SELECT id, version, created_at, created_by, comment, content_sha256
 				FROM config_versions
 				WHERE config_id = c.id
+				  AND ($5::timestamptz IS NULL OR created_at <= $5)
 				ORDER BY version DESC
 				LIMIT 1
 			) lv ON true
 			WHERE ($1 = '' OR c.namespace = $1)
 			  AND ($2 = '' OR c.path LIKE $2 || '%')
-			  AND c.deleted_at IS NULL
+			  AND lv.id IS NOT NULL
 			ORDER BY c.namespace ASC, c.path ASC
 			LIMIT $3 OFFSET $4
-		`, namespace, prefix, limit, offset)
+		`, namespace, prefix, limit, offset, asOf)
 	} else {
 		startIndex := len(prefix) + 1 // SQL substr is 1-based
 		rows, err = db.Query(req.Context(), `
 			SELECT
 				c.id, c.namespace, c.path, c.format::text, c.created_at, c.updated_at,
 				lv.id, lv.version, lv.created_at, lv.created_by, lv.comment, lv.content_sha256
-			FROM configs c
+			FROM `+configSource("c", "$6", asOf != nil)+`
 			LEFT JOIN LATERAL (
 				SELECT id, version, created_at, created_by, comment, content_sha256
 				FROM config_versions
 				WHERE config_id = c.id
+				  AND ($6::timestamptz IS NULL OR created_at <= $6)
 				ORDER BY version DESC
 				LIMIT 1
 			) lv ON true
 			WHERE ($1 = '' OR c.namespace = $1)
 			  AND ($2 = '' OR c.path LIKE $2 || '%')
-			  AND c.deleted_at IS NULL
+			  AND lv.id IS NOT NULL
 			  AND position('/' in substr(c.path, $3)) = 0
 			ORDER BY c.namespace ASC, c.path ASC
 			LIMIT $4 OFFSET $5
-		`, namespace, prefix, startIndex, limit, offset)
+		`, namespace, prefix, startIndex, limit, offset, asOf)
 	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
@@ -132,7 +140,7 @@ func handleListConfigs(w http.ResponseWriter, req *http.Request, db *pgxpool.Poo
 		next = &c
 	}
 
-	writeJSON(w, http.StatusOK, ConfigListResponse{Items: items, NextCursor: next})
+	writeJSON(w, http.StatusOK, ConfigListResponse{Items: items, NextCursor: next, AsOf: asOf})
 }
 
 func handleGetLatestConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
@@ -141,8 +149,102 @@ func handleGetLatestConfig(w http.ResponseWriter, req *http.Request, db *pgxpool
 		return
 	}
 
+	asOf, err := parseAsOf(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	tag := strings.TrimSpace(req.URL.Query().Get("tag"))
+	release := strings.TrimSpace(req.URL.Query().Get("release"))
+	selectors := 0
+	for _, set := range []bool{tag != "", asOf != nil, release != ""} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors > 1 {
+		writeError(w, http.StatusBadRequest, "bad_request", "tag, as_of and release cannot be combined", nil)
+		return
+	}
+	wait, watch, err := parseWait(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "wait"})
+		return
+	}
+	if watch {
+		if selectors > 0 {
+			writeError(w, http.StatusBadRequest, "bad_request", "wait cannot be combined with tag, as_of or release", nil)
+			return
+		}
+		handleWatchConfig(w, req, db, namespace, path, wait)
+		return
+	}
+	if release != "" {
+		if err := validateReleaseName(release); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+			return
+		}
+		handleGetReleasedConfig(w, req, db, namespace, path, release)
+		return
+	}
+	if asOf != nil {
+		handleGetConfigAsOf(w, req, db, namespace, path, *asOf)
+		return
+	}
+	if tag != "" {
+		if err := validateTagName(tag); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+			return
+		}
+		handleGetTaggedConfig(w, req, db, namespace, path, tag)
+		return
+	}
+
 	cfg, ver, err := storeGetConfigAndLatest(req.Context(), db, namespace, path)
 	if errors.Is(err, pgx.ErrNoRows) {
+		if redirectConfigAlias(w, req, db, namespace, path) {
+			return
+		}
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	locks, err := storeFindLocks(req.Context(), db, namespace, []string{path}, maxListedLocks)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	writeJSONWithVersionETag(w, req, http.StatusOK, GetConfigResponse{Config: cfg, Latest: ver, Locks: locks}, configVersionETag(ver))
+}
+
+// handleGetConfigAsOf serves GET /configs/{namespace}/{path}?as_of=... with the version that was latest at that time.
+func handleGetConfigAsOf(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace, path string, asOf time.Time) {
+	cfg, ver, err := storeGetConfigAsOf(req.Context(), db, namespace, path, asOf)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found as of the given time", map[string]any{"as_of": asOf})
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	writeJSONWithVersionETag(w, req, http.StatusOK, GetConfigResponse{Config: cfg, Latest: ver, AsOf: &asOf}, configVersionETag(ver))
+}
+
+// handleGetTaggedConfig serves GET /configs/{namespace}/{path}?tag=... with the tagged version in place of latest.
+func handleGetTaggedConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace, path, tag string) {
+	cfg, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if redirectConfigAlias(w, req, db, namespace, path) {
+			return
+		}
 		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
 		return
 	}
@@ -151,7 +253,28 @@ func handleGetLatestConfig(w http.ResponseWriter, req *http.Request, db *pgxpool
 		return
 	}
 
-	writeJSON(w, http.StatusOK, GetConfigResponse{Config: cfg, Latest: ver})
+	ver, err := storeGetTaggedVersion(req.Context(), db, cfgID, tag)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "tag not found", map[string]any{"tag": tag})
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	latest, err := storeGetLatestVersion(req.Context(), db, cfgID)
+	if err == nil {
+		cfg.LatestVersionID = ptr(latest.ID)
+	}
+
+	locks, err := storeFindLocks(req.Context(), db, namespace, []string{path}, maxListedLocks)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	writeJSONWithVersionETag(w, req, http.StatusOK, GetConfigResponse{Config: cfg, Latest: ver, Locks: locks}, configVersionETag(ver))
 }
 
 func handleCreateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
@@ -160,12 +283,7 @@ func handleCreateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 		return
 	}
 
-	var body struct {
-		Format    ConfigFormat `json:"format"`
-		BodyRaw   string       `json:"body_raw"`
-		Comment   *string      `json:"comment"`
-		CreatedBy *string      `json:"created_by"`
-	}
+	var body CreateConfigRequest
 	if err := decodeJSONBody(w, req, &body, maxConfigBodyBytes); err != nil {
 		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
 		return
@@ -187,17 +305,26 @@ func handleCreateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 	sha := sha256Hex(body.BodyRaw)
 	reqID, userAgent, sourceIP := requestAuditFields(req)
 
-	nsOK, err := storeNamespaceExists(req.Context(), db, namespace)
+	policy, err := storeGetNamespacePolicy(req.Context(), db, namespace)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
 		return
 	}
-	if !nsOK {
-		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+	if err := checkDirectWrite(policy, changeActionCreate); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+	ticketRefs, err := checkComment(policy, body.Comment)
+	if err != nil {
+		writeHTTPError(w, err, "query failed")
 		return
 	}
 
-	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	tx, err := beginEventTx(req.Context(), db)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
 		return
@@ -205,9 +332,13 @@ func handleCreateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 	defer tx.Rollback(req.Context())
 
 	var cfgID pgtype.UUID
-	var latestVersionID pgtype.UUID
 	var createdAt, updatedAt pgtype.Timestamptz
 
+	if err := checkLocks(req.Context(), tx, namespace, []string{path}, lockOverride(req)); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
 	// Insert config (namespace must exist; FK enforces).
 	err = tx.QueryRow(req.Context(), `
 		INSERT INTO configs (namespace, path, format)
@@ -230,22 +361,41 @@ func handleCreateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 		return
 	}
 
-	// Insert version 1
-	var versionCreatedAt pgtype.Timestamptz
-	err = tx.QueryRow(req.Context(), `
-		INSERT INTO config_versions (config_id, version, body_raw, body_json, created_by, comment, content_sha256, request_id, user_agent, source_ip)
-		VALUES ($1, 1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, created_at
-	`, cfgID, body.BodyRaw, json.RawMessage(parsedJSON), body.CreatedBy, body.Comment, sha, reqID, userAgent, sourceIP).Scan(&latestVersionID, &versionCreatedAt)
+	warnings, err := admit(req.Context(), db, tx, admissionReview{
+		Operation: admissionOpCreate,
+		Namespace: namespace,
+		Path:      path,
+		Format:    body.Format,
+		New:       admissionObject{Version: 1, BodyRaw: body.BodyRaw, BodyJSON: parsedJSON},
+		Author:    body.CreatedBy,
+		Comment:   body.Comment,
+	}.withRequest(req))
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal_error", "insert version failed", nil)
+		writeHTTPError(w, err, "admission failed")
 		return
 	}
 
-	// Update latest pointer
-	_, err = tx.Exec(req.Context(), `UPDATE configs SET latest_version_id = $1 WHERE id = $2`, latestVersionID, cfgID)
+	// Insert version 1 and point latest at it.
+	in := versionInput{
+		ConfigID:   cfgID,
+		Version:    1,
+		BodyRaw:    body.BodyRaw,
+		BodyJSON:   parsedJSON,
+		CreatedBy:  body.CreatedBy,
+		Comment:    body.Comment,
+		SHA256:     sha,
+		TicketRefs: ticketRefs,
+		RequestID:  reqID,
+		UserAgent:  userAgent,
+		SourceIP:   sourceIP,
+	}
+	latestVersionID, versionCreatedAt, err := storeInsertVersion(req.Context(), tx, in)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal_error", "update latest failed", nil)
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert version failed", nil)
+		return
+	}
+	if err := storeInsertEvent(req.Context(), tx, versionEvent(eventConfigCreated, namespace, path, in)); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
 		return
 	}
 
@@ -253,6 +403,7 @@ func handleCreateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
 		return
 	}
+	configChanges.notify(namespace, path)
 
 	cfg := Config{
 		ID:              uuidToString(cfgID),
@@ -266,14 +417,16 @@ func handleCreateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 	ver := ConfigVersion{
 		ID:            uuidToString(latestVersionID),
 		Version:       1,
-		CreatedAt:     versionCreatedAt.Time,
+		CreatedAt:     versionCreatedAt,
 		CreatedBy:     body.CreatedBy,
 		Comment:       body.Comment,
 		ContentSHA256: ptr(sha),
+		TicketRefs:    ticketRefs,
 		BodyRaw:       body.BodyRaw,
 		BodyJSON:      parsedAny,
 	}
-	writeJSON(w, http.StatusCreated, GetConfigResponse{Config: cfg, Latest: ver})
+	w.Header().Set("ETag", configVersionETag(ver))
+	writeJSON(w, http.StatusCreated, GetConfigResponse{Config: cfg, Latest: ver, Warnings: warnings})
 }
 
 func handleUpdateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
@@ -282,12 +435,7 @@ func handleUpdateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 		return
 	}
 
-	var body struct {
-		BodyRaw     string  `json:"body_raw"`
-		Comment     *string `json:"comment"`
-		CreatedBy   *string `json:"created_by"`
-		BaseVersion *int    `json:"base_version"`
-	}
+	var body UpdateConfigRequest
 	if err := decodeJSONBody(w, req, &body, maxConfigBodyBytes); err != nil {
 		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
 		return
@@ -296,8 +444,32 @@ func handleUpdateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 		writeError(w, http.StatusBadRequest, "bad_request", "body_raw is required", map[string]any{"field": "body_raw"})
 		return
 	}
+	if body.Merge && body.BaseVersion == nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "merge requires base_version", map[string]any{"field": "base_version"})
+		return
+	}
 
-	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	// Namespaces that require approvals only accept new versions through published drafts.
+	policy, err := storeGetNamespacePolicy(req.Context(), db, namespace)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if err := checkDirectWrite(policy, changeActionUpdate); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+	ticketRefs, err := checkComment(policy, body.Comment)
+	if err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
+	tx, err := beginEventTx(req.Context(), db)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
 		return
@@ -305,16 +477,7 @@ func handleUpdateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 	defer tx.Rollback(req.Context())
 
 	// Lock config row to ensure version increments safely.
-	var cfgID, latestVersionID pgtype.UUID
-	var cfg Config
-	var fmtStr string
-	err = tx.QueryRow(req.Context(), `
-		SELECT c.id, c.namespace, c.path, c.format::text, c.latest_version_id, c.created_at, c.updated_at
-		FROM configs c
-		WHERE c.namespace = $1 AND c.path = $2
-		  AND c.deleted_at IS NULL
-		FOR UPDATE
-	`, namespace, path).Scan(&cfgID, &cfg.Namespace, &cfg.Path, &fmtStr, &latestVersionID, &cfg.CreatedAt, &cfg.UpdatedAt)
+	cfg, cfgID, err := storeLockConfig(req.Context(), tx, namespace, path)
 	if errors.Is(err, pgx.ErrNoRows) {
 		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
 		return
@@ -323,47 +486,44 @@ func handleUpdateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
 		return
 	}
-	cfg.ID = uuidToString(cfgID)
-	cfg.Format = ConfigFormat(fmtStr)
-	if latestVersionID.Valid {
-		s := uuidToString(latestVersionID)
-		cfg.LatestVersionID = &s
+	if err := checkLocks(req.Context(), tx, namespace, []string{path}, lockOverride(req)); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
 	}
 
 	// Latest is strictly the max(version).
-	var currentLatestNumber int
-	if err := tx.QueryRow(req.Context(), `SELECT COALESCE(MAX(version), 0) FROM config_versions WHERE config_id = $1`, cfgID).Scan(&currentLatestNumber); err != nil {
+	currentLatestNumber, err := storeLatestVersionNumber(req.Context(), tx, cfgID)
+	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
 		return
 	}
+	if !checkIfMatchVersion(w, req, tx, cfgID, currentLatestNumber) {
+		return
+	}
 
+	var mergeResult *MergeResult
 	if body.BaseVersion != nil && *body.BaseVersion != currentLatestNumber {
-		writeError(w, http.StatusConflict, "conflict", "base_version does not match current latest", map[string]any{
-			"base_version":    *body.BaseVersion,
-			"current_version": currentLatestNumber,
-		})
-		return
+		if !body.Merge || *body.BaseVersion > currentLatestNumber {
+			writeError(w, http.StatusConflict, "conflict", "base_version does not match current latest", map[string]any{
+				"base_version":    *body.BaseVersion,
+				"current_version": currentLatestNumber,
+			})
+			return
+		}
+		merged, ok := mergeUpdate(w, req, tx, cfg.Format, cfgID, *body.BaseVersion, currentLatestNumber, body.BodyRaw)
+		if !ok {
+			return
+		}
+		body.BodyRaw = merged
+		mergeResult = &MergeResult{BaseVersion: *body.BaseVersion, CurrentVersion: currentLatestNumber}
 	}
 
 	// No-op guard: if submitted body matches current latest exactly, do not create a new version.
 	// This keeps version history meaningful and prevents accidental duplicate versions.
 	sha := sha256Hex(body.BodyRaw)
 	if currentLatestNumber > 0 {
-		var latestSHA sql.NullString
-		var latestBodyRaw string
-		err := tx.QueryRow(req.Context(), `
-			SELECT content_sha256, body_raw
-			FROM config_versions
-			WHERE config_id = $1 AND version = $2
-		`, cfgID, currentLatestNumber).Scan(&latestSHA, &latestBodyRaw)
+		latest, err := storeVersionContentSHA(req.Context(), tx, cfgID, currentLatestNumber)
 		if err == nil {
-			latest := ""
-			if latestSHA.Valid {
-				latest = latestSHA.String
-			}
-			if latest == "" {
-				latest = sha256Hex(latestBodyRaw)
-			}
 			if latest == sha {
 				writeError(w, http.StatusConflict, "no_change", "body_raw matches current latest", map[string]any{
 					"current_version": currentLatestNumber,
@@ -384,20 +544,41 @@ func handleUpdateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 	reqID, userAgent, sourceIP := requestAuditFields(req)
 
 	nextVersion := currentLatestNumber + 1
-	var newVerID pgtype.UUID
-	var createdAt pgtype.Timestamptz
-	err = tx.QueryRow(req.Context(), `
-		INSERT INTO config_versions (config_id, version, body_raw, body_json, created_by, comment, content_sha256, request_id, user_agent, source_ip)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id, created_at
-	`, cfgID, nextVersion, body.BodyRaw, json.RawMessage(parsedJSON), body.CreatedBy, body.Comment, sha, reqID, userAgent, sourceIP).Scan(&newVerID, &createdAt)
+	warnings, err := admit(req.Context(), db, tx, admissionReview{
+		Operation:  admissionOpUpdate,
+		Namespace:  namespace,
+		Path:       path,
+		Format:     cfg.Format,
+		New:        admissionObject{Version: nextVersion, BodyRaw: body.BodyRaw, BodyJSON: parsedJSON},
+		Author:     body.CreatedBy,
+		Comment:    body.Comment,
+		configID:   cfgID,
+		oldVersion: currentLatestNumber,
+	}.withRequest(req))
+	if err != nil {
+		writeHTTPError(w, err, "admission failed")
+		return
+	}
+	in := versionInput{
+		ConfigID:   cfgID,
+		Version:    nextVersion,
+		BodyRaw:    body.BodyRaw,
+		BodyJSON:   parsedJSON,
+		CreatedBy:  body.CreatedBy,
+		Comment:    body.Comment,
+		SHA256:     sha,
+		TicketRefs: ticketRefs,
+		RequestID:  reqID,
+		UserAgent:  userAgent,
+		SourceIP:   sourceIP,
+	}
+	newVerID, createdAt, err := storeInsertVersion(req.Context(), tx, in)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "insert version failed", nil)
 		return
 	}
-	_, err = tx.Exec(req.Context(), `UPDATE configs SET latest_version_id = $1 WHERE id = $2`, newVerID, cfgID)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal_error", "update latest failed", nil)
+	if err := storeInsertEvent(req.Context(), tx, versionEvent(eventConfigUpdated, namespace, path, in)); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
 		return
 	}
 
@@ -405,19 +586,94 @@ func handleUpdateConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
 		return
 	}
+	configChanges.notify(namespace, path)
 
 	cfg.LatestVersionID = ptr(uuidToString(newVerID))
 	ver := ConfigVersion{
 		ID:            uuidToString(newVerID),
 		Version:       nextVersion,
-		CreatedAt:     createdAt.Time,
+		CreatedAt:     createdAt,
 		CreatedBy:     body.CreatedBy,
 		Comment:       body.Comment,
 		ContentSHA256: ptr(sha),
+		TicketRefs:    ticketRefs,
 		BodyRaw:       body.BodyRaw,
 		BodyJSON:      parsedAny,
 	}
-	writeJSON(w, http.StatusOK, GetConfigResponse{Config: cfg, Latest: ver})
+	w.Header().Set("ETag", configVersionETag(ver))
+	writeJSON(w, http.StatusOK, GetConfigResponse{Config: cfg, Latest: ver, Merge: mergeResult, Warnings: warnings})
+}
+
+// mergeUpdate three-way merges submitted body_raw onto the current latest, using baseVersion as the common ancestor.
+// It returns the merged body_raw, or writes an error response (409 merge_conflict on overlapping changes) and returns false.
+func mergeUpdate(w http.ResponseWriter, req *http.Request, tx pgx.Tx, format ConfigFormat, cfgID pgtype.UUID, baseVersion, currentVersion int, submittedRaw string) (string, bool) {
+	baseJSON, err := storeVersionBodyJSON(req.Context(), tx, cfgID, baseVersion)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusConflict, "conflict", "base_version no longer exists; cannot merge", map[string]any{
+			"base_version":    baseVersion,
+			"current_version": currentVersion,
+		})
+		return "", false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return "", false
+	}
+	currentJSON, err := storeVersionBodyJSON(req.Context(), tx, cfgID, currentVersion)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return "", false
+	}
+	_, submittedJSON, err := parseBody(format, submittedRaw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return "", false
+	}
+
+	base, baseOK := decodeJSONNumbers(baseJSON)
+	current, currentOK := decodeJSONNumbers(currentJSON)
+	submitted, _ := decodeJSONNumbers(submittedJSON)
+	if !baseOK || !currentOK {
+		writeError(w, http.StatusConflict, "conflict", "versions have no parsed body; cannot merge", map[string]any{
+			"base_version":    baseVersion,
+			"current_version": currentVersion,
+		})
+		return "", false
+	}
+
+	merged, conflicts := mergeJSON(base, current, submitted)
+	if len(conflicts) > 0 {
+		writeError(w, http.StatusConflict, "merge_conflict", "submitted changes conflict with changes made since base_version", map[string]any{
+			"base_version":    baseVersion,
+			"current_version": currentVersion,
+			"conflicts":       conflicts,
+		})
+		return "", false
+	}
+	if jsonEqual(merged, current) {
+		writeError(w, http.StatusConflict, "no_change", "merged body matches current latest", map[string]any{
+			"current_version": currentVersion,
+		})
+		return "", false
+	}
+	currentVer, err := storeGetVersion(req.Context(), tx, cfgID, currentVersion)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return "", false
+	}
+	mergedRaw, err := renderBody(format, currentVer.BodyRaw, submittedRaw, merged)
+	if errors.Is(err, errMergeUnsupported) {
+		writeError(w, http.StatusConflict, "merge_unsupported", err.Error(), map[string]any{
+			"base_version":    baseVersion,
+			"current_version": currentVersion,
+		})
+		return "", false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "render merged body failed", nil)
+		return "", false
+	}
+	return mergedRaw, true
 }
 
 func handleListConfigVersions(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
@@ -438,6 +694,9 @@ func handleListConfigVersions(w http.ResponseWriter, req *http.Request, db *pgxp
 
 	_, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
 	if errors.Is(err, pgx.ErrNoRows) {
+		if redirectConfigAlias(w, req, db, namespace, path) {
+			return
+		}
 		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
 		return
 	}
@@ -447,7 +706,7 @@ func handleListConfigVersions(w http.ResponseWriter, req *http.Request, db *pgxp
 	}
 
 	rows, err := db.Query(req.Context(), `
-		SELECT id, version, created_at, created_by, comment, content_sha256
+		SELECT id, version, created_at, created_by, comment, content_sha256, changeset_id, ticket_refs
 		FROM config_versions
 		WHERE config_id = $1
 		ORDER BY version DESC
@@ -474,7 +733,7 @@ func handleListConfigVersions(w http.ResponseWriter, req *http.Request, db *pgxp
 		c := encodeCursorOffset(offset + limit)
 		next = &c
 	}
-	writeJSON(w, http.StatusOK, VersionListResponse{Items: items, NextCursor: next})
+	writeJSONWithBodyETag(w, req, http.StatusOK, VersionListResponse{Items: items, NextCursor: next})
 }
 
 func handleGetConfigVersion(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
@@ -487,6 +746,9 @@ func handleGetConfigVersion(w http.ResponseWriter, req *http.Request, db *pgxpoo
 
 	cfg, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
 	if errors.Is(err, pgx.ErrNoRows) {
+		if redirectConfigAlias(w, req, db, namespace, path) {
+			return
+		}
 		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
 		return
 	}
@@ -511,7 +773,7 @@ func handleGetConfigVersion(w http.ResponseWriter, req *http.Request, db *pgxpoo
 		cfg.LatestVersionID = ptr(latest.ID)
 	}
 
-	writeJSON(w, http.StatusOK, GetVersionResponse{Config: cfg, Version: ver})
+	writeJSONWithVersionETag(w, req, http.StatusOK, GetVersionResponse{Config: cfg, Version: ver}, configVersionETag(ver))
 }
 
 func handleDeleteConfigVersion(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
@@ -522,7 +784,7 @@ func handleDeleteConfigVersion(w http.ResponseWriter, req *http.Request, db *pgx
 	verNumStr := chi.URLParam(req, "version")
 	verNum, _ := strconv.Atoi(verNumStr)
 
-	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	tx, err := beginEventTx(req.Context(), db)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
 		return
@@ -547,17 +809,45 @@ func handleDeleteConfigVersion(w http.ResponseWriter, req *http.Request, db *pgx
 		return
 	}
 
+	if err := checkLocks(req.Context(), tx, namespace, []string{path}, lockOverride(req)); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
 	// Determine latest version number.
 	var latestNum int
 	if err := tx.QueryRow(req.Context(), `SELECT COALESCE(MAX(version), 0) FROM config_versions WHERE config_id = $1`, cfgID).Scan(&latestNum); err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
 		return
 	}
+	if !checkIfMatchVersion(w, req, tx, cfgID, verNum) {
+		return
+	}
 	if verNum == latestNum {
 		writeError(w, http.StatusConflict, "conflict", "cannot delete latest version", map[string]any{"latest_version": latestNum})
 		return
 	}
 
+	tags, err := storeTagsForVersion(req.Context(), tx, cfgID, verNum)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if len(tags) > 0 {
+		writeError(w, http.StatusConflict, "conflict", "cannot delete a tagged version", map[string]any{"tags": tags})
+		return
+	}
+
+	releases, err := storeReleasesForVersion(req.Context(), tx, cfgID, verNum)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if len(releases) > 0 {
+		writeError(w, http.StatusConflict, "conflict", "cannot delete a version included in a release", map[string]any{"releases": releases})
+		return
+	}
+
 	tag, err := tx.Exec(req.Context(), `
 		DELETE FROM config_versions
 		WHERE config_id = $1 AND version = $2
@@ -570,11 +860,19 @@ func handleDeleteConfigVersion(w http.ResponseWriter, req *http.Request, db *pgx
 		writeError(w, http.StatusNotFound, "not_found", "version not found", nil)
 		return
 	}
+	reqID, _, _ := requestAuditFields(req)
+	if err := storeInsertEvent(req.Context(), tx, eventInput{
+		Type: eventVersionDeleted, Namespace: namespace, Path: path, ConfigID: cfgID, Version: verNum, RequestID: reqID,
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
+		return
+	}
 
 	if err := tx.Commit(req.Context()); err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
 		return
 	}
+	configChanges.notify(namespace, path)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -585,7 +883,7 @@ func handleDeleteConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 		return
 	}
 
-	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	tx, err := beginEventTx(req.Context(), db)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
 		return
@@ -610,7 +908,33 @@ func handleDeleteConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 		return
 	}
 
-	tag, err := tx.Exec(req.Context(), `DELETE FROM configs WHERE id = $1`, cfgID)
+	if err := checkLocks(req.Context(), tx, namespace, []string{path}, lockOverride(req)); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+	policy, err := storeGetNamespacePolicy(req.Context(), tx, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if err := checkDirectWrite(policy, changeActionDelete); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
+	if req.Header.Get("If-Match") != "" {
+		latestNum, err := storeLatestVersionNumber(req.Context(), tx, cfgID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		if !checkIfMatchVersion(w, req, tx, cfgID, latestNum) {
+			return
+		}
+	}
+
+	// Soft delete: the tombstone keeps history readable via as_of and frees the (namespace, path) identity.
+	tag, err := tx.Exec(req.Context(), `UPDATE configs SET deleted_at = now() WHERE id = $1`, cfgID)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "delete failed", nil)
 		return
@@ -619,15 +943,36 @@ func handleDeleteConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Po
 		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
 		return
 	}
+	reqID, _, _ := requestAuditFields(req)
+	if err := storeInsertEvent(req.Context(), tx, eventInput{
+		Type: eventConfigDeleted, Namespace: namespace, Path: path, ConfigID: cfgID, RequestID: reqID,
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
+		return
+	}
 
 	if err := tx.Commit(req.Context()); err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
 		return
 	}
+	configChanges.notify(namespace, path)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// checkIfMatchVersion enforces If-Match against the ETag of the given version (see checkIfMatch).
+func checkIfMatchVersion(w http.ResponseWriter, req *http.Request, q querier, cfgID pgtype.UUID, version int) bool {
+	if req.Header.Get("If-Match") == "" {
+		return true
+	}
+	etag, err := storeVersionETag(req.Context(), q, cfgID, version)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return false
+	}
+	return checkIfMatch(w, req, etag)
+}
+
 func getNamespaceAndPath(w http.ResponseWriter, req *http.Request) (string, string, bool) {
 	namespace := strings.TrimSpace(chi.URLParam(req, "namespace"))
 	if err := validateNamespace(namespace); err != nil {
@@ -0,0 +1,675 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// checkDirectWrite rejects a write that does not go through a draft (action: create, update, delete or move) in a
+// namespace that requires approvals. Drafts only update existing configs, so configs of such a namespace cannot be
+// created, deleted or moved until required_approvals is set back to 0. Errors are *httpError.
+func checkDirectWrite(policy NamespacePolicy, action string) error {
+	if policy.RequiredApprovals == 0 {
+		return nil
+	}
+	msg := "namespace requires approved drafts; create a draft instead"
+	if action != changeActionUpdate {
+		msg = "namespace requires approved drafts, which only update existing configs; configs cannot be created, deleted or moved in it"
+	}
+	return &httpError{Status: http.StatusForbidden, Code: "approval_required", Message: msg, Details: map[string]any{
+		"required_approvals": policy.RequiredApprovals,
+		"action":             action,
+	}}
+}
+
+func handleCreateDraft(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+
+	var body CreateDraftRequest
+	if err := decodeJSONBody(w, req, &body, maxConfigBodyBytes); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	if body.BodyRaw == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "body_raw is required", map[string]any{"field": "body_raw"})
+		return
+	}
+
+	policy, err := storeGetNamespacePolicy(req.Context(), db, namespace)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	// Self-approval can only be prevented when the author is known.
+	if policy.RequiredApprovals > 0 && (body.CreatedBy == nil || strings.TrimSpace(*body.CreatedBy) == "") {
+		writeError(w, http.StatusBadRequest, "bad_request", "created_by is required when the namespace requires approvals", map[string]any{"field": "created_by"})
+		return
+	}
+	// Checked again at publish; rejecting here saves a review round on a draft that could never be published.
+	if _, err := checkComment(policy, body.Comment); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
+	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	cfg, cfgID, err := storeLockConfig(req.Context(), tx, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	latestNum, err := storeLatestVersionNumber(req.Context(), tx, cfgID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if body.BaseVersion != nil && *body.BaseVersion != latestNum {
+		writeError(w, http.StatusConflict, "conflict", "base_version does not match current latest", map[string]any{
+			"base_version":    *body.BaseVersion,
+			"current_version": latestNum,
+		})
+		return
+	}
+
+	_, parsedJSON, err := parseBody(cfg.Format, body.BodyRaw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	sha := sha256Hex(body.BodyRaw)
+	if latestNum > 0 {
+		latestSHA, err := storeVersionContentSHA(req.Context(), tx, cfgID, latestNum)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		if latestSHA == sha {
+			writeError(w, http.StatusConflict, "no_change", "body_raw matches current latest", map[string]any{"current_version": latestNum})
+			return
+		}
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	var draftID pgtype.UUID
+	err = tx.QueryRow(req.Context(), `
+		INSERT INTO config_drafts (config_id, base_version, body_raw, body_json, content_sha256, created_by, comment, request_id, user_agent, source_ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, cfgID, latestNum, body.BodyRaw, json.RawMessage(parsedJSON), sha, body.CreatedBy, body.Comment, reqID, userAgent, sourceIP).Scan(&draftID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert draft failed", nil)
+		return
+	}
+
+	draft, err := storeGetDraft(req.Context(), tx, cfgID, draftID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+
+	annotateDraft(&draft.ConfigDraftMeta, latestNum, policy.RequiredApprovals)
+	writeJSON(w, http.StatusCreated, GetDraftResponse{Config: cfg, Draft: draft, Reviews: []DraftReview{}})
+}
+
+func handleListDrafts(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	status := strings.TrimSpace(req.URL.Query().Get("status"))
+	switch status {
+	case "", draftStatusOpen, draftStatusPublished, draftStatusRejected:
+	default:
+		writeError(w, http.StatusBadRequest, "bad_request", "status must be one of: open, published, rejected", nil)
+		return
+	}
+	limit, err := parseLimit(req, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	offset, err := parseCursorOffset(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	_, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	latestNum, err := storeLatestVersionNumber(req.Context(), db, cfgID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	policy, err := storeGetNamespacePolicy(req.Context(), db, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	rows, err := db.Query(req.Context(), `
+		SELECT `+draftMetaColumns+`
+		FROM config_drafts d
+		WHERE d.config_id = $1
+		  AND ($2 = '' OR d.status = $2)
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $3 OFFSET $4
+	`, cfgID, status, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	defer rows.Close()
+
+	items := make([]ConfigDraftMeta, 0, limit)
+	for rows.Next() {
+		d, err := scanConfigDraftMeta(rows)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "scan failed", nil)
+			return
+		}
+		annotateDraft(&d, latestNum, policy.RequiredApprovals)
+		items = append(items, d)
+	}
+
+	var next *string
+	if len(items) == limit {
+		c := encodeCursorOffset(offset + limit)
+		next = &c
+	}
+	writeJSON(w, http.StatusOK, DraftListResponse{Items: items, NextCursor: next})
+}
+
+func handleGetDraft(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	draftID, ok := getDraftID(w, req)
+	if !ok {
+		return
+	}
+
+	cfg, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeDraftResponse(w, req, db, http.StatusOK, cfg, cfgID, draftID)
+}
+
+// handleRebaseDraft replaces a draft's body and/or moves its base to the current latest.
+// Every rebase bumps the revision, so earlier approvals no longer count. Whoever changes the body or comment is added
+// to revised_by and can no longer approve the draft.
+func handleRebaseDraft(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	draftID, ok := getDraftID(w, req)
+	if !ok {
+		return
+	}
+
+	var body RebaseDraftRequest
+	if err := decodeJSONBody(w, req, &body, maxConfigBodyBytes); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	if body.BaseVersion == nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "base_version is required", map[string]any{"field": "base_version"})
+		return
+	}
+	var revisedBy *string
+	if body.RevisedBy != nil && strings.TrimSpace(*body.RevisedBy) != "" {
+		revisedBy = ptr(strings.TrimSpace(*body.RevisedBy))
+	}
+
+	policy, err := storeGetNamespacePolicy(req.Context(), db, namespace)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	cfg, cfgID, err := storeLockConfig(req.Context(), tx, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	draft, err := storeLockDraft(req.Context(), tx, cfgID, draftID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "draft not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if draft.Status != draftStatusOpen {
+		writeError(w, http.StatusConflict, "conflict", "draft is not open", map[string]any{"status": draft.Status})
+		return
+	}
+
+	latestNum, err := storeLatestVersionNumber(req.Context(), tx, cfgID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if *body.BaseVersion != latestNum {
+		writeError(w, http.StatusConflict, "conflict", "base_version does not match current latest", map[string]any{
+			"base_version":    *body.BaseVersion,
+			"current_version": latestNum,
+		})
+		return
+	}
+
+	bodyRaw := body.BodyRaw
+	if bodyRaw == "" {
+		bodyRaw = draft.BodyRaw
+	}
+	_, parsedJSON, err := parseBody(cfg.Format, bodyRaw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	comment := draft.Comment
+	if body.Comment != nil {
+		comment = body.Comment
+	}
+	sha := sha256Hex(bodyRaw)
+	revised := draft.ContentSHA256 == nil || *draft.ContentSHA256 != sha || !equalStringPtr(draft.Comment, comment)
+	// Same rule as created_by on create: approvals can only be checked against known revisers.
+	if revised && policy.RequiredApprovals > 0 && revisedBy == nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "revised_by is required to change the body or comment when the namespace requires approvals", map[string]any{"field": "revised_by"})
+		return
+	}
+	if !revised {
+		revisedBy = nil
+	}
+
+	_, err = tx.Exec(req.Context(), `
+		UPDATE config_drafts
+		SET base_version = $2, revision = revision + 1, body_raw = $3, body_json = $4, content_sha256 = $5, comment = $6,
+		    revised_by = CASE WHEN $7::text IS NULL OR $7 = ANY(revised_by) THEN revised_by ELSE array_append(revised_by, $7) END
+		WHERE id = $1
+	`, draftID, latestNum, bodyRaw, json.RawMessage(parsedJSON), sha, comment, revisedBy)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "update draft failed", nil)
+		return
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	writeDraftResponse(w, req, db, http.StatusOK, cfg, cfgID, draftID)
+}
+
+func handleReviewDraft(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	draftID, ok := getDraftID(w, req)
+	if !ok {
+		return
+	}
+
+	var body DraftReviewRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	body.Reviewer = strings.TrimSpace(body.Reviewer)
+	if body.Reviewer == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "reviewer is required", map[string]any{"field": "reviewer"})
+		return
+	}
+	switch body.Decision {
+	case "approve", "reject", "comment":
+	default:
+		writeError(w, http.StatusBadRequest, "bad_request", "decision must be one of: approve, reject, comment", map[string]any{"field": "decision"})
+		return
+	}
+
+	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	cfg, cfgID, err := storeGetConfigOnly(req.Context(), tx, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	draft, err := storeLockDraft(req.Context(), tx, cfgID, draftID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "draft not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if draft.Status != draftStatusOpen {
+		writeError(w, http.StatusConflict, "conflict", "draft is not open", map[string]any{"status": draft.Status})
+		return
+	}
+
+	if body.Decision == "approve" {
+		if draft.CreatedBy != nil && strings.EqualFold(strings.TrimSpace(*draft.CreatedBy), body.Reviewer) {
+			writeError(w, http.StatusForbidden, "forbidden", "authors cannot approve their own drafts", nil)
+			return
+		}
+		for _, reviser := range draft.RevisedBy {
+			if strings.EqualFold(reviser, body.Reviewer) {
+				writeError(w, http.StatusForbidden, "forbidden", "revisers cannot approve drafts they changed", nil)
+				return
+			}
+		}
+		latestNum, err := storeLatestVersionNumber(req.Context(), tx, cfgID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		if draft.BaseVersion != latestNum {
+			writeError(w, http.StatusConflict, "stale_draft", "latest moved since the draft was written; rebase before approving", map[string]any{
+				"base_version":    draft.BaseVersion,
+				"current_version": latestNum,
+			})
+			return
+		}
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	_, err = tx.Exec(req.Context(), `
+		INSERT INTO config_draft_reviews (draft_id, revision, reviewer, decision, comment, request_id, user_agent, source_ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, draftID, draft.Revision, body.Reviewer, body.Decision, body.Comment, reqID, userAgent, sourceIP)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert review failed", nil)
+		return
+	}
+	if body.Decision == "reject" {
+		if _, err := tx.Exec(req.Context(), `UPDATE config_drafts SET status = $2 WHERE id = $1`, draftID, draftStatusRejected); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "update draft failed", nil)
+			return
+		}
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	writeDraftResponse(w, req, db, http.StatusCreated, cfg, cfgID, draftID)
+}
+
+// handlePublishDraft appends the draft as a new version once it is current and sufficiently approved.
+func handlePublishDraft(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	draftID, ok := getDraftID(w, req)
+	if !ok {
+		return
+	}
+
+	var body PublishDraftRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	tx, err := beginEventTx(req.Context(), db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	cfg, cfgID, err := storeLockConfig(req.Context(), tx, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if err := checkLocks(req.Context(), tx, namespace, []string{path}, lockOverride(req)); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+	draft, err := storeLockDraft(req.Context(), tx, cfgID, draftID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "draft not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if draft.Status != draftStatusOpen {
+		writeError(w, http.StatusConflict, "conflict", "draft is not open", map[string]any{"status": draft.Status})
+		return
+	}
+
+	latestNum, err := storeLatestVersionNumber(req.Context(), tx, cfgID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if draft.BaseVersion != latestNum {
+		writeError(w, http.StatusConflict, "stale_draft", "latest moved since the draft was written; rebase or reject it", map[string]any{
+			"base_version":    draft.BaseVersion,
+			"current_version": latestNum,
+		})
+		return
+	}
+
+	policy, err := storeGetNamespacePolicy(req.Context(), tx, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if draft.Approvals < policy.RequiredApprovals {
+		writeError(w, http.StatusConflict, "approval_required", "draft does not have enough approvals", map[string]any{
+			"approvals":          draft.Approvals,
+			"required_approvals": policy.RequiredApprovals,
+		})
+		return
+	}
+	ticketRefs, err := checkComment(policy, draft.Comment)
+	if err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
+	sha := sha256Hex(draft.BodyRaw)
+	if latestNum > 0 {
+		latestSHA, err := storeVersionContentSHA(req.Context(), tx, cfgID, latestNum)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		if latestSHA == sha {
+			writeError(w, http.StatusConflict, "no_change", "draft body matches current latest", map[string]any{"current_version": latestNum})
+			return
+		}
+	}
+
+	parsedAny, parsedJSON, err := parseBody(cfg.Format, draft.BodyRaw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+
+	nextVersion := latestNum + 1
+	warnings, err := admit(req.Context(), db, tx, admissionReview{
+		Operation:  admissionOpUpdate,
+		Namespace:  namespace,
+		Path:       path,
+		Format:     cfg.Format,
+		New:        admissionObject{Version: nextVersion, BodyRaw: draft.BodyRaw, BodyJSON: parsedJSON},
+		Author:     draft.CreatedBy,
+		Comment:    draft.Comment,
+		configID:   cfgID,
+		oldVersion: latestNum,
+	}.withRequest(req))
+	if err != nil {
+		writeHTTPError(w, err, "admission failed")
+		return
+	}
+	in := versionInput{
+		ConfigID:   cfgID,
+		Version:    nextVersion,
+		BodyRaw:    draft.BodyRaw,
+		BodyJSON:   parsedJSON,
+		CreatedBy:  draft.CreatedBy,
+		Comment:    draft.Comment,
+		SHA256:     sha,
+		TicketRefs: ticketRefs,
+		RequestID:  reqID,
+		UserAgent:  userAgent,
+		SourceIP:   sourceIP,
+	}
+	newVerID, createdAt, err := storeInsertVersion(req.Context(), tx, in)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert version failed", nil)
+		return
+	}
+	if err := storeInsertEvent(req.Context(), tx, versionEvent(eventConfigUpdated, namespace, path, in)); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
+		return
+	}
+
+	_, err = tx.Exec(req.Context(), `
+		UPDATE config_drafts
+		SET status = $2, published_version = $3, published_by = $4, published_at = now()
+		WHERE id = $1
+	`, draftID, draftStatusPublished, nextVersion, body.PublishedBy)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "update draft failed", nil)
+		return
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	configChanges.notify(namespace, path)
+
+	cfg.LatestVersionID = ptr(uuidToString(newVerID))
+	ver := ConfigVersion{
+		ID:            uuidToString(newVerID),
+		Version:       nextVersion,
+		CreatedAt:     createdAt,
+		CreatedBy:     draft.CreatedBy,
+		Comment:       draft.Comment,
+		ContentSHA256: ptr(sha),
+		TicketRefs:    ticketRefs,
+		BodyRaw:       draft.BodyRaw,
+		BodyJSON:      parsedAny,
+	}
+	writeJSON(w, http.StatusOK, GetConfigResponse{Config: cfg, Latest: ver, Warnings: warnings})
+}
+
+func writeDraftResponse(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, status int, cfg Config, cfgID, draftID pgtype.UUID) {
+	draft, err := storeGetDraft(req.Context(), db, cfgID, draftID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "draft not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	reviews, err := storeListDraftReviews(req.Context(), db, draftID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	latestNum, err := storeLatestVersionNumber(req.Context(), db, cfgID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	policy, err := storeGetNamespacePolicy(req.Context(), db, cfg.Namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	annotateDraft(&draft.ConfigDraftMeta, latestNum, policy.RequiredApprovals)
+	writeJSON(w, status, GetDraftResponse{Config: cfg, Draft: draft, Reviews: reviews})
+}
+
+func getDraftID(w http.ResponseWriter, req *http.Request) (pgtype.UUID, bool) {
+	id, err := parseUUID(chi.URLParam(req, "draft"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "draft must be a UUID", nil)
+		return pgtype.UUID{}, false
+	}
+	return id, true
+}
@@ -0,0 +1,130 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"config-manager/internal/config"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// eventBatchSize bounds the events read per query while a stream catches up.
+const eventBatchSize = 500
+
+// streamsClosing is closed when the server shuts down so open streams end (http.Server.Shutdown does not cancel
+// request contexts; it waits for handlers to return).
+var streamsClosing = make(chan struct{})
+
+// CloseEventStreams ends every open event stream. Register it with http.Server.RegisterOnShutdown.
+func CloseEventStreams() {
+	select {
+	case <-streamsClosing:
+	default:
+		close(streamsClosing)
+	}
+}
+
+// handleEventStream serves GET /events as Server-Sent Events. Each config_events row is sent as one event
+// (id = event id, event = type, data = ConfigEvent JSON). Clients resume with Last-Event-ID (or ?last_event_id= for
+// the first connection); without it the stream starts with the next change. Comment lines are sent as heartbeats.
+func handleEventStream(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	q := req.URL.Query()
+	namespace := strings.TrimSpace(q.Get("namespace"))
+	if namespace != "" {
+		if err := validateNamespace(namespace); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "namespace"})
+			return
+		}
+	}
+	prefix, err := parsePrefix(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	lastRaw := strings.TrimSpace(req.Header.Get("Last-Event-ID"))
+	if lastRaw == "" {
+		lastRaw = strings.TrimSpace(q.Get("last_event_id"))
+	}
+	var lastID int64
+	if lastRaw != "" {
+		if lastID, err = strconv.ParseInt(lastRaw, 10, 64); err != nil || lastID < 0 {
+			writeError(w, http.StatusBadRequest, "bad_request", "Last-Event-ID must be an event id", nil)
+			return
+		}
+	} else if lastID, err = storeLastEventID(req.Context(), db); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	// Streams outlive api.server.writeTimeoutSeconds; they are exempt from middleware.Timeout in NewRouter.
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		writeError(w, http.StatusInternalServerError, "internal_error", "streaming not supported", nil)
+		return
+	}
+
+	cw := configChanges.subscribe(namespace, "", prefix)
+	defer configChanges.unsubscribe(namespace, cw)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprint(w, "retry: 3000\n\n"); err != nil || rc.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(time.Duration(max(config.Int("api.events.heartbeatSeconds", 15), 1)) * time.Second)
+	defer heartbeat.Stop()
+	var recheck <-chan time.Time
+	if secs := config.Int("api.watch.recheckIntervalSeconds", 60); secs > 0 {
+		t := time.NewTicker(time.Duration(secs) * time.Second)
+		defer t.Stop()
+		recheck = t.C
+	}
+
+	for {
+		events, err := storeListEvents(req.Context(), db, lastID, namespace, prefix, eventBatchSize)
+		if err != nil {
+			return
+		}
+		for _, e := range events {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data); err != nil {
+				return
+			}
+			lastID = e.ID
+		}
+		if len(events) > 0 {
+			if rc.Flush() != nil {
+				return
+			}
+			if len(events) == eventBatchSize {
+				continue
+			}
+		}
+
+		select {
+		case <-cw.ch:
+		case <-recheck:
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		case <-streamsClosing:
+			return
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"config-manager/internal/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// handleExportNamespace serves GET /namespaces/{namespace}/export?prefix=...&format=tar|zip&as_of=...: an archive of
+// every config's latest (or as_of) body laid out by path, preceded by manifest.json. Bodies are streamed one at a
+// time from a repeatable-read snapshot, so the manifest and the files always match.
+func handleExportNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	if err := validateNamespace(namespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	namespace = strings.TrimSpace(namespace)
+	prefix, err := parsePrefix(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	asOf, err := parseAsOf(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	format := strings.TrimSpace(req.URL.Query().Get("format"))
+	switch format {
+	case "":
+		format = "tar"
+	case "tar", "zip":
+	default:
+		writeError(w, http.StatusBadRequest, "bad_request", "format must be tar or zip", map[string]any{"field": "format"})
+		return
+	}
+
+	// Exports skip the request timeout of both routers in NewRouter; api.export.timeoutSeconds bounds them instead,
+	// including the server write deadline.
+	timeout := time.Duration(config.Int("api.export.timeoutSeconds", 600)) * time.Second
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(timeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		writeError(w, http.StatusInternalServerError, "internal_error", "streaming not supported", nil)
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin tx failed", nil)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	ok, err := storeNamespaceExists(ctx, tx, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+	items, err := storeListExportItems(ctx, tx, namespace, prefix, asOf)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	exportFileNames(items)
+
+	manifest := ExportManifest{Namespace: namespace, Prefix: prefix, AsOf: asOf, ExportedAt: time.Now().UTC(), Items: items}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "encode manifest failed", nil)
+		return
+	}
+
+	aw, contentType, ext := newArchiveWriter(format, w)
+	name := namespace
+	if prefix != "" {
+		name += "-" + strings.ReplaceAll(strings.TrimSuffix(prefix, "/"), "/", "-")
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+ext))
+	w.WriteHeader(http.StatusOK)
+
+	err = aw.add(exportManifestName, manifest.ExportedAt, append(manifestJSON, '\n'))
+	if err == nil {
+		ids := make([]string, len(items))
+		for i, it := range items {
+			ids[i] = it.VersionID
+		}
+		written := 0
+		err = storeStreamExportBodies(ctx, tx, ids, func(i int, bodyRaw string) error {
+			written++
+			return aw.add(items[i].File, items[i].CreatedAt, []byte(bodyRaw))
+		})
+		if err == nil && written != len(items) {
+			err = fmt.Errorf("%d of %d bodies found", written, len(items))
+		}
+	}
+	if err == nil {
+		err = aw.Close()
+	}
+	if err != nil {
+		// The status is already sent: abort the connection so the client sees a truncated archive, not a short one.
+		log.Printf("export: %s (prefix %q): %v", namespace, prefix, err)
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,119 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// handleDeleteFolder soft-deletes every config under a folder prefix in one transaction.
+// Without ?confirm= it only returns the plan and its confirm_token; with a matching token it deletes exactly that plan.
+func handleDeleteFolder(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace, rawPrefix string) {
+	if err := validateNamespace(namespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	prefix, err := normalizePrefix(rawPrefix)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	if prefix == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "folder prefix is required (use DELETE /namespaces/{namespace}?cascade=true for the whole namespace)", nil)
+		return
+	}
+	confirm := strings.TrimSpace(req.URL.Query().Get("confirm"))
+
+	tx, err := beginEventTx(req.Context(), db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	var one int
+	err = tx.QueryRow(req.Context(), `SELECT 1 FROM namespaces WHERE name = $1 FOR SHARE`, namespace).Scan(&one)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	if confirm != "" {
+		policy, err := storeGetNamespacePolicy(req.Context(), tx, namespace)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		if err := checkDirectWrite(policy, changeActionDelete); err != nil {
+			writeHTTPError(w, err, "query failed")
+			return
+		}
+		// Lock the folder's configs so the plan cannot change between the check and the delete.
+		if _, err := tx.Exec(req.Context(), `
+			SELECT id FROM configs
+			WHERE namespace = $1 AND deleted_at IS NULL AND left(path, length($2::text)) = $2
+			FOR UPDATE
+		`, namespace, prefix); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+	}
+	plan, ids, err := planBulkDelete(req.Context(), tx, bulkDeleteScopeFolder, namespace, prefix)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if plan.ConfigCount == 0 {
+		writeError(w, http.StatusNotFound, "not_found", "no configs under prefix", map[string]any{"prefix": prefix})
+		return
+	}
+	if confirm == "" {
+		writeJSON(w, http.StatusOK, plan)
+		return
+	}
+	if confirm != plan.ConfirmToken {
+		writeError(w, http.StatusConflict, "confirm_mismatch", "confirm token does not match the current folder contents; review the new plan", map[string]any{
+			"confirm_token": plan.ConfirmToken,
+			"config_count":  plan.ConfigCount,
+		})
+		return
+	}
+
+	paths := make([]string, 0, len(plan.Items))
+	for _, it := range plan.Items {
+		paths = append(paths, it.Path)
+	}
+	if err := checkLocks(req.Context(), tx, namespace, paths, lockOverride(req)); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
+	if _, err := tx.Exec(req.Context(), `UPDATE configs SET deleted_at = now() WHERE id = ANY($1)`, ids); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "delete failed", nil)
+		return
+	}
+	reqID, _, _ := requestAuditFields(req)
+	for i, it := range plan.Items {
+		if err := storeInsertEvent(req.Context(), tx, eventInput{
+			Type: eventConfigDeleted, Namespace: namespace, Path: it.Path, ConfigID: ids[i], Version: it.LatestVersion, RequestID: reqID,
+		}); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
+			return
+		}
+	}
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	configChanges.notify(namespace, paths...)
+	plan.DryRun = false
+	plan.ConfirmToken = ""
+	writeJSON(w, http.StatusOK, plan)
+}
@@ -0,0 +1,254 @@
+package httpapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	importActionCreate    = "create"
+	importActionUpdate    = "update"
+	importActionUnchanged = "unchanged"
+	importActionSkip      = "skip"
+)
+
+// importPlan is what importing one archive file does.
+type importPlan struct {
+	item    ImportItem
+	bodyRaw string
+}
+
+// handleImportNamespace serves POST /namespaces/{namespace}/import: the request body is a tar, tar.gz or zip
+// archive whose files become configs at prefix + their name, with the format taken from the extension (or, for an
+// export archive, from its manifest.json, so exports import back to the same paths).
+//
+//   - Missing configs are created; existing ones get a new version only if the content changed.
+//   - All creates and updates are applied as one changeset (one transaction) with the created_by and comment
+//     query parameters as author and comment.
+//   - dry_run=true runs every check of the real import (locks, namespace policy) and returns the plan, then rolls
+//     back.
+func handleImportNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	if err := validateNamespace(namespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	namespace = strings.TrimSpace(namespace)
+	prefix, err := parsePrefix(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	dryRun, _, err := parseOptionalBool(req, "dry_run")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	var createdBy, comment *string
+	if v := strings.TrimSpace(req.URL.Query().Get("created_by")); v != "" {
+		createdBy = &v
+	}
+	if v := strings.TrimSpace(req.URL.Query().Get("comment")); v != "" {
+		comment = &v
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxImportBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	files, manifest, err := readImportArchive(data)
+	if err != nil {
+		writeHTTPError(w, err, "invalid archive")
+		return
+	}
+	plans, err := planImportFiles(files, manifest, prefix)
+	if err != nil {
+		writeHTTPError(w, err, "invalid archive")
+		return
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	tx, err := beginEventTx(req.Context(), db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	ok, err := storeNamespaceExists(req.Context(), tx, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+
+	// Compare every file with its config first, so all format conflicts are reported at once.
+	var conflicts []string
+	var changes []changesetItemInput
+	var changed []int // plans index of each change
+	for i := range plans {
+		p := &plans[i]
+		if p.item.Action == importActionSkip {
+			continue
+		}
+		cfg, cfgID, err := storeGetConfigOnly(req.Context(), tx, namespace, p.item.Path)
+		if errors.Is(err, pgx.ErrNoRows) {
+			p.item.Action, p.item.Version = importActionCreate, ptr(1)
+			changes = append(changes, changesetItemInput{Action: changeActionCreate, Namespace: namespace, Path: p.item.Path, Format: p.item.Format, BodyRaw: p.bodyRaw})
+			changed = append(changed, i)
+			continue
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		p.item.ConfigID = ptr(cfg.ID)
+		if cfg.Format != p.item.Format {
+			conflicts = append(conflicts, p.item.Path)
+			continue
+		}
+		latest, err := storeLatestVersionNumber(req.Context(), tx, cfgID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		latestSHA, err := storeVersionContentSHA(req.Context(), tx, cfgID, latest)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		if latestSHA == sha256Hex(p.bodyRaw) {
+			p.item.Action, p.item.Version = importActionUnchanged, ptr(latest)
+			continue
+		}
+		p.item.Action, p.item.PreviousVersion, p.item.Version = importActionUpdate, ptr(latest), ptr(latest+1)
+		changes = append(changes, changesetItemInput{Action: changeActionUpdate, Namespace: namespace, Path: p.item.Path, BodyRaw: p.bodyRaw, BaseVersion: ptr(latest)})
+		changed = append(changed, i)
+	}
+	if len(conflicts) > 0 {
+		writeError(w, http.StatusConflict, "conflict", "config exists with a different format", map[string]any{"paths": conflicts})
+		return
+	}
+
+	var cs *Changeset
+	if len(changes) > 0 {
+		applied, err := applyChangeset(req.Context(), db, tx, changesetInput{
+			CreatedBy:    createdBy,
+			Comment:      comment,
+			RequestID:    reqID,
+			UserAgent:    userAgent,
+			SourceIP:     sourceIP,
+			LockOverride: lockOverride(req),
+			DryRun:       dryRun,
+			Items:        changes,
+		})
+		if err != nil {
+			// Name the file instead of the changeset item index.
+			var he *httpError
+			if errors.As(err, &he) && he.Details != nil {
+				if i, ok := he.Details["item"].(int); ok {
+					he.Details["file"] = plans[changed[i]].item.File
+					delete(he.Details, "item")
+				}
+			}
+			writeHTTPError(w, err, "import failed")
+			return
+		}
+		for i, it := range applied.Items {
+			p := &plans[changed[i]]
+			p.item.Version = it.Version
+			if !dryRun {
+				p.item.ConfigID = it.ConfigID
+			}
+		}
+		cs = &applied
+	}
+
+	resp := ImportResponse{DryRun: dryRun, Namespace: namespace, Prefix: prefix, Items: make([]ImportItem, 0, len(plans))}
+	for _, p := range plans {
+		switch p.item.Action {
+		case importActionCreate:
+			resp.Created++
+		case importActionUpdate:
+			resp.Updated++
+		case importActionUnchanged:
+			resp.Unchanged++
+		default:
+			resp.Skipped++
+		}
+		resp.Items = append(resp.Items, p.item)
+	}
+
+	if cs != nil {
+		resp.Warnings = cs.Warnings
+	}
+	if dryRun || cs == nil {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	notifyChangeset(*cs)
+	resp.Changeset = cs
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// planImportFiles maps archive files to config paths and formats, sorted by file name, and validates their bodies.
+// Files without a json/yaml extension (and not in the manifest) are skipped. Errors are *httpError.
+func planImportFiles(files []importFile, manifest *ExportManifest, prefix string) ([]importPlan, error) {
+	fromManifest := map[string]ExportManifestItem{}
+	if manifest != nil {
+		for _, it := range manifest.Items {
+			fromManifest[it.File] = it
+		}
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].Name < files[b].Name })
+
+	plans := make([]importPlan, 0, len(files))
+	seen := map[string]string{} // config path -> file
+	for _, f := range files {
+		p := importPlan{item: ImportItem{File: f.Name}, bodyRaw: string(f.Data)}
+		name := f.Name
+		if m, ok := fromManifest[f.Name]; ok {
+			name, p.item.Format = m.Path, m.Format
+		} else if format, ok := importFormat(f.Name); ok {
+			p.item.Format = format
+		} else {
+			p.item.Action, p.item.Reason = importActionSkip, "unsupported_extension"
+			plans = append(plans, p)
+			continue
+		}
+		bad := func(msg string) error {
+			return &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: msg, Details: map[string]any{"file": f.Name}}
+		}
+
+		path, err := normalizeConfigPath(prefix + name)
+		if err != nil {
+			return nil, bad(err.Error())
+		}
+		if prev, dup := seen[path]; dup {
+			return nil, bad("maps to the same config path as " + prev)
+		}
+		seen[path] = f.Name
+		p.item.Path = path
+		if p.bodyRaw == "" {
+			return nil, bad("file is empty")
+		}
+		if _, _, err := parseBody(p.item.Format, p.bodyRaw); err != nil {
+			return nil, bad(err.Error())
+		}
+		plans = append(plans, p)
+	}
+	return plans, nil
+}
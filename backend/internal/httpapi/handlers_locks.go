@@ -0,0 +1,139 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// handleCreateLock locks a namespace (no path/prefix), a folder (prefix) or a single config (path) against writes.
+func handleCreateLock(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	var body CreateLockRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	scope, target := lockScopeNamespace, ""
+	var err error
+	switch {
+	case body.Path != "" && body.Prefix != "":
+		writeError(w, http.StatusBadRequest, "bad_request", "path and prefix cannot be combined", nil)
+		return
+	case body.Path != "":
+		scope = lockScopeConfig
+		if target, err = normalizeConfigPath(body.Path); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "path"})
+			return
+		}
+	case body.Prefix != "":
+		scope = lockScopePrefix
+		if target, err = normalizePrefix(body.Prefix); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "prefix"})
+			return
+		}
+	}
+	reason := strings.TrimSpace(body.Reason)
+	if reason == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "reason is required", map[string]any{"field": "reason"})
+		return
+	}
+	owner := strings.TrimSpace(body.Owner)
+	if owner == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "owner is required", map[string]any{"field": "owner"})
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		writeError(w, http.StatusBadRequest, "bad_request", "expires_at must be in the future", map[string]any{"field": "expires_at"})
+		return
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	row := db.QueryRow(req.Context(), `
+		WITH l AS (
+			INSERT INTO config_locks (namespace, scope, target, reason, owner, expires_at, request_id, user_agent, source_ip)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING *
+		)
+		SELECT `+lockColumns+` FROM l
+	`, namespace, scope, target, reason, owner, body.ExpiresAt, reqID, userAgent, sourceIP)
+	lock, err := scanLock(row)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusCreated, lock)
+}
+
+// handleListLocks lists the live locks of a namespace; with ?prefix= only those relevant to that folder.
+func handleListLocks(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	prefix, err := parsePrefix(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	nsOK, err := storeNamespaceExists(req.Context(), db, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !nsOK {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+
+	locks, err := storeListLocks(req.Context(), db, namespace, prefix)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, LockListResponse{Items: locks})
+}
+
+// handleDeleteLock lifts a lock (expired locks can be deleted too).
+func handleDeleteLock(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	id, err := parseUUID(chi.URLParam(req, "lock"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "lock must be a UUID", nil)
+		return
+	}
+
+	var deleted pgtype.UUID
+	err = db.QueryRow(req.Context(), `
+		DELETE FROM config_locks WHERE id = $1 AND namespace = $2 RETURNING id
+	`, id, namespace).Scan(&deleted)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "lock not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "delete failed", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,280 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	maxMoveConfigs  = 1000
+	maxAliasTTLSecs = 365 * 24 * 60 * 60
+)
+
+// handleMoveConfigs renames a config (path -> to_path) or a folder (prefix -> to_prefix), optionally into another
+// namespace. Configs keep their id, so versions, tags, drafts and schedules move with them.
+func handleMoveConfigs(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	var body MoveRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	namespace := strings.TrimSpace(body.Namespace)
+	if err := validateNamespace(namespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "namespace"})
+		return
+	}
+	toNamespace := strings.TrimSpace(body.ToNamespace)
+	if toNamespace == "" {
+		toNamespace = namespace
+	}
+	if err := validateNamespace(toNamespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "to_namespace"})
+		return
+	}
+	if body.AliasTTLSeconds < 0 || body.AliasTTLSeconds > maxAliasTTLSecs {
+		writeError(w, http.StatusBadRequest, "bad_request", "alias_ttl_seconds must be between 0 and 31536000", map[string]any{"field": "alias_ttl_seconds"})
+		return
+	}
+
+	mapping, err := newPathMapping(body.Path, body.Prefix, body.ToPath, body.ToPrefix)
+	if err == nil {
+		err = mapping.checkDistinct(toNamespace == namespace)
+	}
+	if err != nil {
+		writeHTTPError(w, err, "invalid request")
+		return
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	tx, err := beginEventTx(req.Context(), db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	nsOK, err := storeNamespaceExists(req.Context(), tx, toNamespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !nsOK {
+		writeError(w, http.StatusNotFound, "not_found", "to_namespace not found", nil)
+		return
+	}
+	for _, ns := range []string{namespace, toNamespace} {
+		policy, err := storeGetNamespacePolicy(req.Context(), tx, ns)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue // a missing source namespace has no configs to select
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		if err := checkDirectWrite(policy, changeActionMove); err != nil {
+			writeHTTPError(w, err, "query failed")
+			return
+		}
+	}
+
+	// Lock the moving configs in path order.
+	selected, err := storeSelectConfigs(req.Context(), tx, namespace, mapping, true)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if len(selected) == 0 {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if len(selected) > maxMoveConfigs {
+		writeError(w, http.StatusBadRequest, "bad_request", "too many configs to move in one request", map[string]any{"count": len(selected), "max": maxMoveConfigs})
+		return
+	}
+
+	type moving struct {
+		id       pgtype.UUID
+		from, to string
+	}
+	configs := make([]moving, 0, len(selected))
+	targets := make([]string, 0, len(selected))
+	for _, c := range selected {
+		to, err := mapping.target(c.Path)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", "invalid destination path: "+err.Error(), map[string]any{"path": c.Path})
+			return
+		}
+		configs = append(configs, moving{id: c.ID, from: c.Path, to: to})
+		targets = append(targets, to)
+	}
+
+	sources := make([]string, 0, len(configs))
+	for _, m := range configs {
+		sources = append(sources, m.from)
+	}
+	override := lockOverride(req)
+	if err := checkLocks(req.Context(), tx, namespace, sources, override); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+	if err := checkLocks(req.Context(), tx, toNamespace, targets, override); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
+	existing, err := storeActivePaths(req.Context(), tx, toNamespace, targets)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if len(existing) > 0 {
+		writeError(w, http.StatusConflict, "conflict", "destination already exists", map[string]any{"paths": existing})
+		return
+	}
+
+	var moveID pgtype.UUID
+	if err := tx.QueryRow(req.Context(), `SELECT gen_random_uuid()`).Scan(&moveID); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	move := Move{ID: uuidToString(moveID), MovedBy: body.MovedBy, Comment: body.Comment, Items: make([]MoveItem, 0, len(configs))}
+	if body.AliasTTLSeconds > 0 {
+		move.AliasExpiresAt = ptr(time.Now().UTC().Add(time.Duration(body.AliasTTLSeconds) * time.Second).Truncate(time.Second))
+	}
+
+	for _, m := range configs {
+		if _, err := tx.Exec(req.Context(), `UPDATE configs SET namespace = $2, path = $3 WHERE id = $1`, m.id, toNamespace, m.to); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				writeError(w, http.StatusConflict, "conflict", "destination already exists", map[string]any{"paths": []string{m.to}})
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "internal_error", "update failed", nil)
+			return
+		}
+		err := tx.QueryRow(req.Context(), `
+			INSERT INTO config_moves (move_id, config_id, from_namespace, from_path, to_namespace, to_path, moved_by, comment, alias_expires_at, request_id, user_agent, source_ip)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING created_at
+		`, moveID, m.id, namespace, m.from, toNamespace, m.to, body.MovedBy, body.Comment, move.AliasExpiresAt, reqID, userAgent, sourceIP).Scan(&move.CreatedAt)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "insert failed", nil)
+			return
+		}
+		if err := storeInsertEvent(req.Context(), tx, eventInput{
+			Type: eventConfigMoved, Namespace: toNamespace, Path: m.to, ConfigID: m.id, Author: body.MovedBy,
+			FromNamespace: namespace, FromPath: m.from, RequestID: reqID,
+		}); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
+			return
+		}
+		if move.AliasExpiresAt != nil {
+			if _, err := tx.Exec(req.Context(), `
+				INSERT INTO config_aliases (namespace, path, config_id, expires_at)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (namespace, path) DO UPDATE
+				SET config_id = EXCLUDED.config_id, expires_at = EXCLUDED.expires_at, created_at = now()
+			`, namespace, m.from, m.id, *move.AliasExpiresAt); err != nil {
+				writeError(w, http.StatusInternalServerError, "internal_error", "insert failed", nil)
+				return
+			}
+		}
+		move.Items = append(move.Items, MoveItem{
+			ConfigID:      uuidToString(m.id),
+			FromNamespace: namespace,
+			FromPath:      m.from,
+			ToNamespace:   toNamespace,
+			ToPath:        m.to,
+		})
+	}
+	// A config now lives at the destination; stale aliases there must not shadow it.
+	if _, err := tx.Exec(req.Context(), `
+		DELETE FROM config_aliases WHERE namespace = $1 AND path = ANY($2)
+	`, toNamespace, targets); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "delete failed", nil)
+		return
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	for _, it := range move.Items {
+		configChanges.notify(it.FromNamespace, it.FromPath)
+		configChanges.notify(it.ToNamespace, it.ToPath)
+	}
+	writeJSON(w, http.StatusCreated, move)
+}
+
+// handleListConfigMoves lists the move history of a config, newest first.
+func handleListConfigMoves(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	limit, err := parseLimit(req, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	offset, err := parseCursorOffset(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	_, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	items, err := storeListConfigMoves(req.Context(), db, cfgID, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	var next *string
+	if len(items) == limit {
+		c := encodeCursorOffset(offset + limit)
+		next = &c
+	}
+	writeJSON(w, http.StatusOK, ConfigMoveListResponse{Items: items, NextCursor: next})
+}
+
+// redirectConfigAlias answers a read of a moved config with 308 Permanent Redirect to its current location,
+// while the alias left by the move is live. It returns false (writing nothing) if there is no live alias.
+func redirectConfigAlias(w http.ResponseWriter, req *http.Request, q querier, namespace, path string) bool {
+	toNamespace, toPath, err := storeResolveAlias(req.Context(), q, namespace, path)
+	if err != nil {
+		return false
+	}
+
+	// Keep any base path and sub-resource (e.g. /versions/3) of the original URL.
+	oldSuffix := "/configs/" + namespace + "/" + path
+	loc := req.URL.Path
+	if i := strings.LastIndex(loc, oldSuffix); i >= 0 {
+		loc = loc[:i] + "/configs/" + toNamespace + "/" + toPath + loc[i+len(oldSuffix):]
+	}
+	if req.URL.RawQuery != "" {
+		loc += "?" + req.URL.RawQuery
+	}
+	w.Header().Set("Location", loc)
+	writeError(w, http.StatusPermanentRedirect, "moved", "config was moved", map[string]any{
+		"namespace": toNamespace,
+		"path":      toPath,
+	})
+	return true
+}
@@ -3,9 +3,11 @@ package httpapi
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -51,7 +53,14 @@ func handleCreateNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool
 	var id pgtype.UUID
 	var createdAt, updatedAt pgtype.Timestamptz
 
-	err := db.QueryRow(req.Context(), `
+	tx, err := beginEventTx(req.Context(), db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	err = tx.QueryRow(req.Context(), `
 		INSERT INTO namespaces (name)
 		VALUES ($1)
 		RETURNING id, created_at, updated_at
@@ -66,6 +75,16 @@ func handleCreateNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool
 		writeError(w, http.StatusInternalServerError, "internal_error", "insert failed", nil)
 		return
 	}
+	reqID, _, _ := requestAuditFields(req)
+	if err := storeInsertEvent(req.Context(), tx, eventInput{Type: eventNamespaceCreated, Namespace: name, RequestID: reqID}); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
+		return
+	}
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	configChanges.notify(name)
 
 	writeJSON(w, http.StatusCreated, Namespace{
 		ID:        uuidToString(id),
@@ -82,7 +101,7 @@ func handleDeleteNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool
 	}
 	namespace = strings.TrimSpace(namespace)
 
-	tx, err := db.Begin(req.Context())
+	tx, err := beginEventTx(req.Context(), db)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
 		return
@@ -102,6 +121,63 @@ func handleDeleteNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool
 		return
 	}
 
+	// cascade=true deletes the namespace with all its configs, behind the same plan/confirm flow as folder deletes.
+	if req.URL.Query().Get("cascade") == "true" {
+		plan, _, err := planBulkDelete(req.Context(), tx, bulkDeleteScopeNamespace, namespace, "")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		confirm := strings.TrimSpace(req.URL.Query().Get("confirm"))
+		if confirm == "" {
+			writeJSON(w, http.StatusOK, plan)
+			return
+		}
+		if confirm != plan.ConfirmToken {
+			writeError(w, http.StatusConflict, "confirm_mismatch", "confirm token does not match the current namespace contents; review the new plan", map[string]any{
+				"confirm_token": plan.ConfirmToken,
+				"config_count":  plan.ConfigCount,
+			})
+			return
+		}
+		// Configs of a namespace that requires approvals cannot be deleted directly, so neither can the namespace.
+		policy, err := storeGetNamespacePolicy(req.Context(), tx, namespace)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+		if err := checkDirectWrite(policy, changeActionDelete); err != nil {
+			writeHTTPError(w, err, "query failed")
+			return
+		}
+		if err := checkLocks(req.Context(), tx, namespace, nil, lockOverride(req)); err != nil {
+			writeHTTPError(w, err, "query failed")
+			return
+		}
+		if _, err := tx.Exec(req.Context(), `DELETE FROM configs WHERE namespace = $1`, namespace); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "delete failed", nil)
+			return
+		}
+		if _, err := tx.Exec(req.Context(), `DELETE FROM namespaces WHERE name = $1`, namespace); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "delete failed", nil)
+			return
+		}
+		reqID, _, _ := requestAuditFields(req)
+		if err := storeInsertEvent(req.Context(), tx, eventInput{Type: eventNamespaceDeleted, Namespace: namespace, RequestID: reqID}); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
+			return
+		}
+		if err := tx.Commit(req.Context()); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+			return
+		}
+		configChanges.notify(namespace)
+		plan.DryRun = false
+		plan.ConfirmToken = ""
+		writeJSON(w, http.StatusOK, plan)
+		return
+	}
+
 	// Count configs in namespace.
 	var cnt int64
 	if err := tx.QueryRow(req.Context(), `
@@ -111,7 +187,7 @@ func handleDeleteNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool
 		return
 	}
 	if cnt > 0 {
-		writeError(w, http.StatusConflict, "conflict", "namespace is not empty", map[string]any{"config_count": cnt})
+		writeError(w, http.StatusConflict, "conflict", "namespace is not empty", map[string]any{"config_count": cnt, "hint": "use cascade=true to delete it with its configs"})
 		return
 	}
 
@@ -138,11 +214,17 @@ func handleDeleteNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool
 		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
 		return
 	}
+	reqID, _, _ := requestAuditFields(req)
+	if err := storeInsertEvent(req.Context(), tx, eventInput{Type: eventNamespaceDeleted, Namespace: namespace, RequestID: reqID}); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
+		return
+	}
 
 	if err := tx.Commit(req.Context()); err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
 		return
 	}
+	configChanges.notify(namespace)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -179,6 +261,11 @@ func handleBrowseNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool
 		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
 		return
 	}
+	asOf, err := parseAsOf(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
 
 	startIndex := len(prefix) + 1 // SQL substr is 1-based
 
@@ -188,17 +275,18 @@ func handleBrowseNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool
 				c.path,
 				c.format::text AS format,
 				lv.version AS latest_version
-			FROM configs c
+			FROM `+configSource("c", "$6", asOf != nil)+`
 			LEFT JOIN LATERAL (
 				SELECT version
 				FROM config_versions
 				WHERE config_id = c.id
+				  AND ($6::timestamptz IS NULL OR created_at <= $6)
 				ORDER BY version DESC
 				LIMIT 1
 			) lv ON true
 			WHERE c.namespace = $1
 			  AND ($2 = '' OR c.path LIKE $2 || '%')
-			  AND c.deleted_at IS NULL
+			  AND lv.version IS NOT NULL
 		),
 		agg AS (
 			SELECT
@@ -214,7 +302,7 @@ func handleBrowseNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool
 		FROM agg
 		ORDER BY child ASC
 		LIMIT $4 OFFSET $5
-	`, namespace, prefix, startIndex, limit, offset)
+	`, namespace, prefix, startIndex, limit, offset, asOf)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
 		return
@@ -267,8 +355,185 @@ func handleBrowseNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool
 		next = &c
 	}
 
+	var locks []Lock
+	if asOf == nil {
+		if locks, err = storeListLocks(req.Context(), db, namespace, prefix); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+	}
+
 	writeJSON(w, http.StatusOK, BrowseResponse{
 		Items:      entries,
 		NextCursor: next,
+		AsOf:       asOf,
+		Locks:      locks,
 	})
 }
+
+func handleGetNamespacePolicy(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	if err := validateNamespace(namespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	namespace = strings.TrimSpace(namespace)
+
+	policy, err := storeGetNamespacePolicy(req.Context(), db, namespace)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, policy)
+}
+
+// handleUpdateNamespacePolicy updates the fields present in the request body; omitted fields are left unchanged.
+func handleUpdateNamespacePolicy(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	if err := validateNamespace(namespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	namespace = strings.TrimSpace(namespace)
+
+	var body UpdateNamespacePolicyRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	if body.RequiredApprovals != nil && (*body.RequiredApprovals < 0 || *body.RequiredApprovals > 10) {
+		writeError(w, http.StatusBadRequest, "bad_request", "required_approvals must be an integer between 0 and 10", map[string]any{"field": "required_approvals"})
+		return
+	}
+	if body.MinCommentLength != nil && (*body.MinCommentLength < 0 || *body.MinCommentLength > maxMinCommentLength) {
+		writeError(w, http.StatusBadRequest, "bad_request", fmt.Sprintf("min_comment_length must be an integer between 0 and %d", maxMinCommentLength), map[string]any{"field": "min_comment_length"})
+		return
+	}
+	if body.TicketPattern != nil {
+		if err := validateTicketPattern(*body.TicketPattern); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "ticket_pattern"})
+			return
+		}
+	}
+
+	tag, err := db.Exec(req.Context(), `
+		UPDATE namespaces
+		SET required_approvals = COALESCE($2, required_approvals),
+		    require_comment = COALESCE($3, require_comment),
+		    min_comment_length = COALESCE($4, min_comment_length),
+		    ticket_pattern = CASE WHEN $5::text IS NULL THEN ticket_pattern ELSE NULLIF($5::text, '') END
+		WHERE name = $1
+	`, namespace, body.RequiredApprovals, body.RequireComment, body.MinCommentLength, body.TicketPattern)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "update failed", nil)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+
+	handleGetNamespacePolicy(w, req, db, namespace)
+}
+
+// handleRenameNamespace renames a namespace; configs, releases and aliases follow through ON UPDATE CASCADE.
+// With alias_ttl_seconds the old name stays usable for reads (308 to the new name) until it expires.
+func handleRenameNamespace(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	if err := validateNamespace(namespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	namespace = strings.TrimSpace(namespace)
+	var body RenameNamespaceRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	if err := validateNamespaceName(body.Name); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "name"})
+		return
+	}
+	name := strings.TrimSpace(body.Name)
+	if name == namespace {
+		writeError(w, http.StatusBadRequest, "bad_request", "name is unchanged", map[string]any{"field": "name"})
+		return
+	}
+	if body.AliasTTLSeconds < 0 || body.AliasTTLSeconds > maxAliasTTLSecs {
+		writeError(w, http.StatusBadRequest, "bad_request", "alias_ttl_seconds must be between 0 and 31536000", map[string]any{"field": "alias_ttl_seconds"})
+		return
+	}
+
+	tx, err := beginEventTx(req.Context(), db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	if err := checkLocks(req.Context(), tx, namespace, nil, lockOverride(req)); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
+	var ns Namespace
+	var id pgtype.UUID
+	err = tx.QueryRow(req.Context(), `
+		UPDATE namespaces SET name = $2
+		WHERE name = $1
+		RETURNING id, name, created_at, updated_at
+	`, namespace, name).Scan(&id, &ns.Name, &ns.CreatedAt, &ns.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			writeError(w, http.StatusConflict, "conflict", "namespace already exists", map[string]any{"name": name})
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal_error", "update failed", nil)
+		return
+	}
+	ns.ID = uuidToString(id)
+
+	// Point-in-time reads of the namespace follow the rename for its whole history (moves keep their own intervals).
+	if _, err := tx.Exec(req.Context(), `UPDATE config_lifetimes SET namespace = $2 WHERE namespace = $1`, namespace, name); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "update failed", nil)
+		return
+	}
+
+	// The new name is now a real namespace, so any alias with that name is obsolete.
+	if _, err := tx.Exec(req.Context(), `DELETE FROM namespace_aliases WHERE name = $1`, name); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "delete failed", nil)
+		return
+	}
+	if body.AliasTTLSeconds > 0 {
+		if _, err := tx.Exec(req.Context(), `
+			INSERT INTO namespace_aliases (name, namespace, expires_at)
+			VALUES ($1, $2, now() + $3::int * interval '1 second')
+			ON CONFLICT (name) DO UPDATE
+			SET namespace = EXCLUDED.namespace, expires_at = EXCLUDED.expires_at, created_at = now()
+		`, namespace, name, body.AliasTTLSeconds); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "insert failed", nil)
+			return
+		}
+	}
+	reqID, _, _ := requestAuditFields(req)
+	if err := storeInsertEvent(req.Context(), tx, eventInput{
+		Type: eventNamespaceRenamed, Namespace: name, FromNamespace: namespace, RequestID: reqID,
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert event failed", nil)
+		return
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	configChanges.notify(namespace)
+	configChanges.notify(name)
+	writeJSON(w, http.StatusOK, ns)
+}
@@ -0,0 +1,473 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// handleCreateRelease snapshots the latest version of every active config under namespace/prefix.
+// The snapshot is taken by a single INSERT ... SELECT, so it never contains half of a changeset.
+func handleCreateRelease(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	var body CreateReleaseRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	body.Name = strings.TrimSpace(body.Name)
+	if err := validateReleaseName(body.Name); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "name"})
+		return
+	}
+	prefix, err := normalizePrefix(body.Prefix)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "prefix"})
+		return
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	var releaseID pgtype.UUID
+	err = tx.QueryRow(req.Context(), `
+		INSERT INTO releases (namespace, name, prefix, created_by, comment, request_id, user_agent, source_ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, namespace, body.Name, prefix, body.CreatedBy, body.Comment, reqID, userAgent, sourceIP).Scan(&releaseID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				writeError(w, http.StatusConflict, "conflict", "release already exists", map[string]any{"name": body.Name})
+				return
+			case "23503":
+				writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+				return
+			}
+		}
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert failed", nil)
+		return
+	}
+
+	tag, err := tx.Exec(req.Context(), `
+		INSERT INTO release_items (release_id, path, config_id, version_id, version)
+		SELECT $1, c.path, c.id, lv.id, lv.version
+		FROM configs c
+		JOIN LATERAL (
+			SELECT id, version
+			FROM config_versions
+			WHERE config_id = c.id
+			ORDER BY version DESC
+			LIMIT 1
+		) lv ON true
+		WHERE c.namespace = $2
+		  AND ($3 = '' OR left(c.path, length($3::text)) = $3)
+		  AND c.deleted_at IS NULL
+	`, releaseID, namespace, prefix)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert failed", nil)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		writeError(w, http.StatusBadRequest, "bad_request", "no configs match namespace and prefix", map[string]any{"prefix": prefix})
+		return
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	writeReleaseResponse(w, req, db, http.StatusCreated, namespace, body.Name)
+}
+
+func handleListReleases(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	limit, err := parseLimit(req, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	offset, err := parseCursorOffset(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	nsOK, err := storeNamespaceExists(req.Context(), db, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !nsOK {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+
+	rows, err := db.Query(req.Context(), `
+		SELECT `+releaseMetaColumns+`
+		FROM releases r
+		WHERE r.namespace = $1
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT $2 OFFSET $3
+	`, namespace, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	defer rows.Close()
+
+	items := make([]ReleaseMeta, 0, limit)
+	for rows.Next() {
+		m, _, err := scanReleaseMeta(rows)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "scan failed", nil)
+			return
+		}
+		items = append(items, m)
+	}
+
+	var next *string
+	if len(items) == limit {
+		c := encodeCursorOffset(offset + limit)
+		next = &c
+	}
+	writeJSON(w, http.StatusOK, ReleaseListResponse{Items: items, NextCursor: next})
+}
+
+func handleGetRelease(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	name, ok := getReleaseName(w, req)
+	if !ok {
+		return
+	}
+	writeReleaseResponse(w, req, db, http.StatusOK, namespace, name)
+}
+
+// handleDiffReleases compares two releases of a namespace by path: added, removed, and changed (different version).
+func handleDiffReleases(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	from, ok := getReleaseName(w, req)
+	if !ok {
+		return
+	}
+	to := strings.TrimSpace(req.URL.Query().Get("to"))
+	if err := validateReleaseName(to); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "to: "+err.Error(), map[string]any{"field": "to"})
+		return
+	}
+
+	load := func(name string) (map[string]ReleaseItem, bool) {
+		_, id, err := storeGetReleaseMeta(req.Context(), db, namespace, name)
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "not_found", "release not found", map[string]any{"release": name})
+			return nil, false
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return nil, false
+		}
+		items, err := storeListReleaseItems(req.Context(), db, id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return nil, false
+		}
+		byPath := make(map[string]ReleaseItem, len(items))
+		for _, it := range items {
+			byPath[it.Path] = it
+		}
+		return byPath, true
+	}
+	fromItems, ok := load(from)
+	if !ok {
+		return
+	}
+	toItems, ok := load(to)
+	if !ok {
+		return
+	}
+
+	resp := ReleaseDiffResponse{
+		From:    from,
+		To:      to,
+		Added:   make([]ReleaseDiffEntry, 0),
+		Removed: make([]ReleaseDiffEntry, 0),
+		Changed: make([]ReleaseDiffEntry, 0),
+	}
+	for path, f := range fromItems {
+		t, inTo := toItems[path]
+		switch {
+		case !inTo:
+			resp.Removed = append(resp.Removed, ReleaseDiffEntry{Path: path, FromVersion: ptr(f.Version)})
+		case f.VersionID != t.VersionID:
+			resp.Changed = append(resp.Changed, ReleaseDiffEntry{Path: path, FromVersion: ptr(f.Version), ToVersion: ptr(t.Version)})
+		default:
+			resp.Unchanged++
+		}
+	}
+	for path, t := range toItems {
+		if _, inFrom := fromItems[path]; !inFrom {
+			resp.Added = append(resp.Added, ReleaseDiffEntry{Path: path, ToVersion: ptr(t.Version)})
+		}
+	}
+	for _, list := range [][]ReleaseDiffEntry{resp.Added, resp.Removed, resp.Changed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleRollbackRelease makes the configs under the release's namespace/prefix match the release again, in one changeset:
+// changed configs get the released body as a new version, configs deleted since are recreated, and configs created since are deleted.
+func handleRollbackRelease(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	name, ok := getReleaseName(w, req)
+	if !ok {
+		return
+	}
+	var body RollbackRequest
+	if req.ContentLength != 0 {
+		if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+			return
+		}
+	}
+
+	tx, err := beginEventTx(req.Context(), db)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	meta, releaseID, err := storeGetReleaseMeta(req.Context(), tx, namespace, name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "release not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	items, err := planReleaseRollback(req, tx, meta, releaseID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if len(items) == 0 {
+		writeError(w, http.StatusConflict, "no_change", "configs already match the release", map[string]any{"release": name})
+		return
+	}
+
+	resp := RollbackResponse{Release: meta, DryRun: body.DryRun, Items: make([]ChangesetItem, 0, len(items))}
+	for _, it := range items {
+		resp.Items = append(resp.Items, ChangesetItem{Action: it.Action, Namespace: it.Namespace, Path: it.Path, PreviousVersion: it.BaseVersion})
+	}
+	if body.DryRun {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	comment := body.Comment
+	if comment == nil {
+		comment = ptr("Roll back " + namespace + " to release " + name)
+	}
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	cs, err := applyChangeset(req.Context(), db, tx, changesetInput{
+		CreatedBy:    body.CreatedBy,
+		Comment:      comment,
+		RequestID:    reqID,
+		UserAgent:    userAgent,
+		SourceIP:     sourceIP,
+		LockOverride: lockOverride(req),
+		Items:        items,
+	})
+	if err != nil {
+		writeHTTPError(w, err, "apply changeset failed")
+		return
+	}
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	notifyChangeset(cs)
+	resp.Items = cs.Items
+	resp.Changeset = &cs
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// planReleaseRollback compares the release with the active configs under its namespace/prefix and returns the
+// changeset items that restore it. Each update/delete is guarded by the current latest version.
+func planReleaseRollback(req *http.Request, q querier, meta ReleaseMeta, releaseID pgtype.UUID) ([]changesetItemInput, error) {
+	type current struct {
+		latest int
+		sha    string
+	}
+	active := map[string]current{}
+	rows, err := q.Query(req.Context(), `
+		SELECT c.path, lv.version, COALESCE(lv.content_sha256, '')
+		FROM configs c
+		JOIN LATERAL (
+			SELECT version, content_sha256
+			FROM config_versions
+			WHERE config_id = c.id
+			ORDER BY version DESC
+			LIMIT 1
+		) lv ON true
+		WHERE c.namespace = $1
+		  AND ($2 = '' OR left(c.path, length($2::text)) = $2)
+		  AND c.deleted_at IS NULL
+	`, meta.Namespace, meta.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var path string
+		var cur current
+		if err := rows.Scan(&path, &cur.latest, &cur.sha); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		active[path] = cur
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = q.Query(req.Context(), `
+		SELECT i.path, c.format::text, v.body_raw, v.content_sha256
+		FROM release_items i
+		JOIN configs c ON c.id = i.config_id
+		JOIN config_versions v ON v.id = i.version_id
+		WHERE i.release_id = $1
+		ORDER BY i.path ASC
+	`, releaseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]changesetItemInput, 0)
+	released := map[string]bool{}
+	for rows.Next() {
+		var path, fmtStr, bodyRaw string
+		var sha sql.NullString
+		if err := rows.Scan(&path, &fmtStr, &bodyRaw, &sha); err != nil {
+			return nil, err
+		}
+		released[path] = true
+		releasedSHA := sha.String
+		if !sha.Valid || releasedSHA == "" {
+			releasedSHA = sha256Hex(bodyRaw)
+		}
+
+		cur, exists := active[path]
+		switch {
+		case !exists:
+			items = append(items, changesetItemInput{Action: changeActionCreate, Namespace: meta.Namespace, Path: path, Format: ConfigFormat(fmtStr), BodyRaw: bodyRaw})
+		case cur.sha != releasedSHA:
+			items = append(items, changesetItemInput{Action: changeActionUpdate, Namespace: meta.Namespace, Path: path, BodyRaw: bodyRaw, BaseVersion: ptr(cur.latest)})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for path, cur := range active {
+		if !released[path] {
+			items = append(items, changesetItemInput{Action: changeActionDelete, Namespace: meta.Namespace, Path: path, BaseVersion: ptr(cur.latest)})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Path < items[j].Path })
+	return items, nil
+}
+
+// handleGetReleasedConfig serves GET /configs/{namespace}/{path}?release=... with the version captured by the release.
+func handleGetReleasedConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace, path, release string) {
+	_, releaseID, err := storeGetReleaseMeta(req.Context(), db, namespace, release)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "release not found", map[string]any{"release": release})
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	cfg, ver, err := storeGetReleasedConfig(req.Context(), db, releaseID, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not in release", map[string]any{"release": release})
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	cfg.LatestVersionID = ptr(ver.ID)
+	writeJSONWithVersionETag(w, req, http.StatusOK, GetConfigResponse{Config: cfg, Latest: ver, Release: &release}, configVersionETag(ver))
+}
+
+func writeReleaseResponse(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, status int, namespace, name string) {
+	meta, id, err := storeGetReleaseMeta(req.Context(), db, namespace, name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "release not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	items, err := storeListReleaseItems(req.Context(), db, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, status, Release{ReleaseMeta: meta, Items: items})
+}
+
+func getNamespaceParam(w http.ResponseWriter, namespace string) (string, bool) {
+	if err := validateNamespace(namespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return "", false
+	}
+	return strings.TrimSpace(namespace), true
+}
+
+func getReleaseName(w http.ResponseWriter, req *http.Request) (string, bool) {
+	name := strings.TrimSpace(chi.URLParam(req, "release"))
+	if err := validateReleaseName(name); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return "", false
+	}
+	return name, true
+}
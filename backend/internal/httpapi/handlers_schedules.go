@@ -0,0 +1,388 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func handleCreateSchedule(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+
+	var body CreateScheduleRequest
+	if err := decodeJSONBody(w, req, &body, maxConfigBodyBytes); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	if body.BodyRaw == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "body_raw is required", map[string]any{"field": "body_raw"})
+		return
+	}
+	publishAt, err := parseRFC3339("publish_at", body.PublishAt)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "publish_at"})
+		return
+	}
+	if !publishAt.After(time.Now()) {
+		writeError(w, http.StatusBadRequest, "bad_request", "publish_at must be in the future", map[string]any{"field": "publish_at"})
+		return
+	}
+	if body.MaxDelaySeconds != nil && *body.MaxDelaySeconds < 1 {
+		writeError(w, http.StatusBadRequest, "bad_request", "max_delay_seconds must be an integer >= 1", map[string]any{"field": "max_delay_seconds"})
+		return
+	}
+
+	// Scheduling bypasses review, so it is only available where direct updates are.
+	policy, err := storeGetNamespacePolicy(req.Context(), db, namespace)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if policy.RequiredApprovals > 0 {
+		writeError(w, http.StatusForbidden, "approval_required", "namespace requires approved drafts; scheduling is not available", map[string]any{
+			"required_approvals": policy.RequiredApprovals,
+		})
+		return
+	}
+	// Checked again at publish time in case the policy changes in between.
+	if _, err := checkComment(policy, body.Comment); err != nil {
+		writeHTTPError(w, err, "query failed")
+		return
+	}
+
+	cfg, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	_, parsedJSON, err := parseBody(cfg.Format, body.BodyRaw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	var scheduleID pgtype.UUID
+	err = db.QueryRow(req.Context(), `
+		INSERT INTO config_schedules (config_id, publish_at, base_version, max_delay_seconds, body_raw, body_json, content_sha256, created_by, comment, request_id, user_agent, source_ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`, cfgID, publishAt, body.BaseVersion, body.MaxDelaySeconds, body.BodyRaw, json.RawMessage(parsedJSON), sha256Hex(body.BodyRaw),
+		body.CreatedBy, body.Comment, reqID, userAgent, sourceIP).Scan(&scheduleID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert schedule failed", nil)
+		return
+	}
+
+	writeScheduleResponse(w, req, db, http.StatusCreated, cfgID, scheduleID)
+}
+
+func handleListSchedules(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	status, ok := getScheduleStatusFilter(w, req, "")
+	if !ok {
+		return
+	}
+	limit, err := parseLimit(req, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	offset, err := parseCursorOffset(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	_, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	rows, err := db.Query(req.Context(), `
+		SELECT `+scheduleMetaColumns+`
+		FROM config_schedules s
+		JOIN configs c ON c.id = s.config_id
+		WHERE s.config_id = $1
+		  AND ($2 = '' OR s.status = $2)
+		ORDER BY s.publish_at DESC, s.created_at DESC
+		LIMIT $3 OFFSET $4
+	`, cfgID, status, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeScheduleList(w, rows, limit, offset)
+}
+
+// handleListNamespaceSchedules lists scheduled changes across a namespace (pending only unless status is given).
+func handleListNamespaceSchedules(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	if err := validateNamespace(namespace); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	namespace = strings.TrimSpace(namespace)
+	status, ok := getScheduleStatusFilter(w, req, scheduleStatusPending)
+	if !ok {
+		return
+	}
+	prefix, err := parsePrefix(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	limit, err := parseLimit(req, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	offset, err := parseCursorOffset(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	ok, err = storeNamespaceExists(req.Context(), db, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+
+	rows, err := db.Query(req.Context(), `
+		SELECT `+scheduleMetaColumns+`
+		FROM config_schedules s
+		JOIN configs c ON c.id = s.config_id
+		WHERE c.namespace = $1
+		  AND c.deleted_at IS NULL
+		  AND ($2 = '' OR left(c.path, length($2::text)) = $2)
+		  AND ($3 = '' OR s.status = $3)
+		ORDER BY s.publish_at ASC, c.path ASC
+		LIMIT $4 OFFSET $5
+	`, namespace, prefix, status, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeScheduleList(w, rows, limit, offset)
+}
+
+func handleGetSchedule(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	scheduleID, ok := getScheduleID(w, req)
+	if !ok {
+		return
+	}
+
+	_, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeScheduleResponse(w, req, db, http.StatusOK, cfgID, scheduleID)
+}
+
+// handleRescheduleSchedule moves a pending schedule to a new publish_at.
+func handleRescheduleSchedule(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	scheduleID, ok := getScheduleID(w, req)
+	if !ok {
+		return
+	}
+
+	var body RescheduleRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	publishAt, err := parseRFC3339("publish_at", body.PublishAt)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "publish_at"})
+		return
+	}
+	if !publishAt.After(time.Now()) {
+		writeError(w, http.StatusBadRequest, "bad_request", "publish_at must be in the future", map[string]any{"field": "publish_at"})
+		return
+	}
+
+	_, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	if !updatePendingSchedule(w, req, db, cfgID, scheduleID, `UPDATE config_schedules SET publish_at = $3 WHERE id = $1 AND config_id = $2`, publishAt) {
+		return
+	}
+	writeScheduleResponse(w, req, db, http.StatusOK, cfgID, scheduleID)
+}
+
+func handleCancelSchedule(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	scheduleID, ok := getScheduleID(w, req)
+	if !ok {
+		return
+	}
+
+	_, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	if !updatePendingSchedule(w, req, db, cfgID, scheduleID, `UPDATE config_schedules SET status = $3 WHERE id = $1 AND config_id = $2`, scheduleStatusCancelled) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// updatePendingSchedule applies stmt ($1 = schedule id, $2 = config id, $3 = arg) to a schedule that is still pending.
+// The schedule row is locked first so the change cannot race with the scheduler publishing it.
+func updatePendingSchedule(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, cfgID, scheduleID pgtype.UUID, stmt string, arg any) bool {
+	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return false
+	}
+	defer tx.Rollback(req.Context())
+
+	var status string
+	err = tx.QueryRow(req.Context(), `
+		SELECT status FROM config_schedules WHERE id = $1 AND config_id = $2 FOR UPDATE
+	`, scheduleID, cfgID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "schedule not found", nil)
+		return false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return false
+	}
+	if status != scheduleStatusPending {
+		writeError(w, http.StatusConflict, "conflict", "schedule is not pending", map[string]any{"status": status})
+		return false
+	}
+
+	if _, err := tx.Exec(req.Context(), stmt, scheduleID, cfgID, arg); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "update schedule failed", nil)
+		return false
+	}
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return false
+	}
+	return true
+}
+
+func writeScheduleResponse(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, status int, cfgID, scheduleID pgtype.UUID) {
+	s, err := storeGetSchedule(req.Context(), db, cfgID, scheduleID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "schedule not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, status, s)
+}
+
+func writeScheduleList(w http.ResponseWriter, rows pgx.Rows, limit, offset int) {
+	defer rows.Close()
+
+	items := make([]ConfigScheduleMeta, 0, limit)
+	for rows.Next() {
+		m, err := scanConfigScheduleMeta(rows)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "scan failed", nil)
+			return
+		}
+		items = append(items, m)
+	}
+	if err := rows.Err(); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	var next *string
+	if len(items) == limit {
+		c := encodeCursorOffset(offset + limit)
+		next = &c
+	}
+	writeJSON(w, http.StatusOK, ScheduleListResponse{Items: items, NextCursor: next})
+}
+
+func getScheduleID(w http.ResponseWriter, req *http.Request) (pgtype.UUID, bool) {
+	id, err := parseUUID(chi.URLParam(req, "schedule"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "schedule must be a UUID", nil)
+		return pgtype.UUID{}, false
+	}
+	return id, true
+}
+
+// getScheduleStatusFilter reads ?status=; "all" disables filtering, an empty value falls back to def.
+func getScheduleStatusFilter(w http.ResponseWriter, req *http.Request, def string) (string, bool) {
+	status := strings.TrimSpace(req.URL.Query().Get("status"))
+	switch status {
+	case "":
+		return def, true
+	case "all":
+		return "", true
+	case scheduleStatusPending, scheduleStatusPublished, scheduleStatusCancelled, scheduleStatusFailed, scheduleStatusMissed:
+		return status, true
+	default:
+		writeError(w, http.StatusBadRequest, "bad_request", "status must be one of: pending, published, cancelled, failed, missed, all", nil)
+		return "", false
+	}
+}
@@ -0,0 +1,307 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func handleListTags(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+
+	_, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	items, err := storeListTags(req.Context(), db, cfgID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, TagListResponse{Items: items})
+}
+
+func handleSetTag(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	tag, ok := getTagName(w, req)
+	if !ok {
+		return
+	}
+
+	var body SetTagRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	if body.Version < 1 {
+		writeError(w, http.StatusBadRequest, "bad_request", "version must be an integer >= 1", map[string]any{"field": "version"})
+		return
+	}
+
+	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	// Lock config row so concurrent moves of the same tag serialize and the target version cannot be deleted underneath us.
+	var cfgID pgtype.UUID
+	err = tx.QueryRow(req.Context(), `
+		SELECT id
+		FROM configs
+		WHERE namespace = $1 AND path = $2
+		  AND deleted_at IS NULL
+		FOR UPDATE
+	`, namespace, path).Scan(&cfgID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	var verID pgtype.UUID
+	err = tx.QueryRow(req.Context(), `
+		SELECT id FROM config_versions WHERE config_id = $1 AND version = $2
+	`, cfgID, body.Version).Scan(&verID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "version not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	var fromVersion sql.NullInt32
+	err = tx.QueryRow(req.Context(), `
+		SELECT v.version
+		FROM config_tags t
+		JOIN config_versions v ON v.id = t.version_id
+		WHERE t.config_id = $1 AND t.name = $2
+	`, cfgID, tag).Scan(&fromVersion)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	created := !fromVersion.Valid
+
+	var t ConfigTag
+	if !fromVersion.Valid || int(fromVersion.Int32) != body.Version {
+		err = tx.QueryRow(req.Context(), `
+			INSERT INTO config_tags (config_id, name, version_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (config_id, name) DO UPDATE SET version_id = EXCLUDED.version_id
+			RETURNING created_at, updated_at
+		`, cfgID, tag, verID).Scan(&t.CreatedAt, &t.UpdatedAt)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "update tag failed", nil)
+			return
+		}
+
+		reqID, userAgent, sourceIP := requestAuditFields(req)
+		_, err = tx.Exec(req.Context(), `
+			INSERT INTO config_tag_history (config_id, tag, from_version, to_version, moved_by, comment, request_id, user_agent, source_ip)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, cfgID, tag, fromVersion, body.Version, body.MovedBy, body.Comment, reqID, userAgent, sourceIP)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "insert tag history failed", nil)
+			return
+		}
+	} else {
+		// Tag already points at the requested version: nothing to record.
+		err = tx.QueryRow(req.Context(), `
+			SELECT created_at, updated_at FROM config_tags WHERE config_id = $1 AND name = $2
+		`, cfgID, tag).Scan(&t.CreatedAt, &t.UpdatedAt)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+			return
+		}
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+
+	t.Name = tag
+	t.Version = body.Version
+	t.VersionID = uuidToString(verID)
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, t)
+}
+
+func handleDeleteTag(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	tag, ok := getTagName(w, req)
+	if !ok {
+		return
+	}
+
+	var body DeleteTagRequest
+	if req.ContentLength != 0 {
+		if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+			return
+		}
+	}
+	if v := strings.TrimSpace(req.URL.Query().Get("moved_by")); v != "" {
+		body.MovedBy = &v
+	}
+	if v := strings.TrimSpace(req.URL.Query().Get("comment")); v != "" {
+		body.Comment = &v
+	}
+
+	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	var cfgID pgtype.UUID
+	err = tx.QueryRow(req.Context(), `
+		SELECT id
+		FROM configs
+		WHERE namespace = $1 AND path = $2
+		  AND deleted_at IS NULL
+		FOR UPDATE
+	`, namespace, path).Scan(&cfgID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	var fromVersion int
+	err = tx.QueryRow(req.Context(), `
+		DELETE FROM config_tags t
+		USING config_versions v
+		WHERE v.id = t.version_id AND t.config_id = $1 AND t.name = $2
+		RETURNING v.version
+	`, cfgID, tag).Scan(&fromVersion)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "tag not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "delete failed", nil)
+		return
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	_, err = tx.Exec(req.Context(), `
+		INSERT INTO config_tag_history (config_id, tag, from_version, to_version, moved_by, comment, request_id, user_agent, source_ip)
+		VALUES ($1, $2, $3, NULL, $4, $5, $6, $7, $8)
+	`, cfgID, tag, fromVersion, body.MovedBy, body.Comment, reqID, userAgent, sourceIP)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert tag history failed", nil)
+		return
+	}
+
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func handleListTagHistory(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace, path, ok := getNamespaceAndPath(w, req)
+	if !ok {
+		return
+	}
+	tag, ok := getTagName(w, req)
+	if !ok {
+		return
+	}
+	limit, err := parseLimit(req, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	offset, err := parseCursorOffset(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	_, cfgID, err := storeGetConfigOnly(req.Context(), db, namespace, path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	rows, err := db.Query(req.Context(), `
+		SELECT id, tag, from_version, to_version, created_at, moved_by, comment
+		FROM config_tag_history
+		WHERE config_id = $1 AND tag = $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`, cfgID, tag, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	defer rows.Close()
+
+	items := make([]TagHistoryEntry, 0, limit)
+	for rows.Next() {
+		e, err := scanTagHistoryEntry(rows)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "scan failed", nil)
+			return
+		}
+		items = append(items, e)
+	}
+
+	var next *string
+	if len(items) == limit {
+		c := encodeCursorOffset(offset + limit)
+		next = &c
+	}
+	writeJSON(w, http.StatusOK, TagHistoryResponse{Items: items, NextCursor: next})
+}
+
+func getTagName(w http.ResponseWriter, req *http.Request) (string, bool) {
+	tag := strings.TrimSpace(chi.URLParam(req, "tag"))
+	if err := validateTagName(tag); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return "", false
+	}
+	return tag, true
+}
@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// handleListTicketVersions lists the versions whose comment referenced a ticket (see NamespacePolicy.TicketPattern),
+// newest first, optionally restricted to one namespace.
+func handleListTicketVersions(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	ticket := strings.TrimSpace(chi.URLParam(req, "ticket"))
+	if ticket == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "ticket is required", nil)
+		return
+	}
+	limit, err := parseLimit(req, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	offset, err := parseCursorOffset(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	namespace := strings.TrimSpace(req.URL.Query().Get("namespace"))
+
+	rows, err := db.Query(req.Context(), `
+		SELECT c.namespace, c.path, c.id,
+		       v.id, v.version, v.created_at, v.created_by, v.comment, v.content_sha256, v.changeset_id, v.ticket_refs
+		FROM config_versions v
+		JOIN configs c ON c.id = v.config_id AND c.deleted_at IS NULL
+		WHERE v.ticket_refs @> ARRAY[$1::text]
+		  AND ($2 = '' OR c.namespace = $2)
+		ORDER BY v.created_at DESC, v.id DESC
+		LIMIT $3 OFFSET $4
+	`, ticket, namespace, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	defer rows.Close()
+
+	items := make([]TicketVersion, 0, limit)
+	for rows.Next() {
+		var tv TicketVersion
+		var cfgID pgtype.UUID
+		tv.ConfigVersionMeta, err = scanConfigVersionMeta(prefixScanner{rows, []any{&tv.Namespace, &tv.Path, &cfgID}})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "scan failed", nil)
+			return
+		}
+		tv.ConfigID = uuidToString(cfgID)
+		items = append(items, tv)
+	}
+	if err := rows.Err(); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	var next *string
+	if len(items) == limit {
+		c := encodeCursorOffset(offset + limit)
+		next = &c
+	}
+	writeJSON(w, http.StatusOK, TicketVersionListResponse{Ticket: ticket, Items: items, NextCursor: next})
+}
@@ -0,0 +1,155 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// handleWatchConfig serves GET /configs/{namespace}/{path}?wait=...&after_version=N: it answers like a plain GET as
+// soon as latest is newer than after_version, or 304 once the wait elapses.
+func handleWatchConfig(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace, path string, wait time.Duration) {
+	raw := strings.TrimSpace(req.URL.Query().Get("after_version"))
+	afterVersion, err := strconv.Atoi(raw)
+	if raw == "" || err != nil || afterVersion < 0 {
+		writeError(w, http.StatusBadRequest, "bad_request", "after_version must be an integer >= 0 when wait is set", map[string]any{"field": "after_version"})
+		return
+	}
+
+	var cfg Config
+	var ver ConfigVersion
+	changed, err := longPoll(req.Context(), wait, namespace, path, "", func() (bool, error) {
+		var err error
+		cfg, ver, err = storeGetConfigAndLatest(req.Context(), db, namespace, path)
+		if err != nil {
+			return false, err
+		}
+		return ver.Version > afterVersion, nil
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		if redirectConfigAlias(w, req, db, namespace, path) {
+			return
+		}
+		writeError(w, http.StatusNotFound, "not_found", "config not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !changed {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	locks, err := storeFindLocks(req.Context(), db, namespace, []string{path}, maxListedLocks)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	resp := GetConfigResponse{Config: cfg, Latest: ver, Locks: locks}
+	b, err := json.Marshal(resp)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "encode failed", nil)
+		return
+	}
+	w.Header().Set("ETag", representationETag(configVersionETag(ver), b))
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleWatchFolder serves GET /namespaces/{namespace}/watch?prefix=...&after=TOKEN: it returns the latest version
+// of every active config under prefix together with a state token. Passing the token back as after (with wait) blocks
+// until a config under prefix is created, updated, deleted or moved, or answers 304 once the wait elapses.
+func handleWatchFolder(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool, namespace string) {
+	namespace, ok := getNamespaceParam(w, namespace)
+	if !ok {
+		return
+	}
+	prefix, err := parsePrefix(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	wait, _, err := parseWait(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "wait"})
+		return
+	}
+	after := strings.TrimSpace(req.URL.Query().Get("after"))
+
+	nsOK, err := storeNamespaceExists(req.Context(), db, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !nsOK {
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", nil)
+		return
+	}
+
+	var state FolderWatchResponse
+	changed, err := longPoll(req.Context(), wait, namespace, "", prefix, func() (bool, error) {
+		var err error
+		state, err = storeFolderState(req.Context(), db, namespace, prefix)
+		if err != nil {
+			return false, err
+		}
+		return state.Token != after, nil
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	if !changed {
+		w.Header().Set("ETag", `"`+state.Token+`"`)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", `"`+state.Token+`"`)
+	writeJSON(w, http.StatusOK, state)
+}
+
+// storeFolderState lists the active configs of namespace under prefix with their latest version. The token changes
+// whenever that list does.
+func storeFolderState(ctx context.Context, q querier, namespace, prefix string) (FolderWatchResponse, error) {
+	rows, err := q.Query(ctx, `
+		SELECT c.id, c.path, COALESCE(v.version, 0)
+		FROM configs c
+		LEFT JOIN config_versions v ON v.id = c.latest_version_id
+		WHERE c.namespace = $1
+		  AND c.deleted_at IS NULL
+		  AND ($2 = '' OR left(c.path, length($2::text)) = $2)
+		ORDER BY c.path ASC
+	`, namespace, prefix)
+	if err != nil {
+		return FolderWatchResponse{}, err
+	}
+	defer rows.Close()
+
+	state := FolderWatchResponse{Namespace: namespace, Prefix: prefix, Items: []FolderWatchItem{}}
+	var b strings.Builder
+	b.WriteString(namespace + "\x00" + prefix + "\x00")
+	for rows.Next() {
+		var id pgtype.UUID
+		var it FolderWatchItem
+		if err := rows.Scan(&id, &it.Path, &it.Version); err != nil {
+			return FolderWatchResponse{}, err
+		}
+		it.ConfigID = uuidToString(id)
+		state.Items = append(state.Items, it)
+		b.WriteString(it.ConfigID + ":" + it.Path + ":" + strconv.Itoa(it.Version) + "\n")
+	}
+	if err := rows.Err(); err != nil {
+		return FolderWatchResponse{}, err
+	}
+	state.Token = sha256Hex(b.String())[:32]
+	return state, nil
+}
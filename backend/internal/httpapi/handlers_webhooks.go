@@ -0,0 +1,435 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	maxWebhookNameLength   = 200
+	maxWebhookURLLength    = 2048
+	minWebhookSecretLength = 16
+	maxWebhookSecretLength = 200
+)
+
+// webhookSpec is the validated, writable part of a webhook. Namespace "" subscribes to every namespace.
+type webhookSpec struct {
+	Name       string
+	URL        string
+	Namespace  string
+	Prefix     string
+	EventTypes []string
+	Active     bool
+}
+
+// validate normalizes the spec in place and returns a 400 *httpError naming the offending field.
+func (s *webhookSpec) validate() error {
+	bad := func(field, msg string) error {
+		return &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: msg, Details: map[string]any{"field": field}}
+	}
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" || len(s.Name) > maxWebhookNameLength {
+		return bad("name", "name is required and must be at most 200 characters")
+	}
+	s.URL = strings.TrimSpace(s.URL)
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(s.URL) > maxWebhookURLLength {
+		return bad("url", "url must be an absolute http(s) URL of at most 2048 characters")
+	}
+	s.Namespace = strings.TrimSpace(s.Namespace)
+	if s.Namespace != "" {
+		if err := validateNamespace(s.Namespace); err != nil {
+			return bad("namespace", err.Error())
+		}
+	}
+	if s.Prefix, err = normalizePrefix(s.Prefix); err != nil {
+		return bad("prefix", err.Error())
+	}
+	types := make([]string, 0, len(s.EventTypes))
+	for _, t := range s.EventTypes {
+		t = strings.TrimSpace(t)
+		if !slices.Contains(webhookEventTypes, t) {
+			return bad("event_types", "unknown event type "+t+"; expected one of "+strings.Join(webhookEventTypes, ", "))
+		}
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	s.EventTypes = types
+	return nil
+}
+
+func validateWebhookSecret(secret string) error {
+	if n := len(secret); n < minWebhookSecretLength || n > maxWebhookSecretLength {
+		return &httpError{
+			Status:  http.StatusBadRequest,
+			Code:    "bad_request",
+			Message: "secret must be between 16 and 200 characters",
+			Details: map[string]any{"field": "secret"},
+		}
+	}
+	return nil
+}
+
+func getWebhookID(w http.ResponseWriter, req *http.Request) (pgtype.UUID, bool) {
+	id, err := parseUUID(chi.URLParam(req, "webhook"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "webhook must be a UUID", nil)
+		return pgtype.UUID{}, false
+	}
+	return id, true
+}
+
+// writeWebhookStoreError maps a failed webhook insert/update to a response.
+func writeWebhookStoreError(w http.ResponseWriter, err error, fallback string) {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		writeError(w, http.StatusNotFound, "not_found", "webhook not found", nil)
+	case errors.As(err, &pgErr) && pgErr.Code == "23503":
+		writeError(w, http.StatusNotFound, "not_found", "namespace not found", map[string]any{"field": "namespace"})
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", fallback, nil)
+	}
+}
+
+// handleCreateWebhook subscribes a URL to events. Without a secret one is generated; the secret is only returned here
+// (and when rotated).
+func handleCreateWebhook(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	var body CreateWebhookRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	spec := webhookSpec{Name: body.Name, URL: body.URL, Namespace: body.Namespace, Prefix: body.Prefix, EventTypes: body.EventTypes, Active: true}
+	if body.Active != nil {
+		spec.Active = *body.Active
+	}
+	if err := spec.validate(); err != nil {
+		writeHTTPError(w, err, "invalid webhook")
+		return
+	}
+	secret := body.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newWebhookSecret(); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "secret generation failed", nil)
+			return
+		}
+	} else if err := validateWebhookSecret(secret); err != nil {
+		writeHTTPError(w, err, "invalid secret")
+		return
+	}
+
+	reqID, userAgent, sourceIP := requestAuditFields(req)
+	hook, err := scanWebhook(db.QueryRow(req.Context(), `
+		WITH w AS (
+			INSERT INTO webhooks (name, url, secret, namespace, prefix, event_types, active, request_id, user_agent, source_ip)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10)
+			RETURNING *
+		)
+		SELECT `+webhookColumns+` FROM w
+	`, spec.Name, spec.URL, secret, spec.Namespace, spec.Prefix, spec.EventTypes, spec.Active, reqID, userAgent, sourceIP))
+	if err != nil {
+		writeWebhookStoreError(w, err, "insert failed")
+		return
+	}
+	hook.Secret = secret
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+// handleListWebhooks lists webhooks; with ?namespace= those that receive events of that namespace.
+func handleListWebhooks(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	namespace := strings.TrimSpace(req.URL.Query().Get("namespace"))
+	if namespace != "" {
+		if err := validateNamespace(namespace); err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), map[string]any{"field": "namespace"})
+			return
+		}
+	}
+	hooks, err := storeListWebhooks(req.Context(), db, namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, WebhookListResponse{Items: hooks})
+}
+
+func handleGetWebhook(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	id, ok := getWebhookID(w, req)
+	if !ok {
+		return
+	}
+	hook, err := storeGetWebhook(req.Context(), db, id)
+	if err != nil {
+		writeWebhookStoreError(w, err, "query failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// handleUpdateWebhook changes the given fields of a webhook (namespace "" = every namespace). rotate_secret replaces
+// the secret with a generated one and returns it; secret sets it explicitly. Pending deliveries keep their payload
+// but are sent to the new URL with the new secret.
+func handleUpdateWebhook(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	id, ok := getWebhookID(w, req)
+	if !ok {
+		return
+	}
+	var body UpdateWebhookRequest
+	if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	if body.Secret != nil && body.RotateSecret {
+		writeError(w, http.StatusBadRequest, "bad_request", "secret and rotate_secret cannot be combined", nil)
+		return
+	}
+
+	tx, err := db.BeginTx(req.Context(), pgx.TxOptions{})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "tx begin failed", nil)
+		return
+	}
+	defer tx.Rollback(req.Context())
+
+	hook, err := scanWebhook(tx.QueryRow(req.Context(), `SELECT `+webhookColumns+` FROM webhooks w WHERE w.id = $1 FOR UPDATE`, id))
+	if err != nil {
+		writeWebhookStoreError(w, err, "query failed")
+		return
+	}
+	spec := webhookSpec{Name: hook.Name, URL: hook.URL, Prefix: hook.Prefix, EventTypes: hook.EventTypes, Active: hook.Active}
+	if hook.Namespace != nil {
+		spec.Namespace = *hook.Namespace
+	}
+	if body.Name != nil {
+		spec.Name = *body.Name
+	}
+	if body.URL != nil {
+		spec.URL = *body.URL
+	}
+	if body.Namespace != nil {
+		spec.Namespace = *body.Namespace
+	}
+	if body.Prefix != nil {
+		spec.Prefix = *body.Prefix
+	}
+	if body.EventTypes != nil {
+		spec.EventTypes = *body.EventTypes
+	}
+	if body.Active != nil {
+		spec.Active = *body.Active
+	}
+	if err := spec.validate(); err != nil {
+		writeHTTPError(w, err, "invalid webhook")
+		return
+	}
+	var secret *string
+	switch {
+	case body.RotateSecret:
+		s, err := newWebhookSecret()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal_error", "secret generation failed", nil)
+			return
+		}
+		secret = &s
+	case body.Secret != nil:
+		if err := validateWebhookSecret(*body.Secret); err != nil {
+			writeHTTPError(w, err, "invalid secret")
+			return
+		}
+		secret = body.Secret
+	}
+
+	hook, err = scanWebhook(tx.QueryRow(req.Context(), `
+		WITH w AS (
+			UPDATE webhooks
+			SET name = $2, url = $3, namespace = NULLIF($4, ''), prefix = $5, event_types = $6, active = $7,
+			    secret = COALESCE($8, secret)
+			WHERE id = $1
+			RETURNING *
+		)
+		SELECT `+webhookColumns+` FROM w
+	`, id, spec.Name, spec.URL, spec.Namespace, spec.Prefix, spec.EventTypes, spec.Active, secret))
+	if err != nil {
+		writeWebhookStoreError(w, err, "update failed")
+		return
+	}
+	if err := tx.Commit(req.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "commit failed", nil)
+		return
+	}
+	if body.RotateSecret {
+		hook.Secret = *secret
+	}
+	if hook.Active {
+		wakeWebhookDispatcher()
+	}
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// handleDeleteWebhook deletes a webhook together with its delivery log; pending deliveries are dropped.
+func handleDeleteWebhook(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	id, ok := getWebhookID(w, req)
+	if !ok {
+		return
+	}
+	var deleted pgtype.UUID
+	err := db.QueryRow(req.Context(), `DELETE FROM webhooks WHERE id = $1 RETURNING id`, id).Scan(&deleted)
+	if err != nil {
+		writeWebhookStoreError(w, err, "delete failed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListWebhookDeliveries lists the delivery log of a webhook, newest first; ?status= filters by status.
+func handleListWebhookDeliveries(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	id, ok := getWebhookID(w, req)
+	if !ok {
+		return
+	}
+	status := strings.TrimSpace(req.URL.Query().Get("status"))
+	switch status {
+	case "", deliveryPending, deliverySucceeded, deliveryDead:
+	default:
+		writeError(w, http.StatusBadRequest, "bad_request", "status must be pending, succeeded or dead", map[string]any{"field": "status"})
+		return
+	}
+	limit, err := parseLimit(req, 50)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+	offset, err := parseCursorOffset(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
+		return
+	}
+
+	if _, err := storeGetWebhook(req.Context(), db, id); err != nil {
+		writeWebhookStoreError(w, err, "query failed")
+		return
+	}
+	items, err := storeListWebhookDeliveries(req.Context(), db, id, status, limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+
+	var next *string
+	if len(items) == limit {
+		c := encodeCursorOffset(offset + limit)
+		next = &c
+	}
+	writeJSON(w, http.StatusOK, WebhookDeliveryListResponse{Items: items, NextCursor: next})
+}
+
+// handleGetWebhookDelivery returns one delivery including the payload that was (or will be) sent.
+func handleGetWebhookDelivery(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	id, ok := getWebhookID(w, req)
+	if !ok {
+		return
+	}
+	deliveryID, err := parseUUID(chi.URLParam(req, "delivery"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "delivery must be a UUID", nil)
+		return
+	}
+	d, err := storeGetWebhookDelivery(req.Context(), db, id, deliveryID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "delivery not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// handleReplayWebhookDelivery queues a new delivery of the same payload (e.g. a dead one after fixing the receiver).
+// The original is left unchanged; the replay references it in replay_of.
+func handleReplayWebhookDelivery(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	id, ok := getWebhookID(w, req)
+	if !ok {
+		return
+	}
+	deliveryID, err := parseUUID(chi.URLParam(req, "delivery"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "delivery must be a UUID", nil)
+		return
+	}
+
+	var replayID pgtype.UUID
+	err = db.QueryRow(req.Context(), `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, replay_of)
+		SELECT webhook_id, event_id, event_type, payload, id
+		FROM webhook_deliveries
+		WHERE id = $1 AND webhook_id = $2
+		RETURNING id
+	`, deliveryID, id).Scan(&replayID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "not_found", "delivery not found", nil)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "insert failed", nil)
+		return
+	}
+	wakeWebhookDispatcher()
+
+	d, err := storeGetWebhookDelivery(req.Context(), db, id, replayID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusCreated, d)
+}
+
+// handleTestWebhook sends a webhook.test event to the webhook right away (also when it is inactive) and returns the
+// recorded delivery. Test deliveries are attempted once and not retried.
+func handleTestWebhook(w http.ResponseWriter, req *http.Request, db *pgxpool.Pool) {
+	id, ok := getWebhookID(w, req)
+	if !ok {
+		return
+	}
+
+	a := webhookAttempt{EventType: eventWebhookTest, Payload: webhookTestPayload(uuidToString(id)), Attempts: 1}
+	// Leased like a claimed delivery so the dispatcher leaves it alone while it is being sent.
+	err := db.QueryRow(req.Context(), `
+		WITH d AS (
+			INSERT INTO webhook_deliveries (webhook_id, event_type, payload, attempts, last_attempt_at, next_attempt_at)
+			SELECT w.id, $2::text, $3::jsonb, 1, now(), now() + $4::int * interval '1 second'
+			FROM webhooks w
+			WHERE w.id = $1
+			RETURNING id, webhook_id
+		)
+		SELECT d.id, w.url, w.secret FROM d JOIN webhooks w ON w.id = d.webhook_id
+	`, id, a.EventType, a.Payload, int(webhookLease().Seconds())).Scan(&a.ID, &a.URL, &a.Secret)
+	if err != nil {
+		writeWebhookStoreError(w, err, "insert failed")
+		return
+	}
+
+	code, sendErr := a.send(req.Context())
+	if err := storeRecordWebhookAttempt(req.Context(), db, a, code, sendErr, 0); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "update failed", nil)
+		return
+	}
+	d, err := storeGetWebhookDelivery(req.Context(), db, id, a.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "query failed", nil)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
@@ -0,0 +1,138 @@
+package httpapi
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const (
+	// maxImportBodyBytes bounds an import upload and, separately, the total size of the files extracted from it.
+	maxImportBodyBytes = int64(50 << 20) // 50 MiB
+	maxImportFiles     = 1000
+)
+
+// importFile is a regular file of an import archive; Name is slash-separated and relative to the archive root.
+type importFile struct {
+	Name string
+	Data []byte
+}
+
+// readImportArchive extracts the files of a tar, gzip-compressed tar or zip archive (detected from its first bytes).
+// Directories, hidden files and folders (".git", ".DS_Store") and "__MACOSX" are ignored. A root manifest.json is
+// returned separately as the manifest of an export archive. Errors are *httpError.
+func readImportArchive(data []byte) ([]importFile, *ExportManifest, error) {
+	var files []importFile
+	var manifest *ExportManifest
+	var total int64
+	add := func(name string, r io.Reader) error {
+		name, skip, err := importEntryName(name)
+		if err != nil || skip {
+			return err
+		}
+		b, err := io.ReadAll(io.LimitReader(r, maxImportBodyBytes-total+1))
+		if err != nil {
+			return importArchiveError("read "+name+": "+err.Error(), nil)
+		}
+		if total += int64(len(b)); total > maxImportBodyBytes {
+			return importArchiveError(fmt.Sprintf("archive expands to more than %d bytes", maxImportBodyBytes), nil)
+		}
+		if name == exportManifestName {
+			manifest = &ExportManifest{}
+			if err := json.Unmarshal(b, manifest); err != nil {
+				return importArchiveError("manifest.json is not an export manifest: "+err.Error(), nil)
+			}
+			return nil
+		}
+		if len(files) == maxImportFiles {
+			return importArchiveError(fmt.Sprintf("an import can have at most %d files", maxImportFiles), nil)
+		}
+		files = append(files, importFile{Name: name, Data: b})
+		return nil
+	}
+
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06")):
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, nil, importArchiveError("invalid zip archive: "+err.Error(), nil)
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, nil, importArchiveError("invalid zip archive: "+err.Error(), map[string]any{"file": f.Name})
+			}
+			err = add(f.Name, rc)
+			rc.Close()
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	default:
+		var r io.Reader = bytes.NewReader(data)
+		if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+			gz, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, nil, importArchiveError("invalid gzip stream: "+err.Error(), nil)
+			}
+			r = gz
+		}
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, nil, importArchiveError("invalid tar archive: "+err.Error(), nil)
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			if err := add(hdr.Name, tr); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return files, manifest, nil
+}
+
+// importEntryName cleans an archive entry name and reports whether the entry is ignored.
+func importEntryName(name string) (string, bool, error) {
+	clean := strings.Trim(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+	for i, seg := range strings.Split(strings.Trim(strings.ReplaceAll(name, "\\", "/"), "/"), "/") {
+		if seg == ".." {
+			return "", false, importArchiveError("file names must not contain '..'", map[string]any{"file": name})
+		}
+		if (strings.HasPrefix(seg, ".") && seg != ".") || (i == 0 && seg == "__MACOSX") {
+			return "", true, nil
+		}
+	}
+	return clean, clean == "", nil
+}
+
+func importArchiveError(msg string, details map[string]any) error {
+	return &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: msg, Details: details}
+}
+
+// importFormat infers a config format from a file extension; ok is false for other files.
+func importFormat(name string) (ConfigFormat, bool) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return FormatJSON, true
+	case ".yaml", ".yml":
+		return FormatYAML, true
+	}
+	return "", false
+}
@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"config-manager/internal/commons"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// changeChannel is the NOTIFY channel written by the config_events trigger (migration 000014).
+	changeChannel = "config_changes"
+	// listenerRetryPause separates rounds of reconnect attempts once commons.RetryWithBackoff gives up.
+	listenerRetryPause = time.Minute
+)
+
+// changeNotification is the payload of a changeChannel notification.
+type changeNotification struct {
+	ID            int64   `json:"id"`
+	Type          string  `json:"type"`
+	Namespace     string  `json:"namespace"`
+	Path          *string `json:"path"`
+	FromNamespace *string `json:"from_namespace"`
+	FromPath      *string `json:"from_path"`
+}
+
+// RunChangeListener LISTENs for committed changes from every replica and wakes the local watchers and event streams
+// until ctx is cancelled. The connection is taken out of pool; when it fails the listener reconnects (with
+// commons.RetryWithBackoff, then again after a pause if every attempt fails). Notifications sent while disconnected
+// are lost, so every (re)connect wakes all local watchers to re-check.
+func RunChangeListener(ctx context.Context, db *pgxpool.Pool, maxAttempts int, initialBackoff time.Duration) {
+	for ctx.Err() == nil {
+		var conn *pgx.Conn
+		err := commons.RetryWithBackoff(maxAttempts, initialBackoff, func() error {
+			var err error
+			conn, err = listenConn(ctx, db)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("listener: connect failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(listenerRetryPause):
+			}
+			continue
+		}
+
+		configChanges.notifyAll()
+		err = receiveChanges(ctx, conn)
+		conn.Close(context.Background())
+		if ctx.Err() == nil {
+			log.Printf("listener: connection lost, reconnecting: %v", err)
+		}
+	}
+}
+
+// listenConn takes a dedicated connection out of the pool and subscribes it to changeChannel.
+func listenConn(ctx context.Context, db *pgxpool.Pool) (*pgx.Conn, error) {
+	pc, err := db.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn := pc.Hijack()
+	if _, err := conn.Exec(ctx, "LISTEN "+changeChannel); err != nil {
+		conn.Close(context.Background())
+		return nil, err
+	}
+	return conn, nil
+}
+
+func receiveChanges(ctx context.Context, conn *pgx.Conn) error {
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var c changeNotification
+		if err := json.Unmarshal([]byte(n.Payload), &c); err != nil {
+			log.Printf("listener: bad payload %q: %v", n.Payload, err)
+			continue
+		}
+		if c.Path == nil {
+			configChanges.notify(c.Namespace)
+		} else {
+			configChanges.notify(c.Namespace, *c.Path)
+		}
+		if c.FromNamespace != nil {
+			if c.FromPath == nil {
+				configChanges.notify(*c.FromNamespace)
+			} else {
+				configChanges.notify(*c.FromNamespace, *c.FromPath)
+			}
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergeConflict is one key path that both sides changed differently. A side is omitted when the key is absent there.
+type mergeConflict struct {
+	Path      string          `json:"path"`
+	Base      json.RawMessage `json:"base,omitempty"`
+	Current   json.RawMessage `json:"current,omitempty"`
+	Submitted json.RawMessage `json:"submitted,omitempty"`
+}
+
+// missingValue marks a key that is absent on one side of a merge.
+type missingValue struct{}
+
+// mergeJSON three-way merges submitted into current relative to base (all decoded with decodeJSONNumbers).
+//
+// Objects are merged key by key. Arrays and scalars are merged as a whole: if both sides changed the same
+// array differently, the whole array is a conflict.
+func mergeJSON(base, current, submitted any) (any, []mergeConflict) {
+	var conflicts []mergeConflict
+	merged := mergeValue("", base, current, submitted, &conflicts)
+	return merged, conflicts
+}
+
+func mergeValue(pointer string, base, current, submitted any, conflicts *[]mergeConflict) any {
+	switch {
+	case jsonEqual(current, submitted):
+		return current
+	case jsonEqual(base, current):
+		return submitted
+	case jsonEqual(base, submitted):
+		return current
+	}
+
+	baseObj, baseOK := base.(map[string]any)
+	curObj, curOK := current.(map[string]any)
+	subObj, subOK := submitted.(map[string]any)
+	if _, missing := base.(missingValue); missing {
+		// Key added on both sides: merge the additions if both are objects.
+		baseObj, baseOK = map[string]any{}, true
+	}
+	if !baseOK || !curOK || !subOK {
+		*conflicts = append(*conflicts, mergeConflict{
+			Path:      pointer,
+			Base:      conflictValue(base),
+			Current:   conflictValue(current),
+			Submitted: conflictValue(submitted),
+		})
+		return current
+	}
+
+	keys := map[string]struct{}{}
+	for _, m := range []map[string]any{baseObj, curObj, subObj} {
+		for k := range m {
+			keys[k] = struct{}{}
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	out := make(map[string]any, len(keys))
+	for _, k := range sorted {
+		v := mergeValue(pointer+"/"+escapeJSONPointer(k), lookupKey(baseObj, k), lookupKey(curObj, k), lookupKey(subObj, k), conflicts)
+		if _, missing := v.(missingValue); !missing {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func lookupKey(m map[string]any, k string) any {
+	if v, ok := m[k]; ok {
+		return v
+	}
+	return missingValue{}
+}
+
+func jsonEqual(a, b any) bool {
+	_, aMissing := a.(missingValue)
+	_, bMissing := b.(missingValue)
+	if aMissing || bMissing {
+		return aMissing == bMissing
+	}
+	return canonicalJSON(a) == canonicalJSON(b)
+}
+
+func conflictValue(v any) json.RawMessage {
+	if _, missing := v.(missingValue); missing {
+		return nil
+	}
+	return json.RawMessage(canonicalJSON(v))
+}
+
+// renderBody serializes a merged document back to body_raw in the config's format. JSON is re-encoded (indented).
+// YAML is rendered from the current latest document (currentRaw) with only the merged changes applied, taking changed
+// parts from submittedRaw, so comments, key order and scalar styles survive (see mergeYAMLNode).
+func renderBody(format ConfigFormat, currentRaw, submittedRaw string, merged any) (string, error) {
+	switch format {
+	case FormatJSON:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(merged); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	case FormatYAML:
+		var current, submitted yaml.Node
+		if err := yaml.Unmarshal([]byte(currentRaw), &current); err != nil {
+			return "", err
+		}
+		if err := yaml.Unmarshal([]byte(submittedRaw), &submitted); err != nil {
+			return "", err
+		}
+		if len(current.Content) != 1 || len(submitted.Content) != 1 {
+			return "", errMergeUnsupported
+		}
+		if yamlHasAliases(&current) || yamlHasAliases(&submitted) {
+			return "", errMergeUnsupported
+		}
+		root, err := mergeYAMLNode(current.Content[0], submitted.Content[0], merged)
+		if err != nil {
+			return "", err
+		}
+		current.Content[0] = root
+
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&current); err != nil {
+			return "", err
+		}
+		if err := enc.Close(); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	default:
+		return "", errors.New("unknown format")
+	}
+}
+
+// errMergeUnsupported is returned by renderBody for YAML documents it cannot merge without losing structure
+// (anchors, aliases or merge keys, several documents).
+var errMergeUnsupported = errors.New("YAML with anchors, aliases or several documents cannot be merged automatically")
+
+// mergeYAMLNode returns node changed to hold merged. Subtrees whose value already equals merged are kept as they
+// are; mappings are patched key by key (current keys keep their order, new keys follow in submitted order);
+// other changed values are taken from the submitted node when it holds exactly that value, else encoded afresh.
+// submitted may be nil when the key is absent from the submitted document.
+func mergeYAMLNode(node, submitted *yaml.Node, merged any) (*yaml.Node, error) {
+	if v, ok := yamlNodeValue(node); ok && jsonEqual(v, merged) {
+		return node, nil
+	}
+	mergedObj, isObj := merged.(map[string]any)
+	if node.Kind != yaml.MappingNode || !isObj {
+		if submitted != nil {
+			if v, ok := yamlNodeValue(submitted); ok && jsonEqual(v, merged) {
+				return submitted, nil
+			}
+		}
+		var fresh yaml.Node
+		if err := fresh.Encode(yamlNumbers(merged)); err != nil {
+			return nil, err
+		}
+		return &fresh, nil
+	}
+
+	submittedPairs := map[string][2]*yaml.Node{}
+	var submittedOrder []string
+	if submitted != nil && submitted.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(submitted.Content); i += 2 {
+			k := yamlKeyString(submitted.Content[i])
+			submittedPairs[k] = [2]*yaml.Node{submitted.Content[i], submitted.Content[i+1]}
+			submittedOrder = append(submittedOrder, k)
+		}
+	}
+
+	out := *node
+	out.Content = make([]*yaml.Node, 0, 2*len(mergedObj))
+	seen := make(map[string]bool, len(mergedObj))
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k := yamlKeyString(node.Content[i])
+		mv, ok := mergedObj[k]
+		if !ok {
+			continue
+		}
+		v, err := mergeYAMLNode(node.Content[i+1], submittedPairs[k][1], mv)
+		if err != nil {
+			return nil, err
+		}
+		out.Content = append(out.Content, node.Content[i], v)
+		seen[k] = true
+	}
+	added := make([]string, 0)
+	for _, k := range submittedOrder {
+		if _, ok := mergedObj[k]; ok && !seen[k] {
+			added = append(added, k)
+			seen[k] = true
+		}
+	}
+	var rest []string
+	for k := range mergedObj {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range append(added, rest...) {
+		keyNode := submittedPairs[k][0]
+		if keyNode == nil {
+			keyNode = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}
+		}
+		v := submittedPairs[k][1]
+		if v == nil {
+			v = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		merged, err := mergeYAMLNode(v, v, mergedObj[k])
+		if err != nil {
+			return nil, err
+		}
+		out.Content = append(out.Content, keyNode, merged)
+	}
+	return &out, nil
+}
+
+// yamlNodeValue decodes node the way parseBody decodes a document, as JSON values with json.Number.
+func yamlNodeValue(node *yaml.Node) (any, bool) {
+	var v any
+	if err := node.Decode(&v); err != nil {
+		return nil, false
+	}
+	b, err := json.Marshal(normalizeYAML(v))
+	if err != nil {
+		return nil, false
+	}
+	return decodeJSONNumbers(b)
+}
+
+// yamlKeyString is the key a mapping key node gets in parseBody's decoded document.
+func yamlKeyString(node *yaml.Node) string {
+	var k any
+	if err := node.Decode(&k); err != nil {
+		return node.Value
+	}
+	return asString(k)
+}
+
+func yamlHasAliases(node *yaml.Node) bool {
+	if node.Kind == yaml.AliasNode || node.Anchor != "" || node.Tag == "!!merge" {
+		return true
+	}
+	for _, c := range node.Content {
+		if yamlHasAliases(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlNumbers converts json.Number values to int64/float64 so YAML emits them unquoted.
+func yamlNumbers(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, vv := range t {
+			out[k] = yamlNumbers(vv)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i := range t {
+			out[i] = yamlNumbers(t[i])
+		}
+		return out
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	default:
+		return t
+	}
+}
@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func mustJSON(t *testing.T, raw string) any {
+	t.Helper()
+	v, ok := decodeJSONNumbers([]byte(raw))
+	if !ok {
+		t.Fatalf("invalid JSON %q", raw)
+	}
+	return v
+}
+
+func TestMergeJSON(t *testing.T) {
+	cases := []struct {
+		name      string
+		base      string
+		current   string
+		submitted string
+		want      string
+		conflicts []string
+	}{
+		{
+			name:      "disjoint key edits",
+			base:      `{"a":1,"b":1}`,
+			current:   `{"a":2,"b":1}`,
+			submitted: `{"a":1,"b":3}`,
+			want:      `{"a":2,"b":3}`,
+		},
+		{
+			name:      "same change on both sides",
+			base:      `{"a":1}`,
+			current:   `{"a":2}`,
+			submitted: `{"a":2}`,
+			want:      `{"a":2}`,
+		},
+		{
+			name:      "delete vs modify",
+			base:      `{"a":1,"b":1}`,
+			current:   `{"b":1}`,
+			submitted: `{"a":5,"b":1}`,
+			conflicts: []string{"/a"},
+		},
+		{
+			name:      "modify vs delete",
+			base:      `{"a":1,"b":1}`,
+			current:   `{"a":5,"b":1}`,
+			submitted: `{"b":1}`,
+			conflicts: []string{"/a"},
+		},
+		{
+			name:      "delete on one side only",
+			base:      `{"a":1,"b":1}`,
+			current:   `{"a":1,"b":2}`,
+			submitted: `{"b":1}`,
+			want:      `{"b":2}`,
+		},
+		{
+			name:      "add/add objects merge key by key",
+			base:      `{}`,
+			current:   `{"db":{"host":"x"}}`,
+			submitted: `{"db":{"port":5432}}`,
+			want:      `{"db":{"host":"x","port":5432}}`,
+		},
+		{
+			name:      "add/add different scalars",
+			base:      `{}`,
+			current:   `{"a":1}`,
+			submitted: `{"a":2}`,
+			conflicts: []string{"/a"},
+		},
+		{
+			name:      "array changed on one side",
+			base:      `{"l":[1,2]}`,
+			current:   `{"l":[1,2],"x":1}`,
+			submitted: `{"l":[1,2,3]}`,
+			want:      `{"l":[1,2,3],"x":1}`,
+		},
+		{
+			name:      "array changed on both sides",
+			base:      `{"l":[1,2]}`,
+			current:   `{"l":[0,1,2]}`,
+			submitted: `{"l":[1,2,3]}`,
+			conflicts: []string{"/l"},
+		},
+		{
+			name:      "nested conflict path is escaped",
+			base:      `{"a/b":{"c":1}}`,
+			current:   `{"a/b":{"c":2}}`,
+			submitted: `{"a/b":{"c":3}}`,
+			conflicts: []string{"/a~1b/c"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, conflicts := mergeJSON(mustJSON(t, tc.base), mustJSON(t, tc.current), mustJSON(t, tc.submitted))
+			var paths []string
+			for _, c := range conflicts {
+				paths = append(paths, c.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tc.conflicts, ",") {
+				t.Fatalf("conflicts = %v, want %v", paths, tc.conflicts)
+			}
+			if len(tc.conflicts) > 0 {
+				return
+			}
+			if !jsonEqual(merged, mustJSON(t, tc.want)) {
+				t.Fatalf("merged = %v, want %s", merged, tc.want)
+			}
+		})
+	}
+}
+
+func TestRenderBodyYAMLKeepsLayout(t *testing.T) {
+	current := `# service settings
+name: api   # display name
+replicas: 2
+db:
+  host: db.internal
+  port: 5432
+`
+	submitted := `db:
+  port: 6432
+  host: db.internal
+name: api
+replicas: 2
+timeout: 30s
+`
+	merged := mustJSON(t, `{"name":"api","replicas":3,"db":{"host":"db.internal","port":6432},"timeout":"30s"}`)
+
+	got, err := renderBody(FormatYAML, current, submitted, merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# service settings
+name: api # display name
+replicas: 3
+db:
+  host: db.internal
+  port: 6432
+timeout: 30s
+`
+	if got != want {
+		t.Fatalf("renderBody =\n%s\nwant\n%s", got, want)
+	}
+	_, j, err := parseBody(FormatYAML, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !jsonEqual(mustJSON(t, string(j)), merged) {
+		t.Fatalf("rendered body does not decode to the merged value")
+	}
+}
+
+func TestRenderBodyYAMLRefusesAliases(t *testing.T) {
+	current := "base: &b\n  x: 1\nother: *b\n"
+	submitted := "base: &b\n  x: 2\nother: *b\n"
+	merged := mustJSON(t, `{"base":{"x":2},"other":{"x":1}}`)
+	if _, err := renderBody(FormatYAML, current, submitted, merged); err != errMergeUnsupported {
+		t.Fatalf("err = %v, want errMergeUnsupported", err)
+	}
+}
@@ -0,0 +1,125 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// namespaceAliasMiddleware serves requests addressed to the old name of a renamed namespace while its alias is live:
+// reads get 308 Permanent Redirect to the same URL under the new name, writes are refused with 409 namespace_renamed.
+// The alias is only looked up when the handler answers 404 (an aliased name has no namespace row, so every handler
+// reports it as not found), keeping the extra query off the normal path. It must run where the route has a
+// {namespace} URL parameter.
+func namespaceAliasMiddleware(db *pgxpool.Pool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(&aliasFallbackWriter{ResponseWriter: w, req: req, db: db}, req)
+		})
+	}
+}
+
+// aliasFallbackWriter replaces a handler's 404 with the alias response when the namespace has a live alias; the
+// handler's own body is then discarded.
+type aliasFallbackWriter struct {
+	http.ResponseWriter
+	req         *http.Request
+	db          *pgxpool.Pool
+	wroteHeader bool
+	replaced    bool
+}
+
+func (w *aliasFallbackWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if status == http.StatusNotFound && w.serveAlias() {
+		w.replaced = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *aliasFallbackWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.replaced {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer (flushing event streams).
+func (w *aliasFallbackWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// serveAlias writes the redirect or namespace_renamed response if the request's namespace has a live alias.
+func (w *aliasFallbackWriter) serveAlias() bool {
+	namespace := chi.URLParam(w.req, "namespace")
+	target, err := storeResolveNamespaceAlias(w.req.Context(), w.db, namespace)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		log.Printf("namespace alias: resolve %q: %v", namespace, err)
+		return false
+	}
+
+	// The handler already set its headers (e.g. Content-Type); writeError sets its own.
+	h := w.ResponseWriter.Header()
+	for k := range h {
+		delete(h, k)
+	}
+	details := map[string]any{"namespace": target}
+	if w.req.Method != http.MethodGet && w.req.Method != http.MethodHead {
+		writeError(w.ResponseWriter, http.StatusConflict, "namespace_renamed", "namespace was renamed; use the new name", details)
+		return true
+	}
+	h.Set("Location", replaceNamespaceInURL(w.req, namespace, target))
+	writeError(w.ResponseWriter, http.StatusPermanentRedirect, "moved", "namespace was renamed", details)
+	return true
+}
+
+// replaceNamespaceInURL rewrites the namespace segment of /configs/{namespace}/... or /namespaces/{namespace}/...,
+// keeping any base path, the rest of the path and the query.
+func replaceNamespaceInURL(req *http.Request, from, to string) string {
+	loc := req.URL.Path
+	for _, seg := range []string{"/configs/", "/namespaces/"} {
+		i := strings.Index(loc, seg+from)
+		if i < 0 {
+			continue
+		}
+		end := i + len(seg) + len(from)
+		if end == len(loc) || loc[end] == '/' {
+			loc = loc[:i] + seg + to + loc[end:]
+			break
+		}
+	}
+	if req.URL.RawQuery != "" {
+		loc += "?" + req.URL.RawQuery
+	}
+	return loc
+}
+
+// storeResolveNamespaceAlias returns the current name of a renamed namespace while the alias is live and no
+// namespace has taken the old name since.
+func storeResolveNamespaceAlias(ctx context.Context, q querier, name string) (string, error) {
+	var target string
+	err := q.QueryRow(ctx, `
+		SELECT a.namespace
+		FROM namespace_aliases a
+		WHERE a.name = $1
+		  AND a.expires_at > now()
+		  AND NOT EXISTS (SELECT 1 FROM namespaces n WHERE n.name = a.name)
+	`, name).Scan(&target)
+	return target, err
+}
@@ -6,15 +6,10 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
-type ConfigFormat string
-
-const (
-	FormatJSON       ConfigFormat = "json"
-	FormatYAML       ConfigFormat = "yaml"
-	maxCursorOffset               = 100_000
-)
+const maxCursorOffset = 100_000
 
 func parseOptionalBool(req *http.Request, key string) (bool, bool, error) {
 	raw := strings.TrimSpace(req.URL.Query().Get(key))
@@ -79,7 +74,12 @@ func encodeCursorOffset(offset int) string {
 }
 
 func parsePrefix(req *http.Request) (string, error) {
-	prefix := strings.TrimSpace(req.URL.Query().Get("prefix"))
+	return normalizePrefix(req.URL.Query().Get("prefix"))
+}
+
+// normalizePrefix turns a folder prefix into the "a/b/" form used with LIKE prefix || '%' ("" means everything).
+func normalizePrefix(prefix string) (string, error) {
+	prefix = strings.TrimSpace(prefix)
 	if prefix == "" {
 		return "", nil
 	}
@@ -92,3 +92,28 @@ func parsePrefix(req *http.Request) (string, error) {
 	}
 	return prefix, nil
 }
+
+// parseAsOf parses the optional as_of query parameter used for point-in-time reads. Future timestamps are rejected.
+func parseAsOf(req *http.Request) (*time.Time, error) {
+	raw := strings.TrimSpace(req.URL.Query().Get("as_of"))
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := parseRFC3339("as_of", raw)
+	if err != nil {
+		return nil, err
+	}
+	if t.After(time.Now()) {
+		return nil, fmt.Errorf("as_of must not be in the future")
+	}
+	return &t, nil
+}
+
+// parseRFC3339 parses a timestamp such as publish_at or as_of.
+func parseRFC3339(field, raw string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(raw))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC3339 timestamp", field)
+	}
+	return t, nil
+}
@@ -0,0 +1,72 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+// pathMapping maps a single config (Path -> To) or a folder (Prefix -> To, both with a trailing slash)
+// onto destination paths. It is shared by moves and clones.
+type pathMapping struct {
+	Path   string
+	Prefix string
+	To     string
+}
+
+// newPathMapping validates a source selector (exactly one of path/prefix) and its destination
+// (to_path for path, to_prefix for prefix). Errors are *httpError.
+func newPathMapping(path, prefix, toPath string, toPrefix *string) (pathMapping, error) {
+	bad := func(field, msg string) error {
+		return &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: msg, Details: map[string]any{"field": field}}
+	}
+	var m pathMapping
+	var err error
+	switch {
+	case path != "" && prefix == "":
+		if m.Path, err = normalizeConfigPath(path); err != nil {
+			return pathMapping{}, bad("path", err.Error())
+		}
+		if toPrefix != nil {
+			return pathMapping{}, bad("to_prefix", "to_prefix requires prefix")
+		}
+		if m.To, err = normalizeConfigPath(toPath); err != nil {
+			return pathMapping{}, bad("to_path", "to_path: "+err.Error())
+		}
+	case prefix != "" && path == "":
+		if m.Prefix, err = normalizePrefix(prefix); err != nil {
+			return pathMapping{}, bad("prefix", err.Error())
+		}
+		if toPrefix == nil || toPath != "" {
+			return pathMapping{}, bad("to_prefix", "prefix requires to_prefix")
+		}
+		if m.To, err = normalizePrefix(*toPrefix); err != nil {
+			return pathMapping{}, bad("to_prefix", "to_prefix: "+err.Error())
+		}
+	default:
+		return pathMapping{}, &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: "exactly one of path or prefix is required"}
+	}
+	return m, nil
+}
+
+// checkDistinct rejects mappings whose source and destination coincide or (for folders) overlap
+// within the same namespace.
+func (m pathMapping) checkDistinct(sameNamespace bool) error {
+	if !sameNamespace {
+		return nil
+	}
+	if m.Prefix == "" && m.Path == m.To {
+		return &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: "source and destination are the same"}
+	}
+	if m.Prefix != "" && (strings.HasPrefix(m.To, m.Prefix) || strings.HasPrefix(m.Prefix, m.To)) {
+		return &httpError{Status: http.StatusBadRequest, Code: "bad_request", Message: "source and destination folders must not overlap"}
+	}
+	return nil
+}
+
+// target returns the normalized destination of a source config path.
+func (m pathMapping) target(from string) (string, error) {
+	if m.Prefix == "" {
+		return m.To, nil
+	}
+	return normalizeConfigPath(m.To + strings.TrimPrefix(from, m.Prefix))
+}
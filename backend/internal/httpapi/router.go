@@ -27,7 +27,15 @@ func NewRouter(db *pgxpool.Pool) http.Handler {
 	api.Use(middleware.RequestID)
 	api.Use(middleware.RealIP)
 	api.Use(middleware.Recoverer)
-	api.Use(middleware.Timeout(requestTimeout))
+	// Event streams and namespace exports outlive the request timeout, on this router and on the outer one.
+	isStream := func(p string) bool {
+		if p == basePath+"/events" {
+			return true
+		}
+		rest, ok := strings.CutPrefix(p, basePath+"/namespaces/")
+		return ok && strings.Count(rest, "/") == 1 && strings.HasSuffix(rest, "/export")
+	}
+	api.Use(timeoutExcept(requestTimeout, isStream))
 	api.Use(middleware.Logger)
 
 	api.Use(corsMiddleware(parseAllowedOriginsEnv()))
@@ -45,28 +53,179 @@ func NewRouter(db *pgxpool.Pool) http.Handler {
 		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 	})
 
-	// Namespaces
+	// Namespaces. Routes addressed to a renamed namespace's old name go through its alias.
+	nsAlias := namespaceAliasMiddleware(db)
 	api.Get("/namespaces", func(w http.ResponseWriter, req *http.Request) {
 		handleListNamespaces(w, req, db)
 	})
 	api.Post("/namespaces", func(w http.ResponseWriter, req *http.Request) {
-		var body struct {
-			Name string `json:"name"`
-		}
+		var body CreateNamespaceRequest
 		if err := decodeJSONBody(w, req, &body, 1<<20); err != nil {
 			writeError(w, http.StatusBadRequest, "bad_request", err.Error(), nil)
 			return
 		}
 		handleCreateNamespace(w, req, db, body.Name)
 	})
-	api.Delete("/namespaces/{namespace}", func(w http.ResponseWriter, req *http.Request) {
+	api.With(nsAlias).Patch("/namespaces/{namespace}", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleRenameNamespace(w, req, db, ns)
+	})
+	api.With(nsAlias).Delete("/namespaces/{namespace}", func(w http.ResponseWriter, req *http.Request) {
 		ns := chi.URLParam(req, "namespace")
 		handleDeleteNamespace(w, req, db, ns)
 	})
-	api.Get("/namespaces/{namespace}/browse", func(w http.ResponseWriter, req *http.Request) {
+	api.With(nsAlias).Delete("/namespaces/{namespace}/folders/*", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleDeleteFolder(w, req, db, ns, chi.URLParam(req, "*"))
+	})
+	api.With(nsAlias).Get("/namespaces/{namespace}/browse", func(w http.ResponseWriter, req *http.Request) {
 		ns := chi.URLParam(req, "namespace")
 		handleBrowseNamespace(w, req, db, ns)
 	})
+	api.With(nsAlias).Get("/namespaces/{namespace}/export", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleExportNamespace(w, req, db, ns)
+	})
+	api.With(nsAlias).Post("/namespaces/{namespace}/import", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleImportNamespace(w, req, db, ns)
+	})
+	api.With(nsAlias).Get("/namespaces/{namespace}/watch", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleWatchFolder(w, req, db, ns)
+	})
+	api.With(nsAlias).Get("/namespaces/{namespace}/policy", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleGetNamespacePolicy(w, req, db, ns)
+	})
+	api.With(nsAlias).Put("/namespaces/{namespace}/policy", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleUpdateNamespacePolicy(w, req, db, ns)
+	})
+	api.With(nsAlias).Get("/namespaces/{namespace}/schedules", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleListNamespaceSchedules(w, req, db, ns)
+	})
+
+	api.With(nsAlias).Get("/namespaces/{namespace}/locks", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleListLocks(w, req, db, ns)
+	})
+	api.With(nsAlias).Post("/namespaces/{namespace}/locks", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleCreateLock(w, req, db, ns)
+	})
+	api.With(nsAlias).Delete("/namespaces/{namespace}/locks/{lock}", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleDeleteLock(w, req, db, ns)
+	})
+
+	api.With(nsAlias).Get("/namespaces/{namespace}/admission-webhooks", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleListAdmissionWebhooks(w, req, db, ns)
+	})
+	api.With(nsAlias).Post("/namespaces/{namespace}/admission-webhooks", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleCreateAdmissionWebhook(w, req, db, ns)
+	})
+	api.With(nsAlias).Get("/namespaces/{namespace}/admission-webhooks/{hook}", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleGetAdmissionWebhook(w, req, db, ns)
+	})
+	api.With(nsAlias).Patch("/namespaces/{namespace}/admission-webhooks/{hook}", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleUpdateAdmissionWebhook(w, req, db, ns)
+	})
+	api.With(nsAlias).Delete("/namespaces/{namespace}/admission-webhooks/{hook}", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleDeleteAdmissionWebhook(w, req, db, ns)
+	})
+	api.With(nsAlias).Get("/namespaces/{namespace}/admission-denials", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleListAdmissionDenials(w, req, db, ns)
+	})
+
+	api.With(nsAlias).Get("/namespaces/{namespace}/releases", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleListReleases(w, req, db, ns)
+	})
+	api.With(nsAlias).Post("/namespaces/{namespace}/releases", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleCreateRelease(w, req, db, ns)
+	})
+	api.With(nsAlias).Get("/namespaces/{namespace}/releases/{release}", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleGetRelease(w, req, db, ns)
+	})
+	api.With(nsAlias).Get("/namespaces/{namespace}/releases/{release}/diff", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleDiffReleases(w, req, db, ns)
+	})
+	api.With(nsAlias).Post("/namespaces/{namespace}/releases/{release}/rollback", func(w http.ResponseWriter, req *http.Request) {
+		ns := chi.URLParam(req, "namespace")
+		handleRollbackRelease(w, req, db, ns)
+	})
+
+	// Change stream (Server-Sent Events; not subject to the request timeout)
+	api.Get("/events", func(w http.ResponseWriter, req *http.Request) {
+		handleEventStream(w, req, db)
+	})
+
+	// Webhooks (outgoing event deliveries)
+	api.Get("/webhooks", func(w http.ResponseWriter, req *http.Request) {
+		handleListWebhooks(w, req, db)
+	})
+	api.Post("/webhooks", func(w http.ResponseWriter, req *http.Request) {
+		handleCreateWebhook(w, req, db)
+	})
+	api.Get("/webhooks/{webhook}", func(w http.ResponseWriter, req *http.Request) {
+		handleGetWebhook(w, req, db)
+	})
+	api.Patch("/webhooks/{webhook}", func(w http.ResponseWriter, req *http.Request) {
+		handleUpdateWebhook(w, req, db)
+	})
+	api.Delete("/webhooks/{webhook}", func(w http.ResponseWriter, req *http.Request) {
+		handleDeleteWebhook(w, req, db)
+	})
+	api.Post("/webhooks/{webhook}/test", func(w http.ResponseWriter, req *http.Request) {
+		handleTestWebhook(w, req, db)
+	})
+	api.Get("/webhooks/{webhook}/deliveries", func(w http.ResponseWriter, req *http.Request) {
+		handleListWebhookDeliveries(w, req, db)
+	})
+	api.Get("/webhooks/{webhook}/deliveries/{delivery}", func(w http.ResponseWriter, req *http.Request) {
+		handleGetWebhookDelivery(w, req, db)
+	})
+	api.Post("/webhooks/{webhook}/deliveries/{delivery}/replay", func(w http.ResponseWriter, req *http.Request) {
+		handleReplayWebhookDelivery(w, req, db)
+	})
+
+	// Moves and clones
+	api.Post("/moves", func(w http.ResponseWriter, req *http.Request) {
+		handleMoveConfigs(w, req, db)
+	})
+	api.Post("/clones", func(w http.ResponseWriter, req *http.Request) {
+		handleCloneConfigs(w, req, db)
+	})
+
+	// Changesets (multi-config atomic writes)
+	api.Get("/changesets", func(w http.ResponseWriter, req *http.Request) {
+		handleListChangesets(w, req, db)
+	})
+	api.Post("/changesets", func(w http.ResponseWriter, req *http.Request) {
+		handleCreateChangeset(w, req, db)
+	})
+	api.Get("/changesets/{changeset}", func(w http.ResponseWriter, req *http.Request) {
+		handleGetChangeset(w, req, db)
+	})
+	api.Post("/changesets/{changeset}/revert", func(w http.ResponseWriter, req *http.Request) {
+		handleRevertChangeset(w, req, db)
+	})
+
+	// Ticket references (extracted from version comments)
+	api.Get("/tickets/{ticket}/versions", func(w http.ResponseWriter, req *http.Request) {
+		handleListTicketVersions(w, req, db)
+	})
 
 	// Browse
 	api.Get("/configs", func(w http.ResponseWriter, req *http.Request) {
@@ -75,6 +234,7 @@ func NewRouter(db *pgxpool.Pool) http.Handler {
 
 	// Greedy path routing: /configs/{namespace}/{path...}
 	api.Route("/configs/{namespace}", func(r chi.Router) {
+		r.Use(nsAlias)
 		r.Route("/{path:.*}", func(r chi.Router) {
 			r.Get("/", func(w http.ResponseWriter, req *http.Request) {
 				handleGetLatestConfig(w, req, db)
@@ -111,13 +271,81 @@ func NewRouter(db *pgxpool.Pool) http.Handler {
 				}
 				handleDeleteConfigVersion(w, req, db)
 			})
+
+			r.Get("/tags", func(w http.ResponseWriter, req *http.Request) {
+				handleListTags(w, req, db)
+			})
+
+			r.Get("/moves", func(w http.ResponseWriter, req *http.Request) {
+				handleListConfigMoves(w, req, db)
+			})
+
+			r.Put("/tags/{tag}", func(w http.ResponseWriter, req *http.Request) {
+				handleSetTag(w, req, db)
+			})
+
+			r.Delete("/tags/{tag}", func(w http.ResponseWriter, req *http.Request) {
+				handleDeleteTag(w, req, db)
+			})
+
+			r.Get("/tags/{tag}/history", func(w http.ResponseWriter, req *http.Request) {
+				handleListTagHistory(w, req, db)
+			})
+
+			r.Get("/drafts", func(w http.ResponseWriter, req *http.Request) {
+				handleListDrafts(w, req, db)
+			})
+
+			r.Post("/drafts", func(w http.ResponseWriter, req *http.Request) {
+				handleCreateDraft(w, req, db)
+			})
+
+			r.Get("/drafts/{draft}", func(w http.ResponseWriter, req *http.Request) {
+				handleGetDraft(w, req, db)
+			})
+
+			r.Put("/drafts/{draft}", func(w http.ResponseWriter, req *http.Request) {
+				handleRebaseDraft(w, req, db)
+			})
+
+			r.Post("/drafts/{draft}/reviews", func(w http.ResponseWriter, req *http.Request) {
+				handleReviewDraft(w, req, db)
+			})
+
+			r.Post("/drafts/{draft}/publish", func(w http.ResponseWriter, req *http.Request) {
+				handlePublishDraft(w, req, db)
+			})
+
+			r.Get("/schedules", func(w http.ResponseWriter, req *http.Request) {
+				handleListSchedules(w, req, db)
+			})
+
+			r.Post("/schedules", func(w http.ResponseWriter, req *http.Request) {
+				handleCreateSchedule(w, req, db)
+			})
+
+			r.Get("/schedules/{schedule}", func(w http.ResponseWriter, req *http.Request) {
+				handleGetSchedule(w, req, db)
+			})
+
+			r.Put("/schedules/{schedule}", func(w http.ResponseWriter, req *http.Request) {
+				handleRescheduleSchedule(w, req, db)
+			})
+
+			r.Delete("/schedules/{schedule}", func(w http.ResponseWriter, req *http.Request) {
+				handleCancelSchedule(w, req, db)
+			})
+
+			r.Get("/blame", func(w http.ResponseWriter, req *http.Request) {
+				handleBlameConfig(w, req, db)
+			})
 		})
 	})
 
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(requestTimeout))
+	r.Use(timeoutExcept(requestTimeout, isStream))
 	r.Use(middleware.Logger)
 
 	r.Use(corsMiddleware(parseAllowedOriginsEnv()))
@@ -151,6 +379,20 @@ func parseAllowedOriginsEnv() []string {
 	return out
 }
 
+// timeoutExcept applies middleware.Timeout to every request except the long-lived streams whose path isStream matches.
+func timeoutExcept(timeout time.Duration, isStream func(path string) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withTimeout := middleware.Timeout(timeout)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if isStream(req.URL.Path) {
+				next.ServeHTTP(w, req)
+				return
+			}
+			withTimeout.ServeHTTP(w, req)
+		})
+	}
+}
+
 func normalizeBasePath(raw string) string {
 	raw = strings.TrimSpace(raw)
 	if raw == "" || raw == "/" {
@@ -189,8 +431,9 @@ func corsMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
 						w.Header().Add("Vary", "Origin")
 					}
 				}
-				w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization")
+				w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization,If-Match,If-None-Match,X-Lock-Override")
+				w.Header().Set("Access-Control-Expose-Headers", "ETag")
 			}
 
 			if req.Method == http.MethodOptions {
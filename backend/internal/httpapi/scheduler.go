@@ -0,0 +1,239 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RunScheduler publishes due scheduled versions until ctx is cancelled.
+//
+// It is safe to run on every API replica: due schedules are claimed with FOR UPDATE SKIP LOCKED, and only the
+// earliest pending schedule of each config is eligible, so schedules for one config publish once and in order.
+// Schedules that came due while no replica was running are published on the next tick (oldest first),
+// unless they set max_delay_seconds and are now later than that, in which case they are marked missed.
+func RunScheduler(ctx context.Context, db *pgxpool.Pool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := failSchedulesOfDeletedConfigs(ctx, db); err != nil && ctx.Err() == nil {
+			log.Printf("scheduler: cleanup failed: %v", err)
+		}
+		for ctx.Err() == nil {
+			processed, err := publishNextDueSchedule(ctx, db)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("scheduler: publish failed: %v", err)
+				}
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func failSchedulesOfDeletedConfigs(ctx context.Context, db *pgxpool.Pool) error {
+	_, err := db.Exec(ctx, `
+		UPDATE config_schedules s
+		SET status = 'failed', failure_reason = 'config deleted'
+		FROM configs c
+		WHERE c.id = s.config_id
+		  AND s.status = 'pending'
+		  AND c.deleted_at IS NOT NULL
+	`)
+	return err
+}
+
+// publishNextDueSchedule claims one due schedule and resolves it (published, failed or missed) in a single transaction.
+// It reports whether a schedule was processed.
+func publishNextDueSchedule(ctx context.Context, db *pgxpool.Pool) (bool, error) {
+	tx, err := beginEventTx(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var scheduleID, cfgID pgtype.UUID
+	var namespace, path, fmtStr, bodyRaw string
+	var publishAt, now time.Time
+	var baseVersion, maxDelaySeconds sql.NullInt32
+	var createdBy, comment, requestID, userAgent sql.NullString
+	var sourceIP *net.IP
+	err = tx.QueryRow(ctx, `
+		SELECT s.id, s.config_id, c.namespace, c.path, c.format::text, s.body_raw, s.publish_at, now(),
+		       s.base_version, s.max_delay_seconds, s.created_by, s.comment, s.request_id, s.user_agent, s.source_ip
+		FROM config_schedules s
+		JOIN configs c ON c.id = s.config_id AND c.deleted_at IS NULL
+		WHERE s.status = 'pending'
+		  AND s.publish_at <= now()
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM config_schedules e
+			WHERE e.config_id = s.config_id
+			  AND e.status = 'pending'
+			  AND (e.publish_at, e.created_at, e.id) < (s.publish_at, s.created_at, s.id)
+		  )
+		ORDER BY s.publish_at ASC, s.created_at ASC
+		LIMIT 1
+		FOR UPDATE OF s SKIP LOCKED
+	`).Scan(&scheduleID, &cfgID, &namespace, &path, &fmtStr, &bodyRaw, &publishAt, &now,
+		&baseVersion, &maxDelaySeconds, &createdBy, &comment, &requestID, &userAgent, &sourceIP)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	resolve := func(status string, reason string, version *int) (bool, error) {
+		var reasonArg *string
+		if reason != "" {
+			reasonArg = &reason
+			log.Printf("scheduler: schedule %s for %s/%s %s: %s", uuidToString(scheduleID), namespace, path, status, reason)
+		}
+		_, err := tx.Exec(ctx, `
+			UPDATE config_schedules
+			SET status = $2, failure_reason = $3, published_version = $4,
+			    published_at = CASE WHEN $2 = 'published' THEN now() ELSE NULL END
+			WHERE id = $1
+		`, scheduleID, status, reasonArg, version)
+		if err != nil {
+			return false, err
+		}
+		return true, tx.Commit(ctx)
+	}
+
+	if maxDelaySeconds.Valid && now.Sub(publishAt) > time.Duration(maxDelaySeconds.Int32)*time.Second {
+		return resolve(scheduleStatusMissed, fmt.Sprintf("publication was %s late (max_delay_seconds=%d)", now.Sub(publishAt).Round(time.Second), maxDelaySeconds.Int32), nil)
+	}
+
+	// Lock order (schedule, then config) is safe: handlers that touch schedules never hold the config lock.
+	if _, _, err := storeLockConfig(ctx, tx, namespace, path); err != nil {
+		return false, err
+	}
+	if err := checkLocks(ctx, tx, namespace, []string{path}, false); err != nil {
+		var he *httpError
+		if !errors.As(err, &he) {
+			return false, err
+		}
+		return resolve(scheduleStatusFailed, "config is locked: "+he.Message, nil)
+	}
+	policy, err := storeGetNamespacePolicy(ctx, tx, namespace)
+	if err != nil {
+		return false, err
+	}
+	// Schedules are refused at creation under required_approvals; this catches those created before it was set.
+	if err := checkDirectWrite(policy, changeActionUpdate); err != nil {
+		var he *httpError
+		if !errors.As(err, &he) {
+			return false, err
+		}
+		return resolve(scheduleStatusFailed, he.Message, nil)
+	}
+	var commentArg *string
+	if comment.Valid {
+		commentArg = &comment.String
+	}
+	ticketRefs, err := checkComment(policy, commentArg)
+	if err != nil {
+		var he *httpError
+		if !errors.As(err, &he) {
+			return false, err
+		}
+		return resolve(scheduleStatusFailed, he.Message, nil)
+	}
+	latestNum, err := storeLatestVersionNumber(ctx, tx, cfgID)
+	if err != nil {
+		return false, err
+	}
+	if baseVersion.Valid && int(baseVersion.Int32) != latestNum {
+		return resolve(scheduleStatusFailed, fmt.Sprintf("base_version %d does not match current latest %d", baseVersion.Int32, latestNum), nil)
+	}
+
+	sha := sha256Hex(bodyRaw)
+	if latestNum > 0 {
+		latestSHA, err := storeVersionContentSHA(ctx, tx, cfgID, latestNum)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return false, err
+		}
+		if latestSHA == sha {
+			return resolve(scheduleStatusFailed, "body_raw matches current latest", nil)
+		}
+	}
+
+	_, parsedJSON, err := parseBody(ConfigFormat(fmtStr), bodyRaw)
+	if err != nil {
+		return resolve(scheduleStatusFailed, err.Error(), nil)
+	}
+
+	in := versionInput{
+		ConfigID:   cfgID,
+		Version:    latestNum + 1,
+		BodyRaw:    bodyRaw,
+		BodyJSON:   parsedJSON,
+		SHA256:     sha,
+		Comment:    commentArg,
+		TicketRefs: ticketRefs,
+	}
+	if createdBy.Valid {
+		in.CreatedBy = &createdBy.String
+	}
+	if requestID.Valid {
+		in.RequestID = &requestID.String
+	}
+	if userAgent.Valid {
+		in.UserAgent = &userAgent.String
+	}
+	if sourceIP != nil {
+		in.SourceIP = *sourceIP
+	}
+	// Hook warnings have nobody to go to; a denial fails the schedule like any other rejected write.
+	if _, err := admit(ctx, db, tx, admissionReview{
+		Operation:  admissionOpUpdate,
+		Namespace:  namespace,
+		Path:       path,
+		Format:     ConfigFormat(fmtStr),
+		New:        admissionObject{Version: in.Version, BodyRaw: bodyRaw, BodyJSON: parsedJSON},
+		Author:     in.CreatedBy,
+		Comment:    in.Comment,
+		RequestID:  in.RequestID,
+		configID:   cfgID,
+		oldVersion: latestNum,
+		userAgent:  in.UserAgent,
+		sourceIP:   in.SourceIP,
+	}); err != nil {
+		var he *httpError
+		if !errors.As(err, &he) {
+			return false, err
+		}
+		return resolve(scheduleStatusFailed, he.Message, nil)
+	}
+	if _, _, err := storeInsertVersion(ctx, tx, in); err != nil {
+		return false, err
+	}
+	if err := storeInsertEvent(ctx, tx, versionEvent(eventConfigUpdated, namespace, path, in)); err != nil {
+		return false, err
+	}
+	ok, err := resolve(scheduleStatusPublished, "", ptr(in.Version))
+	if err == nil {
+		configChanges.notify(namespace, path)
+	}
+	return ok, err
+}
@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// admissionWebhookColumns selects the fields scanned by scanAdmissionWebhook (a = admission_webhooks).
+const admissionWebhookColumns = `a.id, a.namespace, a.prefix, a.name, a.url, a.timeout_ms, a.failure_policy, a.active, a.created_at, a.updated_at`
+
+func scanAdmissionWebhook(s rowScanner) (AdmissionWebhook, error) {
+	var h AdmissionWebhook
+	var id pgtype.UUID
+	if err := s.Scan(&id, &h.Namespace, &h.Prefix, &h.Name, &h.URL, &h.TimeoutMS, &h.FailurePolicy, &h.Active, &h.CreatedAt, &h.UpdatedAt); err != nil {
+		return AdmissionWebhook{}, err
+	}
+	h.ID = uuidToString(id)
+	return h, nil
+}
+
+func storeListAdmissionWebhooks(ctx context.Context, q querier, namespace string) ([]AdmissionWebhook, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+admissionWebhookColumns+`
+		FROM admission_webhooks a
+		WHERE a.namespace = $1
+		ORDER BY a.name ASC
+	`, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []AdmissionWebhook{}
+	for rows.Next() {
+		h, err := scanAdmissionWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+func storeGetAdmissionWebhook(ctx context.Context, q querier, namespace string, id pgtype.UUID) (AdmissionWebhook, error) {
+	return scanAdmissionWebhook(q.QueryRow(ctx, `
+		SELECT `+admissionWebhookColumns+` FROM admission_webhooks a WHERE a.id = $1 AND a.namespace = $2
+	`, id, namespace))
+}
+
+// storeMatchingAdmissionWebhooks returns the active admission webhooks of namespace whose prefix covers path, by name.
+func storeMatchingAdmissionWebhooks(ctx context.Context, q querier, namespace, path string) ([]admissionHook, error) {
+	rows, err := q.Query(ctx, `
+		SELECT a.id, a.name, a.url, a.timeout_ms, a.failure_policy
+		FROM admission_webhooks a
+		WHERE a.namespace = $1
+		  AND a.active
+		  AND left($2, length(a.prefix)) = a.prefix
+		ORDER BY a.name ASC
+	`, namespace, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []admissionHook
+	for rows.Next() {
+		var h admissionHook
+		var timeoutMS int
+		if err := rows.Scan(&h.ID, &h.Name, &h.URL, &timeoutMS, &h.FailurePolicy); err != nil {
+			return nil, err
+		}
+		h.Timeout = time.Duration(timeoutMS) * time.Millisecond
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+// storeAdmissionObject loads a version as the old object of an admission review.
+func storeAdmissionObject(ctx context.Context, q querier, cfgID pgtype.UUID, version int) (*admissionObject, error) {
+	o := &admissionObject{}
+	var bodyJSON []byte
+	err := q.QueryRow(ctx, `
+		SELECT version, body_raw, body_json
+		FROM config_versions
+		WHERE config_id = $1 AND version = $2
+	`, cfgID, version).Scan(&o.Version, &o.BodyRaw, &bodyJSON)
+	if err != nil {
+		return nil, err
+	}
+	o.BodyJSON = json.RawMessage(bodyJSON)
+	return o, nil
+}
+
+// storeRecordAdmissionDenials stores one admission_denials row per denying hook.
+func storeRecordAdmissionDenials(ctx context.Context, q querier, review admissionReview, denials []admissionDenialReason) error {
+	for _, d := range denials {
+		if _, err := q.Exec(ctx, `
+			INSERT INTO admission_denials (namespace, path, operation, webhook_id, webhook_name, messages, failed, created_by, request_id, user_agent, source_ip)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, review.Namespace, review.Path, review.Operation, d.WebhookID, d.Webhook, d.Messages, d.Failed, review.Author, review.RequestID, review.userAgent, review.sourceIP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// admissionDenialColumns selects the fields scanned by scanAdmissionDenial (d = admission_denials).
+const admissionDenialColumns = `d.id, d.namespace, d.path, d.operation, d.webhook_id, d.webhook_name, d.messages, d.failed, d.created_by, d.request_id, d.created_at`
+
+func scanAdmissionDenial(s rowScanner) (AdmissionDenial, error) {
+	var d AdmissionDenial
+	var id, webhookID pgtype.UUID
+	var createdBy, requestID sql.NullString
+	if err := s.Scan(&id, &d.Namespace, &d.Path, &d.Operation, &webhookID, &d.WebhookName, &d.Messages, &d.Failed, &createdBy, &requestID, &d.CreatedAt); err != nil {
+		return AdmissionDenial{}, err
+	}
+	d.ID = uuidToString(id)
+	if webhookID.Valid {
+		d.WebhookID = ptr(uuidToString(webhookID))
+	}
+	if createdBy.Valid {
+		d.CreatedBy = &createdBy.String
+	}
+	if requestID.Valid {
+		d.RequestID = &requestID.String
+	}
+	if d.Messages == nil {
+		d.Messages = []string{}
+	}
+	return d, nil
+}
+
+// storeListAdmissionDenials returns the denials of namespace, newest first; path "" = every path.
+func storeListAdmissionDenials(ctx context.Context, q querier, namespace, path string, limit, offset int) ([]AdmissionDenial, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+admissionDenialColumns+`
+		FROM admission_denials d
+		WHERE d.namespace = $1
+		  AND ($2 = '' OR d.path = $2)
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $3 OFFSET $4
+	`, namespace, path, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]AdmissionDenial, 0, limit)
+	for rows.Next() {
+		d, err := scanAdmissionDenial(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
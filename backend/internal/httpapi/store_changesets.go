@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// changesetMetaColumns selects the fields scanned by scanChangesetMeta (cs = changesets).
+const changesetMetaColumns = `
+	cs.id, cs.created_at, cs.created_by, cs.comment, cs.reverts_changeset_id,
+	(SELECT COUNT(*) FROM changeset_items i WHERE i.changeset_id = cs.id) AS item_count`
+
+func storeGetChangeset(ctx context.Context, q querier, id pgtype.UUID) (Changeset, error) {
+	row := q.QueryRow(ctx, `
+		SELECT `+changesetMetaColumns+`
+		FROM changesets cs
+		WHERE cs.id = $1
+	`, id)
+	meta, err := scanChangesetMeta(row)
+	if err != nil {
+		return Changeset{}, err
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT action, namespace, path, config_id, previous_version, version
+		FROM changeset_items
+		WHERE changeset_id = $1
+		ORDER BY position ASC
+	`, id)
+	if err != nil {
+		return Changeset{}, err
+	}
+	defer rows.Close()
+
+	items := make([]ChangesetItem, 0, meta.ItemCount)
+	for rows.Next() {
+		var it ChangesetItem
+		var cfgID pgtype.UUID
+		var prev, ver sql.NullInt32
+		if err := rows.Scan(&it.Action, &it.Namespace, &it.Path, &cfgID, &prev, &ver); err != nil {
+			return Changeset{}, err
+		}
+		if cfgID.Valid {
+			it.ConfigID = ptr(uuidToString(cfgID))
+		}
+		if prev.Valid {
+			it.PreviousVersion = ptr(int(prev.Int32))
+		}
+		if ver.Valid {
+			it.Version = ptr(int(ver.Int32))
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return Changeset{}, err
+	}
+	return Changeset{ChangesetMeta: meta, Items: items}, nil
+}
+
+// storeChangesetRevertedBy returns the changeset that reverted id (pgx.ErrNoRows if none).
+func storeChangesetRevertedBy(ctx context.Context, q querier, id pgtype.UUID) (string, error) {
+	var revertID pgtype.UUID
+	err := q.QueryRow(ctx, `
+		SELECT id FROM changesets WHERE reverts_changeset_id = $1 LIMIT 1
+	`, id).Scan(&revertID)
+	return uuidToString(revertID), err
+}
+
+func scanChangesetMeta(s rowScanner) (ChangesetMeta, error) {
+	var m ChangesetMeta
+	var id, revertsID pgtype.UUID
+	var createdBy, comment sql.NullString
+	if err := s.Scan(&id, &m.CreatedAt, &createdBy, &comment, &revertsID, &m.ItemCount); err != nil {
+		return ChangesetMeta{}, err
+	}
+	m.ID = uuidToString(id)
+	if createdBy.Valid {
+		m.CreatedBy = &createdBy.String
+	}
+	if comment.Valid {
+		m.Comment = &comment.String
+	}
+	if revertsID.Valid {
+		m.RevertsChangesetID = ptr(uuidToString(revertsID))
+	}
+	return m, nil
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"net"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -12,6 +16,16 @@ type rowScanner interface {
 	Scan(dest ...any) error
 }
 
+// prefixScanner scans leading columns into dest before handing the rest to a scanX function.
+type prefixScanner struct {
+	s    rowScanner
+	dest []any
+}
+
+func (p prefixScanner) Scan(dest ...any) error {
+	return p.s.Scan(append(p.dest, dest...)...)
+}
+
 func storeGetConfigOnly(ctx context.Context, q querier, namespace, path string) (Config, pgtype.UUID, error) {
 	var cfgID pgtype.UUID
 	var cfg Config
@@ -30,6 +44,121 @@ func storeGetConfigOnly(ctx context.Context, q querier, namespace, path string)
 	return cfg, cfgID, nil
 }
 
+// storeLockConfig loads an active config and locks its row (FOR UPDATE) so version numbers increment safely.
+// Must be called inside a transaction.
+func storeLockConfig(ctx context.Context, q querier, namespace, path string) (Config, pgtype.UUID, error) {
+	var cfgID, latestVersionID pgtype.UUID
+	var cfg Config
+	var fmtStr string
+	err := q.QueryRow(ctx, `
+		SELECT id, namespace, path, format::text, latest_version_id, created_at, updated_at
+		FROM configs
+		WHERE namespace = $1 AND path = $2
+		  AND deleted_at IS NULL
+		FOR UPDATE
+	`, namespace, path).Scan(&cfgID, &cfg.Namespace, &cfg.Path, &fmtStr, &latestVersionID, &cfg.CreatedAt, &cfg.UpdatedAt)
+	if err != nil {
+		return Config{}, pgtype.UUID{}, err
+	}
+	cfg.ID = uuidToString(cfgID)
+	cfg.Format = ConfigFormat(fmtStr)
+	if latestVersionID.Valid {
+		cfg.LatestVersionID = ptr(uuidToString(latestVersionID))
+	}
+	return cfg, cfgID, nil
+}
+
+// storeLatestVersionNumber returns max(version) for a config, or 0 if it has no versions.
+func storeLatestVersionNumber(ctx context.Context, q querier, cfgID pgtype.UUID) (int, error) {
+	var n int
+	err := q.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM config_versions WHERE config_id = $1`, cfgID).Scan(&n)
+	return n, err
+}
+
+// storeVersionContentSHA returns the content hash of a version, computing it from body_raw for rows that predate content_sha256.
+func storeVersionContentSHA(ctx context.Context, q querier, cfgID pgtype.UUID, version int) (string, error) {
+	var sha sql.NullString
+	var bodyRaw string
+	err := q.QueryRow(ctx, `
+		SELECT content_sha256, body_raw
+		FROM config_versions
+		WHERE config_id = $1 AND version = $2
+	`, cfgID, version).Scan(&sha, &bodyRaw)
+	if err != nil {
+		return "", err
+	}
+	if sha.Valid && sha.String != "" {
+		return sha.String, nil
+	}
+	return sha256Hex(bodyRaw), nil
+}
+
+// storeVersionBodyJSON returns the stored body_json of a version (nil if the version has none).
+func storeVersionBodyJSON(ctx context.Context, q querier, cfgID pgtype.UUID, version int) ([]byte, error) {
+	var bodyJSON []byte
+	err := q.QueryRow(ctx, `
+		SELECT body_json
+		FROM config_versions
+		WHERE config_id = $1 AND version = $2
+	`, cfgID, version).Scan(&bodyJSON)
+	return bodyJSON, err
+}
+
+// storeVersionETag returns the ETag of a version, or "" (no error) if it does not exist.
+func storeVersionETag(ctx context.Context, q querier, cfgID pgtype.UUID, version int) (string, error) {
+	if version <= 0 {
+		return "", nil
+	}
+	sha, err := storeVersionContentSHA(ctx, q, cfgID, version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return versionETag(version, sha), nil
+}
+
+// versionInput describes a config version to append.
+type versionInput struct {
+	ConfigID  pgtype.UUID
+	Version   int
+	BodyRaw   string
+	BodyJSON  []byte
+	CreatedBy *string
+	Comment   *string
+	SHA256    string
+	// ChangesetID is set for versions produced by a changeset.
+	ChangesetID pgtype.UUID
+	// TicketRefs are the ticket IDs referenced by Comment (see checkComment).
+	TicketRefs []string
+	RequestID  *string
+	UserAgent  *string
+	SourceIP   net.IP
+}
+
+// storeInsertVersion appends a version and advances configs.latest_version_id to it.
+func storeInsertVersion(ctx context.Context, q querier, in versionInput) (pgtype.UUID, time.Time, error) {
+	var verID pgtype.UUID
+	var createdAt pgtype.Timestamptz
+	err := q.QueryRow(ctx, `
+		INSERT INTO config_versions (config_id, version, body_raw, body_json, created_by, comment, content_sha256, request_id, user_agent, source_ip, changeset_id, ticket_refs)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE($12::text[], '{}'))
+		RETURNING id, created_at
+	`, in.ConfigID, in.Version, in.BodyRaw, json.RawMessage(in.BodyJSON), in.CreatedBy, in.Comment, in.SHA256, in.RequestID, in.UserAgent, in.SourceIP, in.ChangesetID, in.TicketRefs).Scan(&verID, &createdAt)
+	if err != nil {
+		return pgtype.UUID{}, time.Time{}, err
+	}
+	tag, err := q.Exec(ctx, `UPDATE configs SET latest_version_id = $1 WHERE id = $2`, verID, in.ConfigID)
+	if err != nil {
+		return pgtype.UUID{}, time.Time{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgtype.UUID{}, time.Time{}, errors.New("update latest failed: config not found")
+	}
+	return verID, createdAt.Time, nil
+}
+
 func storeGetConfigAndLatest(ctx context.Context, q querier, namespace, path string) (Config, ConfigVersion, error) {
 	cfg, cfgID, err := storeGetConfigOnly(ctx, q, namespace, path)
 	if err != nil {
@@ -43,9 +172,58 @@ func storeGetConfigAndLatest(ctx context.Context, q querier, namespace, path str
 	return cfg, ver, nil
 }
 
+// configSource is the FROM item (named alias) for config reads: active configs, or with pointInTime, the configs
+// alive at the timestamp bound to param under the namespace and path they had then (config_lifetimes, so a config
+// that was deleted and restored is absent in between, and a moved one is found at its old path). It provides id,
+// namespace, path, format, created_at and updated_at.
+func configSource(alias, param string, pointInTime bool) string {
+	if !pointInTime {
+		return `(SELECT id, namespace, path, format, created_at, updated_at FROM configs WHERE deleted_at IS NULL) ` + alias
+	}
+	return `(
+		SELECT x.id, l.namespace, l.path, x.format, x.created_at, x.updated_at
+		FROM config_lifetimes l
+		JOIN configs x ON x.id = l.config_id
+		WHERE l.valid_from <= ` + param + ` AND (l.valid_to IS NULL OR l.valid_to > ` + param + `)
+	) ` + alias
+}
+
+// storeGetConfigAsOf resolves the config that was alive at asOf and the version that was latest at that moment.
+func storeGetConfigAsOf(ctx context.Context, q querier, namespace, path string, asOf time.Time) (Config, ConfigVersion, error) {
+	var cfgID pgtype.UUID
+	var cfg Config
+	var fmtStr string
+	err := q.QueryRow(ctx, `
+		SELECT c.id, c.namespace, c.path, c.format::text, c.created_at, c.updated_at
+		FROM `+configSource("c", "$3::timestamptz", true)+`
+		WHERE c.namespace = $1 AND c.path = $2
+		ORDER BY c.created_at DESC
+		LIMIT 1
+	`, namespace, path, asOf).Scan(&cfgID, &cfg.Namespace, &cfg.Path, &fmtStr, &cfg.CreatedAt, &cfg.UpdatedAt)
+	if err != nil {
+		return Config{}, ConfigVersion{}, err
+	}
+	cfg.ID = uuidToString(cfgID)
+	cfg.Format = ConfigFormat(fmtStr)
+
+	row := q.QueryRow(ctx, `
+		SELECT id, version, created_at, created_by, comment, content_sha256, changeset_id, ticket_refs, body_raw, body_json
+		FROM config_versions
+		WHERE config_id = $1 AND created_at <= $2
+		ORDER BY version DESC
+		LIMIT 1
+	`, cfgID, asOf)
+	ver, err := scanConfigVersion(row)
+	if err != nil {
+		return Config{}, ConfigVersion{}, err
+	}
+	cfg.LatestVersionID = ptr(ver.ID)
+	return cfg, ver, nil
+}
+
 func storeGetLatestVersion(ctx context.Context, q querier, cfgID pgtype.UUID) (ConfigVersion, error) {
 	row := q.QueryRow(ctx, `
-		SELECT id, version, created_at, created_by, comment, content_sha256, body_raw, body_json
+		SELECT id, version, created_at, created_by, comment, content_sha256, changeset_id, ticket_refs, body_raw, body_json
 		FROM config_versions
 		WHERE config_id = $1
 		ORDER BY version DESC
@@ -56,7 +234,7 @@ func storeGetLatestVersion(ctx context.Context, q querier, cfgID pgtype.UUID) (C
 
 func storeGetVersion(ctx context.Context, q querier, cfgID pgtype.UUID, version int) (ConfigVersion, error) {
 	row := q.QueryRow(ctx, `
-		SELECT id, version, created_at, created_by, comment, content_sha256, body_raw, body_json
+		SELECT id, version, created_at, created_by, comment, content_sha256, changeset_id, ticket_refs, body_raw, body_json
 		FROM config_versions
 		WHERE config_id = $1 AND version = $2
 	`, cfgID, version)
@@ -64,15 +242,18 @@ func storeGetVersion(ctx context.Context, q querier, cfgID pgtype.UUID, version
 }
 
 func scanConfigVersion(s rowScanner) (ConfigVersion, error) {
-	var verID pgtype.UUID
+	var verID, changesetID pgtype.UUID
 	var v ConfigVersion
 	var bodyJSON []byte
 	var createdBy, comment, contentSHA sql.NullString
-	if err := s.Scan(&verID, &v.Version, &v.CreatedAt, &createdBy, &comment, &contentSHA, &v.BodyRaw, &bodyJSON); err != nil {
+	if err := s.Scan(&verID, &v.Version, &v.CreatedAt, &createdBy, &comment, &contentSHA, &changesetID, &v.TicketRefs, &v.BodyRaw, &bodyJSON); err != nil {
 		return ConfigVersion{}, err
 	}
 
 	v.ID = uuidToString(verID)
+	if changesetID.Valid {
+		v.ChangesetID = ptr(uuidToString(changesetID))
+	}
 	if createdBy.Valid {
 		v.CreatedBy = &createdBy.String
 	}
@@ -91,13 +272,16 @@ func scanConfigVersion(s rowScanner) (ConfigVersion, error) {
 }
 
 func scanConfigVersionMeta(s rowScanner) (ConfigVersionMeta, error) {
-	var id pgtype.UUID
+	var id, changesetID pgtype.UUID
 	var m ConfigVersionMeta
 	var createdBy, comment, contentSHA sql.NullString
-	if err := s.Scan(&id, &m.Version, &m.CreatedAt, &createdBy, &comment, &contentSHA); err != nil {
+	if err := s.Scan(&id, &m.Version, &m.CreatedAt, &createdBy, &comment, &contentSHA, &changesetID, &m.TicketRefs); err != nil {
 		return ConfigVersionMeta{}, err
 	}
 	m.ID = uuidToString(id)
+	if changesetID.Valid {
+		m.ChangesetID = ptr(uuidToString(changesetID))
+	}
 	if createdBy.Valid {
 		m.CreatedBy = &createdBy.String
 	}
@@ -109,3 +293,119 @@ func scanConfigVersionMeta(s rowScanner) (ConfigVersionMeta, error) {
 	}
 	return m, nil
 }
+
+// storeActivePaths returns which of paths are taken by active configs in namespace.
+func storeActivePaths(ctx context.Context, q querier, namespace string, paths []string) ([]string, error) {
+	rows, err := q.Query(ctx, `
+		SELECT path
+		FROM configs
+		WHERE namespace = $1 AND path = ANY($2)
+		  AND deleted_at IS NULL
+		ORDER BY path ASC
+	`, namespace, paths)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// selectedConfig is an active config picked by a pathMapping.
+type selectedConfig struct {
+	ID     pgtype.UUID
+	Path   string
+	Format ConfigFormat
+}
+
+// storeSelectConfigs returns the active configs of namespace matched by m (its path, or everything under its prefix)
+// in path order, optionally locking them (FOR UPDATE). The prefix is compared literally: paths may contain the LIKE
+// wildcards _ and %.
+func storeSelectConfigs(ctx context.Context, q querier, namespace string, m pathMapping, forUpdate bool) ([]selectedConfig, error) {
+	lock := ""
+	if forUpdate {
+		lock = "FOR UPDATE"
+	}
+	rows, err := q.Query(ctx, `
+		SELECT id, path, format::text
+		FROM configs
+		WHERE namespace = $1
+		  AND deleted_at IS NULL
+		  AND (path = $2 OR ($3 <> '' AND left(path, length($3::text)) = $3))
+		ORDER BY path ASC
+		`+lock, namespace, m.Path, m.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []selectedConfig
+	for rows.Next() {
+		var c selectedConfig
+		var fmtStr string
+		if err := rows.Scan(&c.ID, &c.Path, &fmtStr); err != nil {
+			return nil, err
+		}
+		c.Format = ConfigFormat(fmtStr)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// versionBody is a stored version's content and authorship, as copied by clones.
+type versionBody struct {
+	Version   int
+	BodyRaw   string
+	BodyJSON  []byte
+	SHA256    string
+	CreatedBy *string
+	Comment   *string
+}
+
+// storeVersionBodies returns a config's versions oldest-first, or only its latest with latestOnly.
+func storeVersionBodies(ctx context.Context, q querier, cfgID pgtype.UUID, latestOnly bool) ([]versionBody, error) {
+	rows, err := q.Query(ctx, `
+		SELECT version, body_raw, body_json, content_sha256, created_by, comment
+		FROM (
+			SELECT *
+			FROM config_versions
+			WHERE config_id = $1
+			ORDER BY version DESC
+			LIMIT CASE WHEN $2 THEN 1 END
+		) v
+		ORDER BY version ASC
+	`, cfgID, latestOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []versionBody
+	for rows.Next() {
+		var b versionBody
+		var sha, createdBy, comment sql.NullString
+		if err := rows.Scan(&b.Version, &b.BodyRaw, &b.BodyJSON, &sha, &createdBy, &comment); err != nil {
+			return nil, err
+		}
+		b.SHA256 = sha.String
+		if !sha.Valid || sha.String == "" {
+			b.SHA256 = sha256Hex(b.BodyRaw)
+		}
+		if createdBy.Valid {
+			b.CreatedBy = &createdBy.String
+		}
+		if comment.Valid {
+			b.Comment = &comment.String
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,152 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	draftStatusOpen      = "open"
+	draftStatusPublished = "published"
+	draftStatusRejected  = "rejected"
+)
+
+// draftMetaColumns selects the fields scanned by scanConfigDraftMeta, including approvals for the current revision.
+const draftMetaColumns = `
+	d.id, d.status, d.base_version, d.revision, d.created_at, d.updated_at, d.created_by, d.revised_by, d.comment, d.content_sha256,
+	d.published_version, d.published_by, d.published_at,
+	(
+		SELECT COUNT(DISTINCT r.reviewer)
+		FROM config_draft_reviews r
+		WHERE r.draft_id = d.id AND r.revision = d.revision AND r.decision = 'approve'
+	) AS approvals`
+
+func storeGetDraft(ctx context.Context, q querier, cfgID, draftID pgtype.UUID) (ConfigDraft, error) {
+	row := q.QueryRow(ctx, `
+		SELECT `+draftMetaColumns+`, d.body_raw, d.body_json
+		FROM config_drafts d
+		WHERE d.config_id = $1 AND d.id = $2
+	`, cfgID, draftID)
+	return scanConfigDraft(row)
+}
+
+// storeLockDraft locks a draft row (FOR UPDATE). Must be called inside a transaction.
+func storeLockDraft(ctx context.Context, q querier, cfgID, draftID pgtype.UUID) (ConfigDraft, error) {
+	var id pgtype.UUID
+	err := q.QueryRow(ctx, `
+		SELECT id FROM config_drafts WHERE config_id = $1 AND id = $2 FOR UPDATE
+	`, cfgID, draftID).Scan(&id)
+	if err != nil {
+		return ConfigDraft{}, err
+	}
+	return storeGetDraft(ctx, q, cfgID, draftID)
+}
+
+func storeListDraftReviews(ctx context.Context, q querier, draftID pgtype.UUID) ([]DraftReview, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, revision, reviewer, decision, comment, created_at
+		FROM config_draft_reviews
+		WHERE draft_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, draftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]DraftReview, 0)
+	for rows.Next() {
+		var id pgtype.UUID
+		var r DraftReview
+		var comment sql.NullString
+		if err := rows.Scan(&id, &r.Revision, &r.Reviewer, &r.Decision, &comment, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		r.ID = uuidToString(id)
+		if comment.Valid {
+			r.Comment = &comment.String
+		}
+		items = append(items, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func scanConfigDraftMeta(s rowScanner) (ConfigDraftMeta, error) {
+	var d ConfigDraftMeta
+	var dest draftMetaDest
+	if err := s.Scan(dest.targets(&d)...); err != nil {
+		return ConfigDraftMeta{}, err
+	}
+	dest.apply(&d)
+	return d, nil
+}
+
+func scanConfigDraft(s rowScanner) (ConfigDraft, error) {
+	var d ConfigDraft
+	var dest draftMetaDest
+	var bodyJSON []byte
+	targets := append(dest.targets(&d.ConfigDraftMeta), &d.BodyRaw, &bodyJSON)
+	if err := s.Scan(targets...); err != nil {
+		return ConfigDraft{}, err
+	}
+	dest.apply(&d.ConfigDraftMeta)
+	if bodyJSON != nil {
+		var anyVal any
+		_ = json.Unmarshal(bodyJSON, &anyVal)
+		d.BodyJSON = anyVal
+	}
+	return d, nil
+}
+
+// draftMetaDest holds nullable scan targets for draftMetaColumns.
+type draftMetaDest struct {
+	id                             pgtype.UUID
+	createdBy, comment, contentSHA sql.NullString
+	publishedVersion               sql.NullInt32
+	publishedBy                    sql.NullString
+	publishedAt                    pgtype.Timestamptz
+	approvals                      int64
+}
+
+func (x *draftMetaDest) targets(d *ConfigDraftMeta) []any {
+	return []any{
+		&x.id, &d.Status, &d.BaseVersion, &d.Revision, &d.CreatedAt, &d.UpdatedAt, &x.createdBy, &d.RevisedBy, &x.comment, &x.contentSHA,
+		&x.publishedVersion, &x.publishedBy, &x.publishedAt,
+		&x.approvals,
+	}
+}
+
+func (x *draftMetaDest) apply(d *ConfigDraftMeta) {
+	d.ID = uuidToString(x.id)
+	d.Approvals = int(x.approvals)
+	if x.createdBy.Valid {
+		d.CreatedBy = &x.createdBy.String
+	}
+	if x.comment.Valid {
+		d.Comment = &x.comment.String
+	}
+	if x.contentSHA.Valid {
+		d.ContentSHA256 = &x.contentSHA.String
+	}
+	if x.publishedVersion.Valid {
+		d.PublishedVersion = ptr(int(x.publishedVersion.Int32))
+	}
+	if x.publishedBy.Valid {
+		d.PublishedBy = &x.publishedBy.String
+	}
+	if x.publishedAt.Valid {
+		d.PublishedAt = ptr(x.publishedAt.Time)
+	}
+}
+
+// annotateDraft fills fields derived from the config's current state rather than stored on the draft.
+func annotateDraft(d *ConfigDraftMeta, latestVersion, requiredApprovals int) {
+	d.RequiredApprovals = requiredApprovals
+	d.Stale = d.Status == draftStatusOpen && d.BaseVersion != latestVersion
+}
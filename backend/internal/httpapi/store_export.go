@@ -0,0 +1,83 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// storeListExportItems returns the configs under prefix with the version that is latest (or was latest at asOf),
+// ordered by path. File is left empty; bodies are read with storeStreamExportBodies.
+func storeListExportItems(ctx context.Context, q querier, namespace, prefix string, asOf *time.Time) ([]ExportManifestItem, error) {
+	rows, err := q.Query(ctx, `
+		SELECT c.id, c.path, c.format::text,
+			lv.id, lv.version, lv.created_at, lv.created_by, lv.comment, lv.content_sha256
+		FROM `+configSource("c", "$3", asOf != nil)+`
+		JOIN LATERAL (
+			SELECT id, version, created_at, created_by, comment,
+				COALESCE(content_sha256, encode(sha256(convert_to(body_raw, 'UTF8')), 'hex')) AS content_sha256
+			FROM config_versions
+			WHERE config_id = c.id
+			  AND ($3::timestamptz IS NULL OR created_at <= $3)
+			ORDER BY version DESC
+			LIMIT 1
+		) lv ON true
+		WHERE c.namespace = $1
+		  AND ($2 = '' OR left(c.path, length($2::text)) = $2)
+		ORDER BY c.path ASC
+	`, namespace, prefix, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []ExportManifestItem{}
+	for rows.Next() {
+		var cfgID, verID pgtype.UUID
+		var it ExportManifestItem
+		var fmtStr string
+		var createdBy, comment sql.NullString
+		if err := rows.Scan(&cfgID, &it.Path, &fmtStr, &verID, &it.Version, &it.CreatedAt, &createdBy, &comment, &it.ContentSHA256); err != nil {
+			return nil, err
+		}
+		it.ConfigID = uuidToString(cfgID)
+		it.VersionID = uuidToString(verID)
+		it.Format = ConfigFormat(fmtStr)
+		if createdBy.Valid {
+			it.CreatedBy = &createdBy.String
+		}
+		if comment.Valid {
+			it.Comment = &comment.String
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// storeStreamExportBodies calls fn with the body of each version in versionIDs, in that order, one row at a time so
+// an export never holds more than one body in memory.
+func storeStreamExportBodies(ctx context.Context, q querier, versionIDs []string, fn func(i int, bodyRaw string) error) error {
+	rows, err := q.Query(ctx, `
+		SELECT x.ord, v.body_raw
+		FROM unnest($1::uuid[]) WITH ORDINALITY AS x(id, ord)
+		JOIN config_versions v ON v.id = x.id
+		ORDER BY x.ord
+	`, versionIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ord int
+		var bodyRaw string
+		if err := rows.Scan(&ord, &bodyRaw); err != nil {
+			return err
+		}
+		if err := fn(ord-1, bodyRaw); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
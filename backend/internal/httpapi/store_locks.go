@@ -0,0 +1,99 @@
+package httpapi
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// lockColumns selects the fields scanned by scanLock (l = config_locks).
+const lockColumns = `l.id, l.namespace, l.scope, l.target, l.reason, l.owner, l.expires_at, l.created_at`
+
+// lockLive restricts config_locks (l) to locks that have not expired.
+const lockLive = `(l.expires_at IS NULL OR l.expires_at > now())`
+
+// storeFindLocks returns live locks of namespace covering any of paths (any lock of the namespace if paths is nil),
+// oldest first.
+func storeFindLocks(ctx context.Context, q querier, namespace string, paths []string, limit int) ([]Lock, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+lockColumns+`
+		FROM config_locks l
+		WHERE l.namespace = $1
+		  AND `+lockLive+`
+		  AND (
+			$2::text[] IS NULL
+			OR l.scope = 'namespace'
+			OR (l.scope = 'config' AND l.target = ANY($2))
+			OR (l.scope = 'prefix' AND EXISTS (SELECT 1 FROM unnest($2::text[]) p WHERE left(p, length(l.target)) = l.target))
+		  )
+		ORDER BY l.created_at ASC, l.id ASC
+		LIMIT $3
+	`, namespace, paths, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Lock
+	for rows.Next() {
+		l, err := scanLock(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// storeListLocks returns live locks of namespace relevant to a folder: locks on the namespace or an enclosing folder,
+// and locks on anything under prefix ("" = all locks of the namespace).
+func storeListLocks(ctx context.Context, q querier, namespace, prefix string) ([]Lock, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+lockColumns+`
+		FROM config_locks l
+		WHERE l.namespace = $1
+		  AND `+lockLive+`
+		  AND (
+			$2 = ''
+			OR l.scope = 'namespace'
+			OR left(l.target, length($2::text)) = $2
+			OR (l.scope = 'prefix' AND left($2, length(l.target)) = l.target)
+		  )
+		ORDER BY l.created_at ASC, l.id ASC
+	`, namespace, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Lock{}
+	for rows.Next() {
+		l, err := scanLock(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+func scanLock(s rowScanner) (Lock, error) {
+	var l Lock
+	var id pgtype.UUID
+	var target string
+	var expires pgtype.Timestamptz
+	if err := s.Scan(&id, &l.Namespace, &l.Scope, &target, &l.Reason, &l.Owner, &expires, &l.CreatedAt); err != nil {
+		return Lock{}, err
+	}
+	l.ID = uuidToString(id)
+	switch l.Scope {
+	case lockScopePrefix:
+		l.Prefix = target
+	case lockScopeConfig:
+		l.Path = target
+	}
+	if expires.Valid {
+		l.ExpiresAt = ptr(expires.Time)
+	}
+	return l, nil
+}
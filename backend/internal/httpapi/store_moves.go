@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func storeListConfigMoves(ctx context.Context, q querier, cfgID pgtype.UUID, limit, offset int) ([]ConfigMove, error) {
+	rows, err := q.Query(ctx, `
+		SELECT move_id, config_id, created_at, moved_by, comment, alias_expires_at,
+		       from_namespace, from_path, to_namespace, to_path
+		FROM config_moves
+		WHERE config_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, cfgID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]ConfigMove, 0, limit)
+	for rows.Next() {
+		var m ConfigMove
+		var moveID, configID pgtype.UUID
+		var movedBy, comment sql.NullString
+		var expires pgtype.Timestamptz
+		if err := rows.Scan(&moveID, &configID, &m.CreatedAt, &movedBy, &comment, &expires,
+			&m.FromNamespace, &m.FromPath, &m.ToNamespace, &m.ToPath); err != nil {
+			return nil, err
+		}
+		m.MoveID = uuidToString(moveID)
+		m.ConfigID = uuidToString(configID)
+		if movedBy.Valid {
+			m.MovedBy = &movedBy.String
+		}
+		if comment.Valid {
+			m.Comment = &comment.String
+		}
+		if expires.Valid {
+			m.AliasExpiresAt = ptr(expires.Time)
+		}
+		items = append(items, m)
+	}
+	return items, rows.Err()
+}
+
+// storeResolveAlias returns the current location of a config that was moved away from namespace/path,
+// if the alias has not expired and the config is still active.
+func storeResolveAlias(ctx context.Context, q querier, namespace, path string) (string, string, error) {
+	var toNamespace, toPath string
+	err := q.QueryRow(ctx, `
+		SELECT c.namespace, c.path
+		FROM config_aliases a
+		JOIN configs c ON c.id = a.config_id AND c.deleted_at IS NULL
+		WHERE a.namespace = $1 AND a.path = $2
+		  AND a.expires_at > now()
+	`, namespace, path).Scan(&toNamespace, &toPath)
+	return toNamespace, toPath, err
+}
@@ -51,3 +51,17 @@ func storeNamespaceExists(ctx context.Context, q querier, name string) (bool, er
 	}
 	return ok, nil
 }
+
+// storeGetNamespacePolicy returns the write policy of a namespace (pgx.ErrNoRows if it does not exist).
+func storeGetNamespacePolicy(ctx context.Context, q querier, name string) (NamespacePolicy, error) {
+	p := NamespacePolicy{Namespace: name}
+	err := q.QueryRow(ctx, `
+		SELECT required_approvals, require_comment, min_comment_length, ticket_pattern
+		FROM namespaces
+		WHERE name = $1
+	`, name).Scan(&p.RequiredApprovals, &p.RequireComment, &p.MinCommentLength, &p.TicketPattern)
+	if err != nil {
+		return NamespacePolicy{}, err
+	}
+	return p, nil
+}
@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// releaseMetaColumns selects the fields scanned by scanReleaseMeta (r = releases).
+const releaseMetaColumns = `
+	r.id, r.namespace, r.name, r.prefix, r.created_at, r.created_by, r.comment,
+	(SELECT COUNT(*) FROM release_items i WHERE i.release_id = r.id) AS item_count`
+
+func storeGetReleaseMeta(ctx context.Context, q querier, namespace, name string) (ReleaseMeta, pgtype.UUID, error) {
+	row := q.QueryRow(ctx, `
+		SELECT `+releaseMetaColumns+`
+		FROM releases r
+		WHERE r.namespace = $1 AND r.name = $2
+	`, namespace, name)
+	return scanReleaseMeta(row)
+}
+
+func storeListReleaseItems(ctx context.Context, q querier, releaseID pgtype.UUID) ([]ReleaseItem, error) {
+	rows, err := q.Query(ctx, `
+		SELECT i.path, c.format::text, i.version, i.version_id, v.content_sha256
+		FROM release_items i
+		JOIN configs c ON c.id = i.config_id
+		JOIN config_versions v ON v.id = i.version_id
+		WHERE i.release_id = $1
+		ORDER BY i.path ASC
+	`, releaseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]ReleaseItem, 0)
+	for rows.Next() {
+		var it ReleaseItem
+		var fmtStr string
+		var verID pgtype.UUID
+		var sha sql.NullString
+		if err := rows.Scan(&it.Path, &fmtStr, &it.Version, &verID, &sha); err != nil {
+			return nil, err
+		}
+		it.Format = ConfigFormat(fmtStr)
+		it.VersionID = uuidToString(verID)
+		if sha.Valid {
+			it.ContentSHA256 = &sha.String
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// storeReleasesForVersion lists names of releases that include the given version.
+func storeReleasesForVersion(ctx context.Context, q querier, cfgID pgtype.UUID, version int) ([]string, error) {
+	rows, err := q.Query(ctx, `
+		SELECT r.name
+		FROM release_items i
+		JOIN releases r ON r.id = i.release_id
+		WHERE i.config_id = $1 AND i.version = $2
+		ORDER BY r.created_at ASC
+	`, cfgID, version)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func scanReleaseMeta(s rowScanner) (ReleaseMeta, pgtype.UUID, error) {
+	var m ReleaseMeta
+	var id pgtype.UUID
+	var createdBy, comment sql.NullString
+	if err := s.Scan(&id, &m.Namespace, &m.Name, &m.Prefix, &m.CreatedAt, &createdBy, &comment, &m.ItemCount); err != nil {
+		return ReleaseMeta{}, pgtype.UUID{}, err
+	}
+	m.ID = uuidToString(id)
+	if createdBy.Valid {
+		m.CreatedBy = &createdBy.String
+	}
+	if comment.Valid {
+		m.Comment = &comment.String
+	}
+	return m, id, nil
+}
+
+// storeGetReleasedConfig returns the config and version a release captured for path (pgx.ErrNoRows if the
+// release does not include it). The config may have been deleted since.
+func storeGetReleasedConfig(ctx context.Context, q querier, releaseID pgtype.UUID, path string) (Config, ConfigVersion, error) {
+	var cfgID, versionID pgtype.UUID
+	var cfg Config
+	var fmtStr string
+	err := q.QueryRow(ctx, `
+		SELECT c.id, c.namespace, c.path, c.format::text, c.created_at, c.updated_at, i.version_id
+		FROM release_items i
+		JOIN configs c ON c.id = i.config_id
+		WHERE i.release_id = $1 AND i.path = $2
+	`, releaseID, path).Scan(&cfgID, &cfg.Namespace, &cfg.Path, &fmtStr, &cfg.CreatedAt, &cfg.UpdatedAt, &versionID)
+	if err != nil {
+		return Config{}, ConfigVersion{}, err
+	}
+	cfg.ID = uuidToString(cfgID)
+	cfg.Format = ConfigFormat(fmtStr)
+
+	row := q.QueryRow(ctx, `
+		SELECT id, version, created_at, created_by, comment, content_sha256, changeset_id, ticket_refs, body_raw, body_json
+		FROM config_versions
+		WHERE id = $1
+	`, versionID)
+	ver, err := scanConfigVersion(row)
+	if err != nil {
+		return Config{}, ConfigVersion{}, err
+	}
+	return cfg, ver, nil
+}
@@ -0,0 +1,106 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	scheduleStatusPending   = "pending"
+	scheduleStatusPublished = "published"
+	scheduleStatusCancelled = "cancelled"
+	scheduleStatusFailed    = "failed"
+	scheduleStatusMissed    = "missed"
+)
+
+// scheduleMetaColumns selects the fields scanned by scanConfigScheduleMeta (s = config_schedules, c = configs).
+const scheduleMetaColumns = `
+	s.id, c.namespace, c.path, s.status, s.publish_at, s.base_version, s.max_delay_seconds,
+	s.created_at, s.updated_at, s.created_by, s.comment, s.content_sha256,
+	s.published_version, s.published_at, s.failure_reason`
+
+func storeGetSchedule(ctx context.Context, q querier, cfgID, scheduleID pgtype.UUID) (ConfigSchedule, error) {
+	row := q.QueryRow(ctx, `
+		SELECT `+scheduleMetaColumns+`, s.body_raw, s.body_json
+		FROM config_schedules s
+		JOIN configs c ON c.id = s.config_id
+		WHERE s.config_id = $1 AND s.id = $2
+	`, cfgID, scheduleID)
+	return scanConfigSchedule(row)
+}
+
+func scanConfigScheduleMeta(s rowScanner) (ConfigScheduleMeta, error) {
+	var m ConfigScheduleMeta
+	var dest scheduleMetaDest
+	if err := s.Scan(dest.targets(&m)...); err != nil {
+		return ConfigScheduleMeta{}, err
+	}
+	dest.apply(&m)
+	return m, nil
+}
+
+func scanConfigSchedule(s rowScanner) (ConfigSchedule, error) {
+	var c ConfigSchedule
+	var dest scheduleMetaDest
+	var bodyJSON []byte
+	targets := append(dest.targets(&c.ConfigScheduleMeta), &c.BodyRaw, &bodyJSON)
+	if err := s.Scan(targets...); err != nil {
+		return ConfigSchedule{}, err
+	}
+	dest.apply(&c.ConfigScheduleMeta)
+	if bodyJSON != nil {
+		var anyVal any
+		_ = json.Unmarshal(bodyJSON, &anyVal)
+		c.BodyJSON = anyVal
+	}
+	return c, nil
+}
+
+// scheduleMetaDest holds nullable scan targets for scheduleMetaColumns.
+type scheduleMetaDest struct {
+	id                             pgtype.UUID
+	baseVersion, maxDelaySeconds   sql.NullInt32
+	createdBy, comment, contentSHA sql.NullString
+	publishedVersion               sql.NullInt32
+	publishedAt                    pgtype.Timestamptz
+	failureReason                  sql.NullString
+}
+
+func (x *scheduleMetaDest) targets(m *ConfigScheduleMeta) []any {
+	return []any{
+		&x.id, &m.Namespace, &m.Path, &m.Status, &m.PublishAt, &x.baseVersion, &x.maxDelaySeconds,
+		&m.CreatedAt, &m.UpdatedAt, &x.createdBy, &x.comment, &x.contentSHA,
+		&x.publishedVersion, &x.publishedAt, &x.failureReason,
+	}
+}
+
+func (x *scheduleMetaDest) apply(m *ConfigScheduleMeta) {
+	m.ID = uuidToString(x.id)
+	if x.baseVersion.Valid {
+		m.BaseVersion = ptr(int(x.baseVersion.Int32))
+	}
+	if x.maxDelaySeconds.Valid {
+		m.MaxDelaySeconds = ptr(int(x.maxDelaySeconds.Int32))
+	}
+	if x.createdBy.Valid {
+		m.CreatedBy = &x.createdBy.String
+	}
+	if x.comment.Valid {
+		m.Comment = &x.comment.String
+	}
+	if x.contentSHA.Valid {
+		m.ContentSHA256 = &x.contentSHA.String
+	}
+	if x.publishedVersion.Valid {
+		m.PublishedVersion = ptr(int(x.publishedVersion.Int32))
+	}
+	if x.publishedAt.Valid {
+		m.PublishedAt = ptr(x.publishedAt.Time)
+	}
+	if x.failureReason.Valid {
+		m.FailureReason = &x.failureReason.String
+	}
+}
@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func storeListTags(ctx context.Context, q querier, cfgID pgtype.UUID) ([]ConfigTag, error) {
+	rows, err := q.Query(ctx, `
+		SELECT t.name, v.version, v.id, t.created_at, t.updated_at
+		FROM config_tags t
+		JOIN config_versions v ON v.id = t.version_id
+		WHERE t.config_id = $1
+		ORDER BY t.name ASC
+	`, cfgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]ConfigTag, 0)
+	for rows.Next() {
+		var t ConfigTag
+		var verID pgtype.UUID
+		if err := rows.Scan(&t.Name, &t.Version, &verID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		t.VersionID = uuidToString(verID)
+		items = append(items, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// storeGetTaggedVersion returns the version a tag currently points to (pgx.ErrNoRows if the tag does not exist).
+func storeGetTaggedVersion(ctx context.Context, q querier, cfgID pgtype.UUID, tag string) (ConfigVersion, error) {
+	row := q.QueryRow(ctx, `
+		SELECT v.id, v.version, v.created_at, v.created_by, v.comment, v.content_sha256, v.changeset_id, v.ticket_refs, v.body_raw, v.body_json
+		FROM config_tags t
+		JOIN config_versions v ON v.id = t.version_id
+		WHERE t.config_id = $1 AND t.name = $2
+	`, cfgID, tag)
+	return scanConfigVersion(row)
+}
+
+// storeTagsForVersion lists tag names pointing at the given version number.
+func storeTagsForVersion(ctx context.Context, q querier, cfgID pgtype.UUID, version int) ([]string, error) {
+	rows, err := q.Query(ctx, `
+		SELECT t.name
+		FROM config_tags t
+		JOIN config_versions v ON v.id = t.version_id
+		WHERE t.config_id = $1 AND v.version = $2
+		ORDER BY t.name ASC
+	`, cfgID, version)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func scanTagHistoryEntry(s rowScanner) (TagHistoryEntry, error) {
+	var id pgtype.UUID
+	var e TagHistoryEntry
+	var fromVersion, toVersion sql.NullInt32
+	var movedBy, comment sql.NullString
+	if err := s.Scan(&id, &e.Tag, &fromVersion, &toVersion, &e.CreatedAt, &movedBy, &comment); err != nil {
+		return TagHistoryEntry{}, err
+	}
+	e.ID = uuidToString(id)
+	if fromVersion.Valid {
+		e.FromVersion = ptr(int(fromVersion.Int32))
+	}
+	if toVersion.Valid {
+		e.ToVersion = ptr(int(toVersion.Int32))
+	}
+	if movedBy.Valid {
+		e.MovedBy = &movedBy.String
+	}
+	if comment.Valid {
+		e.Comment = &comment.String
+	}
+	return e, nil
+}
@@ -0,0 +1,141 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// webhookColumns selects the fields scanned by scanWebhook (w = webhooks). The secret is never listed.
+const webhookColumns = `w.id, w.name, w.url, w.namespace, w.prefix, w.event_types, w.active, w.created_at, w.updated_at`
+
+func scanWebhook(s rowScanner) (Webhook, error) {
+	var h Webhook
+	var id pgtype.UUID
+	var namespace sql.NullString
+	if err := s.Scan(&id, &h.Name, &h.URL, &namespace, &h.Prefix, &h.EventTypes, &h.Active, &h.CreatedAt, &h.UpdatedAt); err != nil {
+		return Webhook{}, err
+	}
+	h.ID = uuidToString(id)
+	if namespace.Valid {
+		h.Namespace = &namespace.String
+	}
+	if h.EventTypes == nil {
+		h.EventTypes = []string{}
+	}
+	return h, nil
+}
+
+// storeListWebhooks returns the webhooks scoped to namespace ("" = all webhooks; namespace-wide ones included
+// otherwise), oldest first.
+func storeListWebhooks(ctx context.Context, q querier, namespace string) ([]Webhook, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhooks w
+		WHERE $1 = '' OR w.namespace IS NULL OR w.namespace = $1
+		ORDER BY w.created_at ASC, w.id ASC
+	`, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Webhook{}
+	for rows.Next() {
+		h, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+func storeGetWebhook(ctx context.Context, q querier, id pgtype.UUID) (Webhook, error) {
+	return scanWebhook(q.QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhooks w WHERE w.id = $1`, id))
+}
+
+// deliveryColumns selects the fields scanned by scanWebhookDelivery (d = webhook_deliveries), without the payload.
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_type, d.replay_of, d.status, d.attempts, d.next_attempt_at,
+	d.last_attempt_at, d.last_status_code, d.last_error, d.delivered_at, d.created_at`
+
+func scanWebhookDelivery(s rowScanner) (WebhookDelivery, error) {
+	var d WebhookDelivery
+	var id, webhookID, replayOf pgtype.UUID
+	var eventID sql.NullInt64
+	var nextAttempt, lastAttempt, delivered pgtype.Timestamptz
+	var statusCode sql.NullInt32
+	var lastError sql.NullString
+	if err := s.Scan(&id, &webhookID, &eventID, &d.EventType, &replayOf, &d.Status, &d.Attempts, &nextAttempt,
+		&lastAttempt, &statusCode, &lastError, &delivered, &d.CreatedAt); err != nil {
+		return WebhookDelivery{}, err
+	}
+	d.ID = uuidToString(id)
+	d.WebhookID = uuidToString(webhookID)
+	if eventID.Valid {
+		d.EventID = &eventID.Int64
+	}
+	if replayOf.Valid {
+		d.ReplayOf = ptr(uuidToString(replayOf))
+	}
+	// next_attempt_at is only meaningful while the delivery is pending.
+	if nextAttempt.Valid && d.Status == deliveryPending {
+		d.NextAttemptAt = ptr(nextAttempt.Time)
+	}
+	if lastAttempt.Valid {
+		d.LastAttemptAt = ptr(lastAttempt.Time)
+	}
+	if statusCode.Valid {
+		d.LastStatusCode = ptr(int(statusCode.Int32))
+	}
+	if lastError.Valid {
+		d.LastError = &lastError.String
+	}
+	if delivered.Valid {
+		d.DeliveredAt = ptr(delivered.Time)
+	}
+	return d, nil
+}
+
+// storeListWebhookDeliveries returns deliveries of a webhook, newest first, optionally filtered by status ("" = any).
+func storeListWebhookDeliveries(ctx context.Context, q querier, webhookID pgtype.UUID, status string, limit, offset int) ([]WebhookDelivery, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1
+		  AND ($2 = '' OR d.status = $2)
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $3 OFFSET $4
+	`, webhookID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]WebhookDelivery, 0, limit)
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// storeGetWebhookDelivery returns one delivery of a webhook, including its payload.
+func storeGetWebhookDelivery(ctx context.Context, q querier, webhookID, id pgtype.UUID) (WebhookDelivery, error) {
+	var payload []byte
+	d, err := scanWebhookDelivery(prefixScanner{q.QueryRow(ctx, `
+		SELECT d.payload, `+deliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.id = $1 AND d.webhook_id = $2
+	`, id, webhookID), []any{&payload}})
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+	d.Payload = json.RawMessage(payload)
+	return d, nil
+}
@@ -1,94 +1,115 @@
 package httpapi
 
-import "time"
+import "config-manager/apitypes"
 
-type Config struct {
-	ID              string       `json:"id"`
-	Namespace       string       `json:"namespace"`
-	Path            string       `json:"path"`
-	Format          ConfigFormat `json:"format"`
-	LatestVersionID *string      `json:"latest_version_id,omitempty"`
-	CreatedAt       time.Time    `json:"created_at"`
-	UpdatedAt       time.Time    `json:"updated_at"`
-}
+// The API bodies live in package apitypes so the Go client shares them; these aliases keep the handlers short.
 
-type ConfigVersion struct {
-	ID            string    `json:"id"`
-	Version       int       `json:"version"`
-	CreatedAt     time.Time `json:"created_at"`
-	CreatedBy     *string   `json:"created_by,omitempty"`
-	Comment       *string   `json:"comment,omitempty"`
-	ContentSHA256 *string   `json:"content_sha256,omitempty"`
-	BodyRaw       string    `json:"body_raw"`
-	BodyJSON      any       `json:"body_json,omitempty"`
-}
+type ConfigFormat = apitypes.ConfigFormat
 
-type ConfigVersionMeta struct {
-	ID            string    `json:"id"`
-	Version       int       `json:"version"`
-	CreatedAt     time.Time `json:"created_at"`
-	CreatedBy     *string   `json:"created_by,omitempty"`
-	Comment       *string   `json:"comment,omitempty"`
-	ContentSHA256 *string   `json:"content_sha256,omitempty"`
-}
+const (
+	FormatJSON = apitypes.FormatJSON
+	FormatYAML = apitypes.FormatYAML
+)
 
-type GetConfigResponse struct {
-	Config Config        `json:"config"`
-	Latest ConfigVersion `json:"latest"`
-}
+type apiError = apitypes.Error
 
-type GetVersionResponse struct {
-	Config  Config        `json:"config"`
-	Version ConfigVersion `json:"version"`
-}
+type (
+	Config                       = apitypes.Config
+	ConfigVersion                = apitypes.ConfigVersion
+	ConfigVersionMeta            = apitypes.ConfigVersionMeta
+	GetConfigResponse            = apitypes.GetConfigResponse
+	MergeResult                  = apitypes.MergeResult
+	GetVersionResponse           = apitypes.GetVersionResponse
+	VersionListResponse          = apitypes.VersionListResponse
+	TicketVersion                = apitypes.TicketVersion
+	TicketVersionListResponse    = apitypes.TicketVersionListResponse
+	ConfigListItem               = apitypes.ConfigListItem
+	ConfigListResponse           = apitypes.ConfigListResponse
+	Namespace                    = apitypes.Namespace
+	NamespaceWithCount           = apitypes.NamespaceWithCount
+	NamespaceListResponse        = apitypes.NamespaceListResponse
+	BrowseEntryFolder            = apitypes.BrowseEntryFolder
+	BrowseEntryConfig            = apitypes.BrowseEntryConfig
+	BrowseResponse               = apitypes.BrowseResponse
+	ConfigTag                    = apitypes.ConfigTag
+	TagListResponse              = apitypes.TagListResponse
+	TagHistoryEntry              = apitypes.TagHistoryEntry
+	TagHistoryResponse           = apitypes.TagHistoryResponse
+	NamespacePolicy              = apitypes.NamespacePolicy
+	ConfigDraftMeta              = apitypes.ConfigDraftMeta
+	ConfigDraft                  = apitypes.ConfigDraft
+	DraftReview                  = apitypes.DraftReview
+	GetDraftResponse             = apitypes.GetDraftResponse
+	DraftListResponse            = apitypes.DraftListResponse
+	ConfigScheduleMeta           = apitypes.ConfigScheduleMeta
+	ConfigSchedule               = apitypes.ConfigSchedule
+	ScheduleListResponse         = apitypes.ScheduleListResponse
+	BlameVersion                 = apitypes.BlameVersion
+	BlameKey                     = apitypes.BlameKey
+	BlameLine                    = apitypes.BlameLine
+	BlameResponse                = apitypes.BlameResponse
+	ChangesetMeta                = apitypes.ChangesetMeta
+	ChangesetItem                = apitypes.ChangesetItem
+	Changeset                    = apitypes.Changeset
+	ChangesetListResponse        = apitypes.ChangesetListResponse
+	ReleaseMeta                  = apitypes.ReleaseMeta
+	ReleaseItem                  = apitypes.ReleaseItem
+	Release                      = apitypes.Release
+	ReleaseListResponse          = apitypes.ReleaseListResponse
+	ReleaseDiffEntry             = apitypes.ReleaseDiffEntry
+	ReleaseDiffResponse          = apitypes.ReleaseDiffResponse
+	RollbackResponse             = apitypes.RollbackResponse
+	MoveItem                     = apitypes.MoveItem
+	Move                         = apitypes.Move
+	ConfigMove                   = apitypes.ConfigMove
+	ConfigMoveListResponse       = apitypes.ConfigMoveListResponse
+	CloneItem                    = apitypes.CloneItem
+	CloneResponse                = apitypes.CloneResponse
+	BulkDeleteItem               = apitypes.BulkDeleteItem
+	BulkDeleteResponse           = apitypes.BulkDeleteResponse
+	Lock                         = apitypes.Lock
+	LockListResponse             = apitypes.LockListResponse
+	FolderWatchItem              = apitypes.FolderWatchItem
+	FolderWatchResponse          = apitypes.FolderWatchResponse
+	ConfigEvent                  = apitypes.ConfigEvent
+	Webhook                      = apitypes.Webhook
+	WebhookListResponse          = apitypes.WebhookListResponse
+	WebhookDelivery              = apitypes.WebhookDelivery
+	WebhookDeliveryListResponse  = apitypes.WebhookDeliveryListResponse
+	AdmissionWebhook             = apitypes.AdmissionWebhook
+	AdmissionWebhookListResponse = apitypes.AdmissionWebhookListResponse
+	AdmissionDenial              = apitypes.AdmissionDenial
+	AdmissionDenialListResponse  = apitypes.AdmissionDenialListResponse
+	ExportManifest               = apitypes.ExportManifest
+	ExportManifestItem           = apitypes.ExportManifestItem
+	ImportItem                   = apitypes.ImportItem
+	ImportResponse               = apitypes.ImportResponse
+)
 
-type VersionListResponse struct {
-	Items      []ConfigVersionMeta `json:"items"`
-	NextCursor *string             `json:"next_cursor,omitempty"`
-}
-
-type ConfigListItem struct {
-	Config     Config            `json:"config"`
-	LatestMeta ConfigVersionMeta `json:"latest_meta"`
-}
-
-type ConfigListResponse struct {
-	Items      []ConfigListItem `json:"items"`
-	NextCursor *string          `json:"next_cursor,omitempty"`
-}
-
-type Namespace struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
-
-type NamespaceWithCount struct {
-	Namespace
-	ConfigCount int `json:"config_count"`
-}
-
-type NamespaceListResponse struct {
-	Items      []NamespaceWithCount `json:"items"`
-	NextCursor *string              `json:"next_cursor,omitempty"`
-}
-
-type BrowseEntryFolder struct {
-	Type     string `json:"type"` // folder
-	Name     string `json:"name"`
-	FullPath string `json:"full_path"` // ends with /
-}
-
-type BrowseEntryConfig struct {
-	Type          string       `json:"type"` // config
-	Name          string       `json:"name"`
-	FullPath      string       `json:"full_path"` // no trailing /
-	Format        ConfigFormat `json:"format"`
-	LatestVersion int          `json:"latest_version"`
-}
-
-type BrowseResponse struct {
-	Items      []any   `json:"items"`
-	NextCursor *string `json:"next_cursor,omitempty"`
-}
+type (
+	CreateNamespaceRequest        = apitypes.CreateNamespaceRequest
+	RenameNamespaceRequest        = apitypes.RenameNamespaceRequest
+	UpdateNamespacePolicyRequest  = apitypes.UpdateNamespacePolicyRequest
+	CreateConfigRequest           = apitypes.CreateConfigRequest
+	UpdateConfigRequest           = apitypes.UpdateConfigRequest
+	SetTagRequest                 = apitypes.SetTagRequest
+	DeleteTagRequest              = apitypes.DeleteTagRequest
+	CreateDraftRequest            = apitypes.CreateDraftRequest
+	RebaseDraftRequest            = apitypes.RebaseDraftRequest
+	DraftReviewRequest            = apitypes.DraftReviewRequest
+	PublishDraftRequest           = apitypes.PublishDraftRequest
+	CreateScheduleRequest         = apitypes.CreateScheduleRequest
+	RescheduleRequest             = apitypes.RescheduleRequest
+	CreateLockRequest             = apitypes.CreateLockRequest
+	CreateReleaseRequest          = apitypes.CreateReleaseRequest
+	RollbackRequest               = apitypes.RollbackRequest
+	MoveRequest                   = apitypes.MoveRequest
+	CloneRequest                  = apitypes.CloneRequest
+	ChangesetItemRequest          = apitypes.ChangesetItemRequest
+	CreateChangesetRequest        = apitypes.CreateChangesetRequest
+	RevertChangesetRequest        = apitypes.RevertChangesetRequest
+	CreateWebhookRequest          = apitypes.CreateWebhookRequest
+	UpdateWebhookRequest          = apitypes.UpdateWebhookRequest
+	CreateAdmissionWebhookRequest = apitypes.CreateAdmissionWebhookRequest
+	UpdateAdmissionWebhookRequest = apitypes.UpdateAdmissionWebhookRequest
+)
@@ -1,6 +1,10 @@
 package httpapi
 
-import "github.com/jackc/pgx/v5/pgtype"
+import (
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
 
 func uuidToString(u pgtype.UUID) string {
 	if !u.Valid {
@@ -10,3 +14,20 @@ func uuidToString(u pgtype.UUID) string {
 }
 
 func ptr[T any](v T) *T { return &v }
+
+// equalStringPtr reports whether a and b are both nil or point to equal strings.
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// parseUUID parses a textual UUID (e.g. from a URL parameter).
+func parseUUID(raw string) (pgtype.UUID, error) {
+	var u pgtype.UUID
+	if err := u.Scan(strings.TrimSpace(raw)); err != nil {
+		return pgtype.UUID{}, err
+	}
+	return u, nil
+}
@@ -62,3 +62,40 @@ func normalizeConfigPath(path string) (string, error) {
 	}
 	return path, nil
 }
+
+// Tag names: letters, digits, underscore, hyphen, dot. "latest" is reserved for the derived pointer.
+var tagNameRE = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+const maxTagNameLength = 64
+
+func validateTagName(name string) error {
+	if name == "" {
+		return errors.New("tag is required")
+	}
+	if len(name) > maxTagNameLength {
+		return errors.New("tag must be at most 64 characters")
+	}
+	if !tagNameRE.MatchString(name) {
+		return errors.New("tag must be letters, digits, underscore, hyphen, dot only")
+	}
+	if strings.EqualFold(name, "latest") {
+		return errors.New("tag 'latest' is reserved")
+	}
+	return nil
+}
+
+// Release names follow tag rules (e.g. 2026.10.3) without reserved names.
+const maxReleaseNameLength = 64
+
+func validateReleaseName(name string) error {
+	if name == "" {
+		return errors.New("release name is required")
+	}
+	if len(name) > maxReleaseNameLength {
+		return errors.New("release name must be at most 64 characters")
+	}
+	if !tagNameRE.MatchString(name) {
+		return errors.New("release name must be letters, digits, underscore, hyphen, dot only")
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"config-manager/internal/config"
+)
+
+const (
+	// maxWatchWait bounds ?wait=; the effective wait is further capped by the request deadline (middleware.Timeout).
+	maxWatchWait = 5 * time.Minute
+	// watchDeadlineMargin is kept free before the request deadline so a timed-out watch can still answer 304
+	// before middleware.Timeout answers 504.
+	watchDeadlineMargin = time.Second
+)
+
+// changeHub wakes long-poll watchers and event streams after committed writes: directly from the handlers of this
+// process, and through RunChangeListener for every replica. Wakeups are only hints: watchers re-read the database
+// after every wakeup, so spurious (and duplicate) ones are harmless.
+type changeHub struct {
+	mu       sync.Mutex
+	watchers map[string]map[*changeWatcher]struct{} // by namespace; "" = every namespace (event streams)
+}
+
+// changeWatcher is interested in one config (path) or a folder (prefix, "" = the whole namespace).
+type changeWatcher struct {
+	path   string
+	prefix string
+	ch     chan struct{} // buffered(1); a pending wakeup is never lost
+}
+
+// configChanges is the process-wide hub; handlers call notify after commit.
+var configChanges = &changeHub{watchers: map[string]map[*changeWatcher]struct{}{}}
+
+func (h *changeHub) subscribe(namespace, path, prefix string) *changeWatcher {
+	cw := &changeWatcher{path: path, prefix: prefix, ch: make(chan struct{}, 1)}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.watchers[namespace] == nil {
+		h.watchers[namespace] = map[*changeWatcher]struct{}{}
+	}
+	h.watchers[namespace][cw] = struct{}{}
+	return cw
+}
+
+func (h *changeHub) unsubscribe(namespace string, cw *changeWatcher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.watchers[namespace], cw)
+	if len(h.watchers[namespace]) == 0 {
+		delete(h.watchers, namespace)
+	}
+}
+
+// notify wakes the watchers of the given configs in namespace; with no paths every watcher of the namespace
+// (namespace-wide changes such as cascading deletes and renames).
+func (h *changeHub) notify(namespace string, paths ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range []string{namespace, ""} {
+		for cw := range h.watchers[key] {
+			if len(paths) > 0 && !cw.matches(paths) {
+				continue
+			}
+			select {
+			case cw.ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// notifyAll wakes every watcher, e.g. after notifications may have been missed.
+func (h *changeHub) notifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, watchers := range h.watchers {
+		for cw := range watchers {
+			select {
+			case cw.ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (cw *changeWatcher) matches(paths []string) bool {
+	for _, p := range paths {
+		if cw.path != "" && p == cw.path || cw.path == "" && strings.HasPrefix(p, cw.prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyChangeset wakes the watchers of every config touched by a changeset.
+func notifyChangeset(cs Changeset) {
+	byNamespace := map[string][]string{}
+	for _, it := range cs.Items {
+		byNamespace[it.Namespace] = append(byNamespace[it.Namespace], it.Path)
+	}
+	for ns, paths := range byNamespace {
+		configChanges.notify(ns, paths...)
+	}
+}
+
+// parseWait parses ?wait= as a Go duration ("30s") or a number of seconds. ok is false when the parameter is absent.
+func parseWait(req *http.Request) (time.Duration, bool, error) {
+	raw := strings.TrimSpace(req.URL.Query().Get("wait"))
+	if raw == "" {
+		return 0, false, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		secs, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			return 0, false, fmt.Errorf("wait must be a duration (e.g. 30s) or a number of seconds")
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	if d < 0 || d > maxWatchWait {
+		return 0, false, fmt.Errorf("wait must be between 0s and %s", maxWatchWait)
+	}
+	return d, true, nil
+}
+
+// watchContext bounds a long poll by wait and by the request deadline (less watchDeadlineMargin).
+func watchContext(ctx context.Context, wait time.Duration) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if left := time.Until(deadline) - watchDeadlineMargin; left < wait {
+			wait = max(left, 0)
+		}
+	}
+	return context.WithTimeout(ctx, wait)
+}
+
+// longPoll subscribes to changes, then calls check until it reports done or the watch times out. Subscribing before
+// the first check means a write committed between the check and the wait still wakes the watcher. The periodic
+// re-check (api.watch.recheckIntervalSeconds) is a safety net in case the change listener is down. It returns false
+// on timeout (or client disconnect).
+func longPoll(ctx context.Context, wait time.Duration, namespace, path, prefix string, check func() (bool, error)) (bool, error) {
+	cw := configChanges.subscribe(namespace, path, prefix)
+	defer configChanges.unsubscribe(namespace, cw)
+
+	ctx, cancel := watchContext(ctx, wait)
+	defer cancel()
+	var recheck <-chan time.Time
+	if secs := config.Int("api.watch.recheckIntervalSeconds", 60); secs > 0 {
+		t := time.NewTicker(time.Duration(secs) * time.Second)
+		defer t.Stop()
+		recheck = t.C
+	}
+	for {
+		done, err := check()
+		if err != nil || done {
+			return done, err
+		}
+		select {
+		case <-cw.ch:
+		case <-recheck:
+		case <-ctx.Done():
+			return false, nil
+		}
+	}
+}
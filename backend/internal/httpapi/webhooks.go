@@ -0,0 +1,270 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"config-manager/internal/config"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	deliveryPending   = "pending"
+	deliverySucceeded = "succeeded"
+	deliveryDead      = "dead"
+
+	// eventWebhookTest is the event type of deliveries sent by POST /webhooks/{webhook}/test.
+	eventWebhookTest = "webhook.test"
+
+	// webhookBatchSize bounds the deliveries claimed (and sent concurrently) per dispatcher round.
+	webhookBatchSize = 20
+	// webhookBaseBackoff is the delay after the first failed attempt; it doubles per attempt up to webhookMaxBackoff.
+	webhookBaseBackoff = 10 * time.Second
+	webhookMaxBackoff  = time.Hour
+	// maxWebhookErrorLen bounds last_error (including the start of the receiver's response body).
+	maxWebhookErrorLen = 500
+)
+
+// webhookEventTypes are the event types a webhook can subscribe to.
+var webhookEventTypes = []string{
+	eventConfigCreated, eventConfigUpdated, eventConfigDeleted, eventConfigMoved, eventVersionDeleted,
+	eventNamespaceCreated, eventNamespaceDeleted, eventNamespaceRenamed,
+}
+
+// webhookWake wakes the local dispatcher for deliveries that were not created by a change (replays).
+var webhookWake = make(chan struct{}, 1)
+
+func wakeWebhookDispatcher() {
+	select {
+	case webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// newWebhookSecret returns a random signing secret.
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// signWebhook returns the X-Webhook-Signature value: HMAC-SHA256 over "<timestamp>.<body>", hex encoded.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookTimeout is the timeout of one delivery attempt.
+func webhookTimeout() time.Duration {
+	return time.Duration(max(config.Int("api.webhooks.timeoutSeconds", 10), 1)) * time.Second
+}
+
+// webhookLease is how long a claimed delivery stays invisible to other dispatchers. A replica dying mid-attempt
+// leaves the delivery pending, so it is retried once the lease expires.
+func webhookLease() time.Duration {
+	return 2*webhookTimeout() + 30*time.Second
+}
+
+// webhookBackoff is the delay before the next attempt after attempts failed ones.
+func webhookBackoff(attempts int) time.Duration {
+	d := webhookBaseBackoff
+	for i := 1; i < attempts && d < webhookMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, webhookMaxBackoff)
+}
+
+var webhookClient = &http.Client{
+	// Redirects are not followed: the receiver is the configured URL only.
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// webhookAttempt is a claimed delivery together with its webhook's target.
+type webhookAttempt struct {
+	ID        pgtype.UUID
+	EventType string
+	Payload   []byte
+	Attempts  int
+	URL       string
+	Secret    string
+}
+
+// send POSTs the payload once. It returns the response status (0 if there was none) and, unless the receiver
+// answered 2xx, an error describing the failure.
+func (a webhookAttempt) send(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout())
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(a.Payload))
+	if err != nil {
+		return 0, err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "config-manager-webhooks")
+	httpReq.Header.Set("X-Webhook-Id", uuidToString(a.ID))
+	httpReq.Header.Set("X-Webhook-Event", a.EventType)
+	httpReq.Header.Set("X-Webhook-Timestamp", ts)
+	httpReq.Header.Set("X-Webhook-Signature", signWebhook(a.Secret, ts, a.Payload))
+
+	resp, err := webhookClient.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorLen))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := "unexpected status " + resp.Status
+		if len(snippet) > 0 {
+			msg += ": " + string(snippet)
+		}
+		return resp.StatusCode, errors.New(msg)
+	}
+	return resp.StatusCode, nil
+}
+
+// storeRecordWebhookAttempt stores the outcome of an attempt: succeeded, retried after a backoff, or dead once
+// maxAttempts attempts have failed (maxAttempts <= 0 makes a failure final).
+func storeRecordWebhookAttempt(ctx context.Context, q querier, a webhookAttempt, statusCode int, sendErr error, maxAttempts int) error {
+	status := deliverySucceeded
+	var lastError *string
+	if sendErr != nil {
+		status = deliveryPending
+		if a.Attempts >= maxAttempts {
+			status = deliveryDead
+		}
+		msg := sendErr.Error()
+		if len(msg) > maxWebhookErrorLen {
+			msg = msg[:maxWebhookErrorLen]
+		}
+		lastError = &msg
+	}
+	_, err := q.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, last_status_code = NULLIF($3, 0), last_error = $4,
+		    delivered_at = CASE WHEN $2 = 'succeeded' THEN now() ELSE NULL END,
+		    next_attempt_at = now() + $5::int * interval '1 second'
+		WHERE id = $1
+	`, a.ID, status, statusCode, lastError, int(webhookBackoff(a.Attempts).Seconds()))
+	return err
+}
+
+// RunWebhookDispatcher delivers pending webhook deliveries until ctx is cancelled.
+//
+// Deliveries are written to the webhook_deliveries outbox in the transaction of the change they describe, so a
+// committed change is never lost: a replica that dies before or during an attempt leaves the delivery pending and
+// another (or the restarted) replica sends it. Delivery is therefore at least once; receivers deduplicate on
+// X-Webhook-Id. It is safe to run on every replica: due deliveries are claimed with FOR UPDATE SKIP LOCKED.
+func RunWebhookDispatcher(ctx context.Context, db *pgxpool.Pool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	cw := configChanges.subscribe("", "", "")
+	defer configChanges.unsubscribe("", cw)
+
+	for {
+		for ctx.Err() == nil {
+			n, err := dispatchDueWebhooks(ctx, db)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("webhooks: dispatch failed: %v", err)
+				}
+				break
+			}
+			if n < webhookBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-cw.ch:
+		case <-webhookWake:
+		}
+	}
+}
+
+// dispatchDueWebhooks claims up to webhookBatchSize due deliveries of active webhooks, sends them concurrently and
+// records the outcomes. It returns the number of deliveries claimed.
+func dispatchDueWebhooks(ctx context.Context, db *pgxpool.Pool) (int, error) {
+	rows, err := db.Query(ctx, `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, last_attempt_at = now(), next_attempt_at = now() + $2::int * interval '1 second'
+		FROM webhooks w
+		WHERE w.id = d.webhook_id
+		  AND d.id IN (
+			SELECT p.id
+			FROM webhook_deliveries p
+			JOIN webhooks pw ON pw.id = p.webhook_id AND pw.active
+			WHERE p.status = 'pending' AND p.next_attempt_at <= now()
+			ORDER BY p.next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE OF p SKIP LOCKED
+		  )
+		RETURNING d.id, d.event_type, d.payload, d.attempts, w.url, w.secret
+	`, webhookBatchSize, int(webhookLease().Seconds()))
+	if err != nil {
+		return 0, err
+	}
+	var claimed []webhookAttempt
+	for rows.Next() {
+		var a webhookAttempt
+		if err := rows.Scan(&a.ID, &a.EventType, &a.Payload, &a.Attempts, &a.URL, &a.Secret); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		claimed = append(claimed, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	maxAttempts := config.Int("api.webhooks.maxAttempts", 8)
+	var wg sync.WaitGroup
+	for _, a := range claimed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			code, sendErr := a.send(ctx)
+			if ctx.Err() != nil {
+				// Shutting down: the lease expires and another replica retries.
+				return
+			}
+			if err := storeRecordWebhookAttempt(ctx, db, a, code, sendErr, maxAttempts); err != nil {
+				log.Printf("webhooks: record delivery %s: %v", uuidToString(a.ID), err)
+			}
+			if sendErr != nil {
+				log.Printf("webhooks: delivery %s attempt %d to %s failed: %v", uuidToString(a.ID), a.Attempts, a.URL, sendErr)
+			}
+		}()
+	}
+	wg.Wait()
+	return len(claimed), nil
+}
+
+// webhookTestPayload is the body of a test delivery.
+func webhookTestPayload(webhookID string) []byte {
+	return fmt.Appendf(nil, `{"type":%q,"webhook_id":%q,"created_at":%q}`,
+		eventWebhookTest, webhookID, time.Now().UTC().Format(time.RFC3339Nano))
+}
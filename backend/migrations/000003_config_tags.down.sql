@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS config_tag_history;
+DROP TRIGGER IF EXISTS config_tags_set_updated_at ON config_tags;
+DROP TABLE IF EXISTS config_tags;
@@ -0,0 +1,44 @@
+-- Named, movable pointers to config versions (e.g. stable, canary, prod).
+-- Tag moves are recorded in config_tag_history; tagged versions cannot be deleted.
+
+CREATE TABLE IF NOT EXISTS config_tags (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  config_id  UUID NOT NULL REFERENCES configs(id) ON DELETE CASCADE,
+  name       TEXT NOT NULL,
+  version_id UUID NOT NULL REFERENCES config_versions(id),
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT config_tags_name_pattern CHECK (name ~ '^[a-zA-Z0-9_.-]+$'),
+  CONSTRAINT config_tags_unique_per_config UNIQUE (config_id, name)
+);
+
+CREATE TRIGGER config_tags_set_updated_at
+BEFORE UPDATE ON config_tags
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE INDEX IF NOT EXISTS config_tags_version_id_idx
+  ON config_tags (version_id);
+
+-- Append-only audit trail of tag moves. to_version is NULL when a tag was removed.
+CREATE TABLE IF NOT EXISTS config_tag_history (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  config_id    UUID NOT NULL REFERENCES configs(id) ON DELETE CASCADE,
+  tag          TEXT NOT NULL,
+  from_version INTEGER NULL,
+  to_version   INTEGER NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  moved_by   TEXT NULL,
+  comment    TEXT NULL,
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL
+);
+
+CREATE INDEX IF NOT EXISTS config_tag_history_config_tag_idx
+  ON config_tag_history (config_id, tag, created_at DESC);
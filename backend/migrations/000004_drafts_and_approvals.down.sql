@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS config_draft_reviews;
+DROP TRIGGER IF EXISTS config_drafts_set_updated_at ON config_drafts;
+DROP TABLE IF EXISTS config_drafts;
+
+ALTER TABLE namespaces DROP CONSTRAINT IF EXISTS namespaces_required_approvals_nonnegative;
+ALTER TABLE namespaces DROP COLUMN IF EXISTS required_approvals;
@@ -0,0 +1,71 @@
+-- Draft versions and review workflow.
+-- A draft never moves configs.latest_version_id; publishing a draft appends a regular version.
+
+ALTER TABLE namespaces
+  ADD COLUMN IF NOT EXISTS required_approvals INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE namespaces DROP CONSTRAINT IF EXISTS namespaces_required_approvals_nonnegative;
+ALTER TABLE namespaces
+  ADD CONSTRAINT namespaces_required_approvals_nonnegative CHECK (required_approvals >= 0);
+
+CREATE TABLE IF NOT EXISTS config_drafts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  config_id UUID NOT NULL REFERENCES configs(id) ON DELETE CASCADE,
+
+  -- Latest version the draft was written (or last rebased) against.
+  base_version INTEGER NOT NULL,
+  -- Bumped on every rebase/edit; approvals only count for the current revision.
+  revision INTEGER NOT NULL DEFAULT 1,
+  status TEXT NOT NULL DEFAULT 'open',
+
+  body_raw  TEXT NOT NULL,
+  body_json JSONB NULL,
+  content_sha256 TEXT NULL,
+
+  created_by TEXT NULL,
+  comment    TEXT NULL,
+
+  published_version INTEGER NULL,
+  published_by      TEXT NULL,
+  published_at      TIMESTAMPTZ NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL,
+
+  CONSTRAINT config_drafts_status_valid CHECK (status IN ('open', 'published', 'rejected')),
+  CONSTRAINT config_drafts_base_version_nonnegative CHECK (base_version >= 0),
+  CONSTRAINT config_drafts_revision_positive CHECK (revision >= 1)
+);
+
+CREATE TRIGGER config_drafts_set_updated_at
+BEFORE UPDATE ON config_drafts
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE INDEX IF NOT EXISTS config_drafts_config_status_idx
+  ON config_drafts (config_id, status, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS config_draft_reviews (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  draft_id UUID NOT NULL REFERENCES config_drafts(id) ON DELETE CASCADE,
+  revision INTEGER NOT NULL,
+  reviewer TEXT NOT NULL,
+  decision TEXT NOT NULL,
+  comment  TEXT NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL,
+
+  CONSTRAINT config_draft_reviews_reviewer_nonempty CHECK (char_length(trim(reviewer)) > 0),
+  CONSTRAINT config_draft_reviews_decision_valid CHECK (decision IN ('approve', 'reject', 'comment'))
+);
+
+CREATE INDEX IF NOT EXISTS config_draft_reviews_draft_idx
+  ON config_draft_reviews (draft_id, created_at);
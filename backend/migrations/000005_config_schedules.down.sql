@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS config_schedules_set_updated_at ON config_schedules;
+DROP TABLE IF EXISTS config_schedules;
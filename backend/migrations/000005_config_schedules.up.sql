@@ -0,0 +1,46 @@
+-- Scheduled publication: a pending body that the scheduler appends as a new version at publish_at.
+
+CREATE TABLE IF NOT EXISTS config_schedules (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  config_id  UUID NOT NULL REFERENCES configs(id) ON DELETE CASCADE,
+  publish_at TIMESTAMPTZ NOT NULL,
+  status     TEXT NOT NULL DEFAULT 'pending',
+
+  -- Optional guard: only publish if latest is still base_version.
+  base_version INTEGER NULL,
+  -- Optional window: mark as missed instead of publishing when more than this late (e.g. after downtime).
+  max_delay_seconds INTEGER NULL,
+
+  body_raw  TEXT NOT NULL,
+  body_json JSONB NULL,
+  content_sha256 TEXT NULL,
+
+  created_by TEXT NULL,
+  comment    TEXT NULL,
+
+  published_version INTEGER NULL,
+  published_at      TIMESTAMPTZ NULL,
+  failure_reason    TEXT NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL,
+
+  CONSTRAINT config_schedules_status_valid CHECK (status IN ('pending', 'published', 'cancelled', 'failed', 'missed')),
+  CONSTRAINT config_schedules_max_delay_positive CHECK (max_delay_seconds IS NULL OR max_delay_seconds > 0)
+);
+
+CREATE TRIGGER config_schedules_set_updated_at
+BEFORE UPDATE ON config_schedules
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE INDEX IF NOT EXISTS config_schedules_due_idx
+  ON config_schedules (publish_at, created_at)
+  WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS config_schedules_config_idx
+  ON config_schedules (config_id, publish_at DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS config_versions_config_created_idx;
+DROP INDEX IF EXISTS configs_identity_history_idx;
@@ -0,0 +1,7 @@
+-- Point-in-time reads (as_of): configs are soft-deleted and resolved by creation/deletion time,
+-- versions by created_at.
+CREATE INDEX IF NOT EXISTS configs_identity_history_idx
+  ON configs (namespace, path, created_at);
+
+CREATE INDEX IF NOT EXISTS config_versions_config_created_idx
+  ON config_versions (config_id, created_at);
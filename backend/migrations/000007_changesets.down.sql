@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS config_versions_changeset_idx;
+ALTER TABLE config_versions DROP COLUMN IF EXISTS changeset_id;
+
+DROP TABLE IF EXISTS changeset_items;
+DROP TABLE IF EXISTS changesets;
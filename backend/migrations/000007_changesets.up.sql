@@ -0,0 +1,47 @@
+-- Changesets: several config creates/updates/deletes applied in one transaction and recorded as a unit.
+
+CREATE TABLE IF NOT EXISTS changesets (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  created_by TEXT NULL,
+  comment    TEXT NULL,
+
+  -- Set when this changeset was produced by reverting another one.
+  reverts_changeset_id UUID NULL REFERENCES changesets(id) ON DELETE SET NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL
+);
+
+CREATE INDEX IF NOT EXISTS changesets_created_at_idx
+  ON changesets (created_at DESC, id);
+
+CREATE TABLE IF NOT EXISTS changeset_items (
+  changeset_id UUID NOT NULL REFERENCES changesets(id) ON DELETE CASCADE,
+  position     INTEGER NOT NULL,
+
+  action    TEXT NOT NULL,
+  namespace TEXT NOT NULL,
+  path      TEXT NOT NULL,
+  -- The config row the item applied to (a recreated path gets a new row). Tombstones are removed with their namespace.
+  config_id UUID NULL REFERENCES configs(id) ON DELETE SET NULL,
+
+  -- Latest version before the item (NULL for create) and the version it produced (NULL for delete).
+  previous_version INTEGER NULL,
+  version          INTEGER NULL,
+
+  PRIMARY KEY (changeset_id, position),
+  CONSTRAINT changeset_items_action_valid CHECK (action IN ('create', 'update', 'delete'))
+);
+
+CREATE INDEX IF NOT EXISTS changeset_items_namespace_idx
+  ON changeset_items (namespace, path);
+
+ALTER TABLE config_versions
+  ADD COLUMN IF NOT EXISTS changeset_id UUID NULL REFERENCES changesets(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS config_versions_changeset_idx
+  ON config_versions (changeset_id)
+  WHERE changeset_id IS NOT NULL;
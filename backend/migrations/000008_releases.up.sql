@@ -0,0 +1,38 @@
+-- Releases: immutable, named snapshots of the latest versions under a namespace (optionally a path prefix).
+
+CREATE TABLE IF NOT EXISTS releases (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  namespace TEXT NOT NULL REFERENCES namespaces(name) ON DELETE CASCADE,
+  name      TEXT NOT NULL,
+  prefix    TEXT NOT NULL DEFAULT '',
+
+  created_by TEXT NULL,
+  comment    TEXT NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL,
+
+  CONSTRAINT releases_name_unique UNIQUE (namespace, name),
+  CONSTRAINT releases_name_nonempty CHECK (char_length(name) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS releases_namespace_created_idx
+  ON releases (namespace, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS release_items (
+  release_id UUID NOT NULL REFERENCES releases(id) ON DELETE CASCADE,
+  path       TEXT NOT NULL,
+
+  config_id  UUID NOT NULL REFERENCES configs(id) ON DELETE CASCADE,
+  -- No cascade: a version referenced by a release cannot be deleted.
+  version_id UUID NOT NULL REFERENCES config_versions(id),
+  version    INTEGER NOT NULL,
+
+  PRIMARY KEY (release_id, path)
+);
+
+CREATE INDEX IF NOT EXISTS release_items_version_idx
+  ON release_items (version_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS config_aliases;
+DROP TABLE IF EXISTS config_moves;
@@ -0,0 +1,41 @@
+-- Moves/renames: configs keep their id (and so their versions, tags, drafts and schedules) when their
+-- namespace/path changes. config_moves is the audit trail; config_aliases redirect reads from old paths for a while.
+
+CREATE TABLE IF NOT EXISTS config_moves (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  -- All configs moved by one request share a move_id.
+  move_id   UUID NOT NULL,
+  config_id UUID NOT NULL REFERENCES configs(id) ON DELETE CASCADE,
+
+  from_namespace TEXT NOT NULL,
+  from_path      TEXT NOT NULL,
+  to_namespace   TEXT NOT NULL,
+  to_path        TEXT NOT NULL,
+
+  moved_by TEXT NULL,
+  comment  TEXT NULL,
+  alias_expires_at TIMESTAMPTZ NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL
+);
+
+CREATE INDEX IF NOT EXISTS config_moves_config_idx
+  ON config_moves (config_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS config_moves_move_idx
+  ON config_moves (move_id);
+
+CREATE TABLE IF NOT EXISTS config_aliases (
+  namespace TEXT NOT NULL REFERENCES namespaces(name) ON DELETE CASCADE,
+  path      TEXT NOT NULL,
+  config_id UUID NOT NULL REFERENCES configs(id) ON DELETE CASCADE,
+
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  PRIMARY KEY (namespace, path)
+);
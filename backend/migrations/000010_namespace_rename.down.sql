@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS namespace_aliases;
+
+ALTER TABLE config_aliases DROP CONSTRAINT IF EXISTS config_aliases_namespace_fkey;
+ALTER TABLE config_aliases
+  ADD CONSTRAINT config_aliases_namespace_fkey
+  FOREIGN KEY (namespace) REFERENCES namespaces(name) ON DELETE CASCADE;
+
+ALTER TABLE releases DROP CONSTRAINT IF EXISTS releases_namespace_fkey;
+ALTER TABLE releases
+  ADD CONSTRAINT releases_namespace_fkey
+  FOREIGN KEY (namespace) REFERENCES namespaces(name) ON DELETE CASCADE;
+
+ALTER TABLE configs DROP CONSTRAINT IF EXISTS configs_namespace_fk;
+ALTER TABLE configs
+  ADD CONSTRAINT configs_namespace_fk
+  FOREIGN KEY (namespace) REFERENCES namespaces(name);
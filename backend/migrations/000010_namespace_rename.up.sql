@@ -0,0 +1,29 @@
+-- Namespace rename: namespace names are natural keys, so every FK to namespaces(name) follows a rename.
+-- History tables (changeset_items, config_moves) keep the name that was current when they were written.
+
+ALTER TABLE configs DROP CONSTRAINT IF EXISTS configs_namespace_fk;
+ALTER TABLE configs
+  ADD CONSTRAINT configs_namespace_fk
+  FOREIGN KEY (namespace) REFERENCES namespaces(name) ON UPDATE CASCADE;
+
+ALTER TABLE releases DROP CONSTRAINT IF EXISTS releases_namespace_fkey;
+ALTER TABLE releases
+  ADD CONSTRAINT releases_namespace_fkey
+  FOREIGN KEY (namespace) REFERENCES namespaces(name) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE config_aliases DROP CONSTRAINT IF EXISTS config_aliases_namespace_fkey;
+ALTER TABLE config_aliases
+  ADD CONSTRAINT config_aliases_namespace_fkey
+  FOREIGN KEY (namespace) REFERENCES namespaces(name) ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- Old names of renamed namespaces: reads through them redirect, writes are refused, until expires_at.
+CREATE TABLE IF NOT EXISTS namespace_aliases (
+  name      TEXT PRIMARY KEY,
+  namespace TEXT NOT NULL REFERENCES namespaces(name) ON DELETE CASCADE ON UPDATE CASCADE,
+
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS namespace_aliases_namespace_idx
+  ON namespace_aliases (namespace);
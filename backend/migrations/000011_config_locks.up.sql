@@ -0,0 +1,28 @@
+-- Write locks (freezes) on a whole namespace, a folder prefix or a single config.
+-- A lock is live until it is deleted or expires_at passes.
+
+CREATE TABLE IF NOT EXISTS config_locks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  namespace TEXT NOT NULL REFERENCES namespaces(name) ON DELETE CASCADE ON UPDATE CASCADE,
+  scope     TEXT NOT NULL,
+  -- '' for namespace locks, the folder prefix (with trailing slash) for prefix locks, the path for config locks.
+  target    TEXT NOT NULL DEFAULT '',
+
+  reason TEXT NOT NULL,
+  owner  TEXT NOT NULL,
+  expires_at TIMESTAMPTZ NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL,
+
+  CONSTRAINT config_locks_scope_valid CHECK (scope IN ('namespace', 'prefix', 'config')),
+  CONSTRAINT config_locks_target_matches_scope CHECK ((scope = 'namespace') = (target = '')),
+  CONSTRAINT config_locks_reason_nonempty CHECK (char_length(trim(reason)) > 0),
+  CONSTRAINT config_locks_owner_nonempty CHECK (char_length(trim(owner)) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS config_locks_namespace_idx
+  ON config_locks (namespace, created_at);
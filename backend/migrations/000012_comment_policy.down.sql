@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS config_versions_ticket_refs_idx;
+ALTER TABLE config_versions DROP COLUMN IF EXISTS ticket_refs;
+
+ALTER TABLE namespaces DROP CONSTRAINT IF EXISTS namespaces_min_comment_length_nonnegative;
+ALTER TABLE namespaces
+  DROP COLUMN IF EXISTS ticket_pattern,
+  DROP COLUMN IF EXISTS min_comment_length,
+  DROP COLUMN IF EXISTS require_comment;
@@ -0,0 +1,18 @@
+-- Per-namespace change comment policy and ticket references extracted from version comments.
+
+ALTER TABLE namespaces
+  ADD COLUMN IF NOT EXISTS require_comment BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS min_comment_length INTEGER NOT NULL DEFAULT 0,
+  -- Regular expression (Go RE2 syntax); every match in a comment is recorded as a ticket reference.
+  -- When set, comments must reference at least one ticket.
+  ADD COLUMN IF NOT EXISTS ticket_pattern TEXT NULL;
+
+ALTER TABLE namespaces DROP CONSTRAINT IF EXISTS namespaces_min_comment_length_nonnegative;
+ALTER TABLE namespaces
+  ADD CONSTRAINT namespaces_min_comment_length_nonnegative CHECK (min_comment_length >= 0);
+
+ALTER TABLE config_versions
+  ADD COLUMN IF NOT EXISTS ticket_refs TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS config_versions_ticket_refs_idx
+  ON config_versions USING GIN (ticket_refs);
@@ -0,0 +1,33 @@
+-- Change events for the SSE stream (GET /events). Rows are written in the same transaction as the change they
+-- describe; ids are assigned in commit order (writers serialise on an advisory lock), so a reader resuming after
+-- an id never misses an event. Namespaces and configs are not referenced by FK: events outlive them.
+
+CREATE TABLE IF NOT EXISTS config_events (
+  id BIGSERIAL PRIMARY KEY,
+
+  type      TEXT NOT NULL,
+  namespace TEXT NOT NULL,
+  path      TEXT NULL,
+  config_id UUID NULL,
+  version   INTEGER NULL,
+  author    TEXT NULL,
+  content_sha256 TEXT NULL,
+
+  -- Previous location for config.moved and namespace.renamed.
+  from_namespace TEXT NULL,
+  from_path      TEXT NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+
+  CONSTRAINT config_events_type_valid CHECK (type IN (
+    'config.created', 'config.updated', 'config.deleted', 'config.moved', 'version.deleted',
+    'namespace.created', 'namespace.deleted', 'namespace.renamed'
+  ))
+);
+
+CREATE INDEX IF NOT EXISTS config_events_namespace_idx
+  ON config_events (namespace, id);
+
+CREATE INDEX IF NOT EXISTS config_events_created_at_idx
+  ON config_events (created_at);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS config_events_notify ON config_events;
+DROP FUNCTION IF EXISTS notify_config_event();
@@ -0,0 +1,23 @@
+-- Fan committed changes out to every API replica: each config_events row is announced on the config_changes
+-- channel. NOTIFY is transactional, so listeners only hear about changes that committed.
+
+CREATE OR REPLACE FUNCTION notify_config_event()
+RETURNS TRIGGER AS $$
+BEGIN
+  PERFORM pg_notify('config_changes', json_build_object(
+    'id', NEW.id,
+    'type', NEW.type,
+    'namespace', NEW.namespace,
+    'path', NEW.path,
+    'from_namespace', NEW.from_namespace,
+    'from_path', NEW.from_path
+  )::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS config_events_notify ON config_events;
+CREATE TRIGGER config_events_notify
+AFTER INSERT ON config_events
+FOR EACH ROW
+EXECUTE FUNCTION notify_config_event();
@@ -0,0 +1,64 @@
+-- Outgoing webhooks. webhook_deliveries is a transactional outbox: one row per matching subscription is inserted in
+-- the same transaction as the config_events row it describes, and a background dispatcher delivers it afterwards.
+
+CREATE TABLE IF NOT EXISTS webhooks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  name   TEXT NOT NULL,
+  url    TEXT NOT NULL,
+  -- HMAC-SHA256 key for X-Webhook-Signature.
+  secret TEXT NOT NULL,
+
+  -- Scope: NULL namespace = every namespace; prefix '' = every path; empty event_types = every type.
+  namespace   TEXT NULL REFERENCES namespaces(name) ON DELETE CASCADE ON UPDATE CASCADE,
+  prefix      TEXT NOT NULL DEFAULT '',
+  event_types TEXT[] NOT NULL DEFAULT '{}',
+  active      BOOLEAN NOT NULL DEFAULT true,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL,
+
+  CONSTRAINT webhooks_name_nonempty CHECK (char_length(trim(name)) > 0),
+  CONSTRAINT webhooks_url_http CHECK (url ~ '^https?://')
+);
+
+CREATE TRIGGER webhooks_set_updated_at
+BEFORE UPDATE ON webhooks
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+  -- config_events.id (no FK: events are pruned independently); NULL for test deliveries.
+  event_id   BIGINT NULL,
+  event_type TEXT NOT NULL,
+  payload    JSONB NOT NULL,
+  -- Set on replays.
+  replay_of  UUID NULL REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+
+  status   TEXT NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  -- Due time while pending; also the lease of an in-flight attempt (see the dispatcher).
+  next_attempt_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_attempt_at  TIMESTAMPTZ NULL,
+  last_status_code INTEGER NULL,
+  last_error       TEXT NULL,
+  delivered_at     TIMESTAMPTZ NULL,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT webhook_deliveries_status_valid CHECK (status IN ('pending', 'succeeded', 'dead')),
+  CONSTRAINT webhook_deliveries_attempts_nonnegative CHECK (attempts >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx
+  ON webhook_deliveries (next_attempt_at)
+  WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx
+  ON webhook_deliveries (webhook_id, created_at DESC);
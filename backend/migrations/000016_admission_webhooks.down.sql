@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS admission_denials;
+DROP TRIGGER IF EXISTS admission_webhooks_set_updated_at ON admission_webhooks;
+DROP TABLE IF EXISTS admission_webhooks;
@@ -0,0 +1,59 @@
+-- Admission webhooks: called synchronously by config create/update before commit to allow or deny the write.
+-- Denials (and failures of fail-closed hooks) are recorded in admission_denials, outside the rejected transaction.
+
+CREATE TABLE IF NOT EXISTS admission_webhooks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  namespace TEXT NOT NULL REFERENCES namespaces(name) ON DELETE CASCADE ON UPDATE CASCADE,
+  -- '' = every config of the namespace.
+  prefix    TEXT NOT NULL DEFAULT '',
+  name      TEXT NOT NULL,
+  url       TEXT NOT NULL,
+
+  timeout_ms     INTEGER NOT NULL DEFAULT 5000,
+  -- fail_closed: an unreachable or invalid hook denies the write; fail_open: it only adds a warning.
+  failure_policy TEXT NOT NULL DEFAULT 'fail_closed',
+  active         BOOLEAN NOT NULL DEFAULT true,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL,
+
+  CONSTRAINT admission_webhooks_name_unique UNIQUE (namespace, name),
+  CONSTRAINT admission_webhooks_name_nonempty CHECK (char_length(trim(name)) > 0),
+  CONSTRAINT admission_webhooks_url_http CHECK (url ~ '^https?://'),
+  CONSTRAINT admission_webhooks_timeout_range CHECK (timeout_ms BETWEEN 100 AND 30000),
+  CONSTRAINT admission_webhooks_failure_policy_valid CHECK (failure_policy IN ('fail_closed', 'fail_open'))
+);
+
+CREATE TRIGGER admission_webhooks_set_updated_at
+BEFORE UPDATE ON admission_webhooks
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS admission_denials (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  namespace TEXT NOT NULL REFERENCES namespaces(name) ON DELETE CASCADE ON UPDATE CASCADE,
+  path      TEXT NOT NULL,
+  operation TEXT NOT NULL,
+
+  webhook_id   UUID NULL REFERENCES admission_webhooks(id) ON DELETE SET NULL,
+  webhook_name TEXT NOT NULL,
+  messages     TEXT[] NOT NULL DEFAULT '{}',
+  -- true when the hook could not be called (fail_closed) rather than denying.
+  failed       BOOLEAN NOT NULL DEFAULT false,
+
+  created_by TEXT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  request_id TEXT NULL,
+  user_agent TEXT NULL,
+  source_ip  INET NULL,
+
+  CONSTRAINT admission_denials_operation_valid CHECK (operation IN ('create', 'update'))
+);
+
+CREATE INDEX IF NOT EXISTS admission_denials_namespace_idx
+  ON admission_denials (namespace, created_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE config_drafts DROP COLUMN IF EXISTS revised_by;
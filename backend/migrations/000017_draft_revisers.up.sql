@@ -0,0 +1,5 @@
+-- Who changed a draft's body or comment after it was created. Approvals from the author or any reviser are refused,
+-- so whoever rewrites a draft cannot also approve it.
+
+ALTER TABLE config_drafts
+  ADD COLUMN IF NOT EXISTS revised_by TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS configs_track_lifetime ON configs;
+DROP FUNCTION IF EXISTS track_config_lifetime();
+DROP TABLE IF EXISTS config_lifetimes;
@@ -0,0 +1,46 @@
+-- Intervals during which each config was alive (not soft-deleted). A config restored by reverting its deletion gets a
+-- new interval, so as_of reads inside the deletion still see it as deleted. Maintained by a trigger on configs.
+
+CREATE TABLE IF NOT EXISTS config_lifetimes (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+
+  config_id UUID NOT NULL REFERENCES configs(id) ON DELETE CASCADE,
+
+  valid_from TIMESTAMPTZ NOT NULL,
+  -- NULL while the config is alive.
+  valid_to   TIMESTAMPTZ NULL,
+
+  CONSTRAINT config_lifetimes_interval_valid CHECK (valid_to IS NULL OR valid_to >= valid_from)
+);
+
+CREATE INDEX IF NOT EXISTS config_lifetimes_config_idx
+  ON config_lifetimes (config_id, valid_from);
+
+INSERT INTO config_lifetimes (config_id, valid_from, valid_to)
+SELECT c.id, c.created_at, c.deleted_at
+FROM configs c
+WHERE NOT EXISTS (SELECT 1 FROM config_lifetimes l WHERE l.config_id = c.id);
+
+CREATE OR REPLACE FUNCTION track_config_lifetime()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    IF NEW.deleted_at IS NULL THEN
+      INSERT INTO config_lifetimes (config_id, valid_from) VALUES (NEW.id, NEW.created_at);
+    END IF;
+  ELSIF NEW.deleted_at IS DISTINCT FROM OLD.deleted_at THEN
+    UPDATE config_lifetimes SET valid_to = NEW.deleted_at
+    WHERE config_id = NEW.id AND valid_to IS NULL AND NEW.deleted_at IS NOT NULL;
+    IF NEW.deleted_at IS NULL THEN
+      INSERT INTO config_lifetimes (config_id, valid_from) VALUES (NEW.id, now());
+    END IF;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS configs_track_lifetime ON configs;
+CREATE TRIGGER configs_track_lifetime
+AFTER INSERT OR UPDATE OF deleted_at ON configs
+FOR EACH ROW
+EXECUTE FUNCTION track_config_lifetime();
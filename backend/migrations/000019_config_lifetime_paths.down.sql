@@ -0,0 +1,30 @@
+DROP INDEX IF EXISTS config_lifetimes_identity_idx;
+
+-- Intervals split at moves stay split; back to back they mean the same as the original interval.
+ALTER TABLE config_lifetimes
+  DROP COLUMN IF EXISTS path,
+  DROP COLUMN IF EXISTS namespace;
+
+CREATE OR REPLACE FUNCTION track_config_lifetime()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    IF NEW.deleted_at IS NULL THEN
+      INSERT INTO config_lifetimes (config_id, valid_from) VALUES (NEW.id, NEW.created_at);
+    END IF;
+  ELSIF NEW.deleted_at IS DISTINCT FROM OLD.deleted_at THEN
+    UPDATE config_lifetimes SET valid_to = NEW.deleted_at
+    WHERE config_id = NEW.id AND valid_to IS NULL AND NEW.deleted_at IS NOT NULL;
+    IF NEW.deleted_at IS NULL THEN
+      INSERT INTO config_lifetimes (config_id, valid_from) VALUES (NEW.id, now());
+    END IF;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS configs_track_lifetime ON configs;
+CREATE TRIGGER configs_track_lifetime
+AFTER INSERT OR UPDATE OF deleted_at ON configs
+FOR EACH ROW
+EXECUTE FUNCTION track_config_lifetime();
@@ -0,0 +1,76 @@
+-- Record where each config lived: a move closes the config's lifetime interval and opens one at the new
+-- namespace/path, so as_of reads resolve a moved config at the path it had then. Namespace renames rewrite the
+-- namespace of the whole history instead (the API does this in the rename), like every other reference to the name.
+
+ALTER TABLE config_lifetimes
+  ADD COLUMN IF NOT EXISTS namespace TEXT NULL,
+  ADD COLUMN IF NOT EXISTS path      TEXT NULL;
+
+-- Split existing intervals at the recorded moves: before a move the config was at its from_namespace/from_path,
+-- after the last one at its current namespace/path.
+WITH moves AS (
+  SELECT m.config_id, m.from_namespace, m.from_path, m.created_at,
+         lag(m.created_at) OVER (PARTITION BY m.config_id ORDER BY m.created_at, m.id) AS prev_at
+  FROM config_moves m
+),
+locations AS (
+  SELECT config_id, from_namespace AS namespace, from_path AS path, prev_at AS loc_from, created_at AS loc_to
+  FROM moves
+  UNION ALL
+  SELECT c.id, c.namespace, c.path, (SELECT max(m.created_at) FROM config_moves m WHERE m.config_id = c.id), NULL
+  FROM configs c
+)
+INSERT INTO config_lifetimes (config_id, namespace, path, valid_from, valid_to)
+SELECT l.config_id, loc.namespace, loc.path,
+       -- greatest/least ignore NULLs, which stand for open ends here.
+       greatest(l.valid_from, loc.loc_from), least(l.valid_to, loc.loc_to)
+FROM config_lifetimes l
+JOIN locations loc ON loc.config_id = l.config_id
+WHERE l.namespace IS NULL
+  AND (loc.loc_from IS NULL OR l.valid_to IS NULL OR loc.loc_from < l.valid_to)
+  AND (loc.loc_to IS NULL OR loc.loc_to > l.valid_from);
+
+DELETE FROM config_lifetimes WHERE namespace IS NULL;
+
+ALTER TABLE config_lifetimes
+  ALTER COLUMN namespace SET NOT NULL,
+  ALTER COLUMN path SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS config_lifetimes_identity_idx
+  ON config_lifetimes (namespace, path, valid_from);
+
+CREATE OR REPLACE FUNCTION track_config_lifetime()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    IF NEW.deleted_at IS NULL THEN
+      INSERT INTO config_lifetimes (config_id, namespace, path, valid_from)
+      VALUES (NEW.id, NEW.namespace, NEW.path, NEW.created_at);
+    END IF;
+    RETURN NULL;
+  END IF;
+
+  IF NEW.deleted_at IS NOT DISTINCT FROM OLD.deleted_at AND NEW.namespace = OLD.namespace AND NEW.path = OLD.path THEN
+    RETURN NULL;
+  END IF;
+  -- A namespace rename cascades to configs; the old name is gone by then and the history follows the rename.
+  IF NEW.deleted_at IS NOT DISTINCT FROM OLD.deleted_at AND NEW.path = OLD.path
+     AND NOT EXISTS (SELECT 1 FROM namespaces WHERE name = OLD.namespace) THEN
+    RETURN NULL;
+  END IF;
+
+  UPDATE config_lifetimes SET valid_to = COALESCE(NEW.deleted_at, now())
+  WHERE config_id = NEW.id AND valid_to IS NULL;
+  IF NEW.deleted_at IS NULL THEN
+    INSERT INTO config_lifetimes (config_id, namespace, path, valid_from)
+    VALUES (NEW.id, NEW.namespace, NEW.path, now());
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS configs_track_lifetime ON configs;
+CREATE TRIGGER configs_track_lifetime
+AFTER INSERT OR UPDATE OF deleted_at, namespace, path ON configs
+FOR EACH ROW
+EXECUTE FUNCTION track_config_lifetime();
@@ -399,7 +399,7 @@ function ConfigEditorInner(props: {
     }
 
     const ok = window.confirm(
-      `Delete config '${expected}'?\n\nThis will hard-delete the config and its versions. This cannot be undone.`,
+      `Delete config '${expected}'?\n\nThis will delete the config. It can no longer be read or edited (history stays available to point-in-time reads).`,
     );
     if (!ok) return;
 
@@ -530,7 +530,7 @@ function ConfigEditorInner(props: {
               Danger zone
             </div>
             <div className="mt-1 text-xs text-zinc-700 dark:text-zinc-300">
-              This will hard-delete the config and its versions. Type{" "}
+              This will delete the config; history stays available to point-in-time reads. Type{" "}
               <code>{props.namespace}/{props.path}</code> to confirm.
             </div>
             <div className="mt-2 flex flex-col gap-2 md:flex-row md:items-center">